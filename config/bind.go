@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/wwwangxc/gopkg/config/unmarshaler"
+)
+
+// Binding typed config snapshot
+//
+// The snapshot is swapped atomically on every successful reload that changes
+// the bound key, readers get a consistent snapshot without locking.
+type Binding struct {
+	typ   reflect.Type
+	value atomic.Value
+}
+
+// Load return the latest snapshot
+//
+// The snapshot is a pointer of the bound type, it must be treated as
+// read-only since it is shared by all readers.
+func (b *Binding) Load() interface{} {
+	return b.value.Load()
+}
+
+func (b *Binding) store(ptr interface{}) {
+	b.value.Store(ptr)
+}
+
+// Bind bind the value of key to a typed snapshot
+//
+// ptr must be a non-nil pointer, it will be filled with the current value and
+// used as the first snapshot, so do not modify it after bind.
+// k support key1.key2.key3, and "" means the whole config.
+func (c *configureImpl) Bind(k string, ptr interface{}) (*Binding, error) {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, fmt.Errorf("%s: bind target must be a non-nil pointer", packageName)
+	}
+
+	val, err := c.getByKey(k)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.decode(val, ptr); err != nil {
+		return nil, fmt.Errorf("%s: bind key %s fail. err:%w", packageName, k, err)
+	}
+
+	b := &Binding{
+		typ: rv.Type().Elem(),
+	}
+	b.store(ptr)
+
+	c.OnChange(k, func(_, new interface{}) {
+		out := reflect.New(b.typ).Interface()
+		if new != nil {
+			if err := c.decode(new, out); err != nil {
+				c.logErrorf("%s: refresh binding fail, keep the last snapshot. key:%s err:%v\n", packageName, k, err)
+				return
+			}
+		}
+
+		b.store(out)
+	})
+
+	return b, nil
+}
+
+// getByKey get value by key, return the whole config when key is empty
+func (c *configureImpl) getByKey(k string) (interface{}, error) {
+	if k != "" {
+		return c.get(k)
+	}
+
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return c.unmarshaledData, nil
+}
+
+// decode decode the value into out by the unmarshaler
+func (c *configureImpl) decode(val interface{}, out interface{}) error {
+	if c.unmarshaler == nil {
+		return ErrUnmarshalerNotExist
+	}
+
+	marshaler, ok := c.unmarshaler.(unmarshaler.Marshaler)
+	if !ok {
+		return fmt.Errorf("unmarshaler %s not support marshal", c.unmarshaler.Name())
+	}
+
+	tagName := c.structTagName()
+	data, err := marshaler.Marshal(convertStrings(reflect.TypeOf(out), val, tagName))
+	if err != nil {
+		return err
+	}
+
+	if err = c.unmarshaler.Unmarshal(data, out); err != nil {
+		return err
+	}
+
+	return setDefaults(out, val, tagName)
+}
+
+// convertStrings convert the string values of doc to the scalar types of t
+//
+// The env values are kept raw strings, e.g. "007" and "1.10", they are
+// converted only when decoded into the bool, int, uint or float fields, and
+// kept as is when the conversion fails so the unmarshaler reports it.
+func convertStrings(t reflect.Type, doc interface{}, tagName string) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil {
+		return doc
+	}
+
+	if s, ok := doc.(string); ok {
+		return convertString(t, s)
+	}
+
+	if m, ok := toStringMap(doc); ok {
+		switch t.Kind() {
+		case reflect.Struct:
+			if t == timeType {
+				return doc
+			}
+
+			ret := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				ret[k] = v
+			}
+			convertStructStrings(t, m, ret, tagName)
+			return ret
+		case reflect.Map:
+			ret := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				ret[k] = convertStrings(t.Elem(), v, tagName)
+			}
+			return ret
+		default:
+			return doc
+		}
+	}
+
+	if items := toSlice(doc); items != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		ret := make([]interface{}, 0, len(items))
+		for _, v := range items {
+			ret = append(ret, convertStrings(t.Elem(), v, tagName))
+		}
+		return ret
+	}
+
+	return doc
+}
+
+// convertStructStrings convert the values of m into ret by the struct fields
+func convertStructStrings(t reflect.Type, m, ret map[string]interface{}, tagName string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline := fieldKey(field, tagName)
+		if name == "-" {
+			continue
+		}
+
+		if inline {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				convertStructStrings(ft, m, ret, tagName)
+			}
+			continue
+		}
+
+		for k, v := range m {
+			if strings.EqualFold(k, name) {
+				ret[k] = convertStrings(field.Type, v, tagName)
+			}
+		}
+	}
+}
+
+func convertString(t reflect.Type, s string) interface{} {
+	if t == durationType {
+		return s
+	}
+
+	var ret interface{}
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		ret, err = strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ret, err = strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ret, err = strconv.ParseUint(s, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		ret, err = strconv.ParseFloat(s, 64)
+	default:
+		return s
+	}
+
+	if err != nil {
+		return s
+	}
+
+	return ret
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func Test_configureImpl_Bind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("client:\n  redis:\n    timeout: 1000\n"), 0644); err != nil {
+		t.Fatalf("write file fail. err:%v", err)
+	}
+
+	c := defaultConfigure(path)
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	type redisConfig struct {
+		Timeout int `yaml:"timeout"`
+	}
+
+	if _, err := c.Bind("client.redis", redisConfig{}); err == nil {
+		t.Errorf("configureImpl.Bind() error = nil, want error")
+	}
+
+	if _, err := c.Bind("not exist key", &redisConfig{}); err == nil {
+		t.Errorf("configureImpl.Bind() error = nil, want error")
+	}
+
+	b, err := c.Bind("client.redis", &redisConfig{})
+	if err != nil {
+		t.Fatalf("configureImpl.Bind() error = %v", err)
+	}
+
+	first := b.Load().(*redisConfig)
+	if first.Timeout != 1000 {
+		t.Errorf("Binding.Load() = %+v, want timeout 1000", first)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("client:\n  redis:\n    timeout: 2000\n"), 0644); err != nil {
+		t.Fatalf("write file fail. err:%v", err)
+	}
+
+	if err := c.Reload(); err != nil {
+		t.Fatalf("configureImpl.Reload() error = %v", err)
+	}
+
+	if got := b.Load().(*redisConfig); got.Timeout != 2000 {
+		t.Errorf("Binding.Load() = %+v, want timeout 2000", got)
+	}
+
+	if first.Timeout != 1000 {
+		t.Errorf("old snapshot modified: %+v", first)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/wwwangxc/gopkg/config"
+	"github.com/wwwangxc/gopkg/config/unmarshaler"
+)
+
+// runValidate validate the client.* config
+//
+// Exit 1 when the config can not be loaded, or any key break the schema.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("validate", "file [layer...]", stderr)
+	format := fs.String("format", "", "config format, picked by the file extension when empty")
+	if code, ok := parseFlags(fs, args, 1, 0); !ok {
+		return code
+	}
+
+	r, err := resolve(fs.Args(), *format)
+	if err != nil {
+		fmt.Fprintf(stderr, "FAIL %s\n  %v\n", strings.Join(fs.Args(), ","), err)
+		return exitFail
+	}
+
+	if err = r.validate(); err != nil {
+		fmt.Fprintf(stderr, "FAIL %s\n", r.name)
+		for _, v := range validationErrors(err) {
+			fmt.Fprintf(stderr, "  %s\n", r.maskString("", v.Error()))
+		}
+		return exitFail
+	}
+
+	fmt.Fprintf(stdout, "OK %s\n", r.name)
+	return exitOK
+}
+
+// runRender print the resolved config with secrets masked
+func runRender(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("render", "file [layer...]", stderr)
+	format := fs.String("format", "", "config format, picked by the file extension when empty")
+	output := fs.String("o", "yaml", "output format: yaml, json or toml")
+	if code, ok := parseFlags(fs, args, 1, 0); !ok {
+		return code
+	}
+
+	r, err := resolve(fs.Args(), *format)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", programName, err)
+		return exitFail
+	}
+
+	data, err := marshal(*output, r.masked())
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", programName, err)
+		return exitFail
+	}
+
+	stdout.Write(data)
+	return exitOK
+}
+
+// runConvert convert the config file between formats
+//
+// The values are converted as is, the ENC() values are not decrypted.
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("convert", "file", stderr)
+	from := fs.String("from", "", "source format, picked by the file extension when empty")
+	to := fs.String("to", "", "target format, e.g. yaml, json or toml")
+	if code, ok := parseFlags(fs, args, 1, 1); !ok {
+		return code
+	}
+
+	if *to == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	data, err := readFile(fs.Arg(0), *from)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", programName, err)
+		return exitFail
+	}
+
+	out, err := marshal(*to, data)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", programName, err)
+		return exitFail
+	}
+
+	stdout.Write(out)
+	return exitOK
+}
+
+// runDiff print the resolved keys differ between two environments
+//
+// Each environment is a file and its layers separated by comma, e.g.
+// app.yaml,app.prod.yaml. Exit 1 when any key differs.
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("diff", "file[,layer...] file[,layer...]", stderr)
+	format := fs.String("format", "", "config format, picked by the file extension when empty")
+	if code, ok := parseFlags(fs, args, 2, 2); !ok {
+		return code
+	}
+
+	var envs [2]*resolved
+	for i := range envs {
+		r, err := resolve(strings.Split(fs.Arg(i), ","), *format)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", programName, err)
+			return exitFail
+		}
+		envs[i] = r
+	}
+
+	lines := diff(envs[0], envs[1])
+	if len(lines) == 0 {
+		return exitOK
+	}
+
+	fmt.Fprintf(stdout, "--- %s\n+++ %s\n", envs[0].name, envs[1].name)
+	for _, v := range lines {
+		fmt.Fprintln(stdout, v)
+	}
+
+	return exitFail
+}
+
+// diff compare the resolved values by key, the values are masked in lines
+func diff(a, b *resolved) []string {
+	aValues, aMasked := flattenResolved(a)
+	bValues, bMasked := flattenResolved(b)
+
+	keys := make([]string, 0, len(aValues)+len(bValues))
+	for k := range aValues {
+		keys = append(keys, k)
+	}
+	for k := range bValues {
+		if _, exist := aValues[k]; !exist {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		aVal, inA := aValues[k]
+		bVal, inB := bValues[k]
+		switch {
+		case !inB:
+			lines = append(lines, fmt.Sprintf("- %s: %v", k, aMasked[k]))
+		case !inA:
+			lines = append(lines, fmt.Sprintf("+ %s: %v", k, bMasked[k]))
+		case !reflect.DeepEqual(aVal, bVal):
+			lines = append(lines, fmt.Sprintf("~ %s: %v -> %v", k, aMasked[k], bMasked[k]))
+		}
+	}
+
+	return lines
+}
+
+func flattenResolved(r *resolved) (map[string]interface{}, map[string]interface{}) {
+	values := map[string]interface{}{}
+	flatten("", r.data, func(k string, v interface{}) {
+		values[k] = v
+	})
+
+	masked := map[string]interface{}{}
+	flatten("", r.masked(), func(k string, v interface{}) {
+		masked[k] = v
+	})
+
+	return values, masked
+}
+
+// validate validate the resolved data by the client schemas
+//
+// The data is validated by yaml whatever the file format is, as the
+// clients unmarshal config by the yaml tag.
+func (r *resolved) validate() error {
+	_, err := config.NewLoader().LoadSource(&mapSource{name: r.name, data: r.data},
+		config.WithUnmarshaler("yaml"),
+		config.WithValidate(&appSchema{}),
+		config.WithValidator(serviceValidator),
+		config.WithValidator(unknownKeysValidator))
+
+	return err
+}
+
+// mapSource config source of the resolved data
+type mapSource struct {
+	name string
+	data map[string]interface{}
+}
+
+// Name source name
+func (m *mapSource) Name() string {
+	return m.name
+}
+
+// Read return the resolved data
+func (m *mapSource) Read() (map[string]interface{}, error) {
+	return m.data, nil
+}
+
+// validationErrors return the errors of validation error, or the error itself
+func validationErrors(err error) []error {
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Errors
+	}
+
+	return []error{err}
+}
+
+// marshal marshal data by the marshaler of format, json is indented
+func marshal(format string, data map[string]interface{}) ([]byte, error) {
+	marshaler, ok := unmarshaler.Get(format).(unmarshaler.Marshaler)
+	if !ok {
+		return nil, fmt.Errorf("format %s not support marshal", format)
+	}
+
+	out, err := marshaler.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s fail. err:%w", format, err)
+	}
+
+	if format == "json" {
+		buf := &bytes.Buffer{}
+		if err = json.Indent(buf, out, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		out = buf.Bytes()
+	}
+
+	return out, nil
+}
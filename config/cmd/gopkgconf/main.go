@@ -0,0 +1,125 @@
+// gopkgconf check the config file of gopkg clients before deploy
+//
+// Usage:
+//
+//	gopkgconf validate [-format name] file [layer...]
+//	gopkgconf render   [-format name] [-o yaml|json|toml] file [layer...]
+//	gopkgconf convert  [-from name] -to name file
+//	gopkgconf diff     [-format name] file[,layer...] file[,layer...]
+//
+// The layers are merged over the file in order, the later one has the
+// higher priority. The format is picked by the file extension when not given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+const (
+	exitOK      = 0
+	exitFail    = 1
+	exitUsage   = 2
+	programName = "gopkgconf"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{
+		name:  "validate",
+		usage: "validate the client.* config of redis, mysql, orm, etcd and httpx",
+		run:   runValidate,
+	},
+	{
+		name:  "render",
+		usage: "print the resolved config with defaults applied and secrets masked",
+		run:   runRender,
+	},
+	{
+		name:  "convert",
+		usage: "convert the config file between formats",
+		run:   runConvert,
+	},
+	{
+		name:  "diff",
+		usage: "print the resolved keys differ between two environments",
+		run:   runDiff,
+	},
+}
+
+func main() {
+	// the config watcher logs are noise for a one-shot command, the load
+	// errors are returned and reported by the command
+	log.SetOutput(ioutil.Discard)
+
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "%s: unknown command %q\n", programName, args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [args]\n\nCommands:\n", programName)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of command.\n", programName)
+}
+
+// newFlagSet new flag set of command, the errors are written to stderr
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s [flags] %s\n\nFlags:\n", programName, name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseFlags parse args and return the exit code when fail
+func parseFlags(fs *flag.FlagSet, args []string, minArgs, maxArgs int) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+
+	if fs.NArg() < minArgs || (maxArgs > 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return exitUsage, false
+	}
+
+	return exitOK, true
+}
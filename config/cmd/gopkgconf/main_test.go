@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wwwangxc/gopkg/config"
+)
+
+func Test_run(t *testing.T) {
+	os.Setenv("GOPKGCONF_APP_NAME", "demo")
+	defer os.Unsetenv("GOPKGCONF_APP_NAME")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string
+		wantStderr []string
+	}{
+		{
+			name:       "no command",
+			args:       []string{},
+			wantCode:   exitUsage,
+			wantStderr: []string{"Usage: gopkgconf"},
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"unknown"},
+			wantCode:   exitUsage,
+			wantStderr: []string{`unknown command "unknown"`},
+		},
+		{
+			name:       "validate ok",
+			args:       []string{"validate", "testdata/app.yaml", "testdata/app.prod.yaml"},
+			wantCode:   exitOK,
+			wantStdout: []string{"OK testdata/app.yaml,testdata/app.prod.yaml"},
+		},
+		{
+			name:       "validate toml",
+			args:       []string{"validate", "testdata/app.toml"},
+			wantCode:   exitOK,
+			wantStdout: []string{"OK testdata/app.toml"},
+		},
+		{
+			name:     "validate fail",
+			args:     []string{"validate", "testdata/invalid.yaml"},
+			wantCode: exitFail,
+			wantStderr: []string{
+				"FAIL testdata/invalid.yaml",
+				"key:client.redis.timeout rule:min=0",
+				"key:client.service.2.dsn rule:required",
+				"key:client.service.1.name duplicate with client.service.0.name",
+				"key:client.service.2.driver rule:oneof=",
+				"key:client.service.3.master_name rule:required",
+				"key:client.service.4.mode rule:oneof=standalone sentinel cluster",
+				"key:client.service.4.replica_selector rule:oneof=round_robin latency",
+				"key:client.redis.max_idel unknown",
+				"key:client.http.transport.max_idle_conn unknown",
+			},
+		},
+		{
+			name:       "validate file not exist",
+			args:       []string{"validate", "testdata/not_exist.yaml"},
+			wantCode:   exitFail,
+			wantStderr: []string{"FAIL testdata/not_exist.yaml"},
+		},
+		{
+			name:     "render",
+			args:     []string{"render", "testdata/app.yaml"},
+			wantCode: exitOK,
+			wantStdout: []string{
+				"name: demo",
+				"api_token: '******'",
+				"redis://:******@127.0.0.1:6379/0",
+				"root:******@tcp(127.0.0.1:3306)/db",
+				"timeout: 1000",
+				"idle_conn_timeout: 90s",
+			},
+		},
+		{
+			name:       "render json",
+			args:       []string{"render", "-o", "json", "testdata/app.toml"},
+			wantCode:   exitOK,
+			wantStdout: []string{`"max_idle": 10`},
+		},
+		{
+			name:       "render unknown output",
+			args:       []string{"render", "-o", "xml", "testdata/app.yaml"},
+			wantCode:   exitFail,
+			wantStderr: []string{"format xml not support marshal"},
+		},
+		{
+			name:     "convert",
+			args:     []string{"convert", "-to", "toml", "testdata/app.yaml"},
+			wantCode: exitOK,
+			wantStdout: []string{
+				"[client.redis]",
+				"[[client.service]]",
+				`dsn = "root:root@tcp(127.0.0.1:3306)/db"`,
+			},
+		},
+		{
+			name:       "convert without target",
+			args:       []string{"convert", "testdata/app.yaml"},
+			wantCode:   exitUsage,
+			wantStderr: []string{"Usage: gopkgconf convert"},
+		},
+		{
+			name:     "diff",
+			args:     []string{"diff", "testdata/app.yaml", "testdata/app.yaml,testdata/app.prod.yaml"},
+			wantCode: exitFail,
+			wantStdout: []string{
+				"~ client.redis.max_active: 100 -> 500",
+				"~ client.service.0.dsn: redis://:******@127.0.0.1:6379/0 -> redis://:******@10.0.0.1:6379/0",
+				"+ client.service.2.name: etcd1",
+			},
+		},
+		{
+			name:     "diff same",
+			args:     []string{"diff", "testdata/app.yaml", "testdata/app.yaml"},
+			wantCode: exitOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			if got := run(tt.args, stdout, stderr); got != tt.wantCode {
+				t.Errorf("run() = %v, want %v. stderr:%s", got, tt.wantCode, stderr)
+			}
+
+			for _, v := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), v) {
+					t.Errorf("run() stdout = %s, want contains %s", stdout, v)
+				}
+			}
+
+			for _, v := range tt.wantStderr {
+				if !strings.Contains(stderr.String(), v) {
+					t.Errorf("run() stderr = %s, want contains %s", stderr, v)
+				}
+			}
+		})
+	}
+}
+
+func Test_run_MaskEncrypted(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypted, err := config.EncryptAESGCM(key, []byte("s3cr3t"))
+	if err != nil {
+		t.Fatalf("EncryptAESGCM() error = %v", err)
+	}
+
+	os.Setenv(config.EnvDecryptKey, base64.StdEncoding.EncodeToString(key))
+	defer os.Unsetenv(config.EnvDecryptKey)
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := fmt.Sprintf("client:\n  kafka:\n    sasl: %s\n    brokers: kafka://s3cr3t@127.0.0.1:9092\n", encrypted)
+	if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write file error = %v", err)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if got := run([]string{"render", path}, stdout, stderr); got != exitOK {
+		t.Fatalf("run() = %v, want %v. stderr:%s", got, exitOK, stderr)
+	}
+
+	if strings.Contains(stdout.String(), "s3cr3t") {
+		t.Errorf("run() stdout = %s, secret not masked", stdout)
+	}
+
+	if !strings.Contains(stdout.String(), "sasl: '******'") {
+		t.Errorf("run() stdout = %s, want sasl masked", stdout)
+	}
+
+	stdout.Reset()
+	if got := run([]string{"convert", "-to", "json", path}, stdout, stderr); got != exitOK {
+		t.Fatalf("run() = %v, want %v. stderr:%s", got, exitOK, stderr)
+	}
+
+	if !strings.Contains(stdout.String(), encrypted) {
+		t.Errorf("run() stdout = %s, want keep %s", stdout, encrypted)
+	}
+}
+
+func Test_maskDSN(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"redis://127.0.0.1:6379/0", "redis://127.0.0.1:6379/0"},
+		{"redis://:pass@127.0.0.1:6379/0", "redis://:******@127.0.0.1:6379/0"},
+		{"redis://user@127.0.0.1:6379/0", "redis://user@127.0.0.1:6379/0"},
+		{"root:p@ss@tcp(127.0.0.1:3306)/db", "root:******@tcp(127.0.0.1:3306)/db"},
+		{"user:pass@127.0.0.1:2379,127.0.0.2:2379", "user:******@127.0.0.1:2379,127.0.0.2:2379"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			if got := maskDSN(tt.dsn); got != tt.want {
+				t.Errorf("maskDSN() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
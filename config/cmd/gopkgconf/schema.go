@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wwwangxc/gopkg/config"
+)
+
+// appSchema the client.* config expected by the gopkg clients
+//
+// The schemas are kept in line with the appConfig of each client package,
+// the client modules depend on config and can not be imported here.
+// The service list is shared by all clients, so serviceSchema is the union
+// of the service config of each client.
+type appSchema struct {
+	Client clientSchema `yaml:"client"`
+}
+
+type clientSchema struct {
+	Redis      redisSchema     `yaml:"redis"`
+	MySQL      dbSchema        `yaml:"mysql"`
+	PostgreSQL dbSchema        `yaml:"postgresql"`
+	SQLite     dbSchema        `yaml:"sqlite"`
+	SQLServer  dbSchema        `yaml:"sqlserver"`
+	Clickhouse dbSchema        `yaml:"clickhouse"`
+	ETCD       etcdSchema      `yaml:"etcd"`
+	HTTP       httpSchema      `yaml:"http"`
+	Service    []serviceSchema `yaml:"service"`
+}
+
+// redisSchema gopkg/redis
+type redisSchema struct {
+	MaxIdle         int  `yaml:"max_idle" validate:"min=0"`
+	MaxActive       int  `yaml:"max_active" validate:"min=0"`
+	MaxConnLifetime int  `yaml:"max_conn_lifetime" validate:"min=0"`
+	IdleTimeout     int  `yaml:"idle_timeout" validate:"min=0"`
+	Timeout         int  `yaml:"timeout" validate:"min=0"`
+	Wait            bool `yaml:"wait"`
+}
+
+// dbSchema gopkg/mysql and gopkg/orm
+type dbSchema struct {
+	MaxIdle     int `yaml:"max_idle" validate:"min=0"`
+	MaxOpen     int `yaml:"max_open" validate:"min=0"`
+	MaxIdleTime int `yaml:"max_idle_time" validate:"min=0"`
+}
+
+// etcdSchema gopkg/etcd
+type etcdSchema struct {
+	Timeout     int    `yaml:"timeout" validate:"min=0"`
+	TLSKeyPath  string `yaml:"tls_key"`
+	TLSCertPath string `yaml:"tls_cert"`
+	CACertPath  string `yaml:"ca_cert"`
+}
+
+// httpSchema gopkg/httpx
+type httpSchema struct {
+	Header    map[string]string `yaml:"header"`
+	Transport *transportSchema  `yaml:"transport"`
+}
+
+type transportSchema struct {
+	MaxIdleConns          int           `yaml:"max_idle_conns" validate:"min=0"`
+	MaxIdleConnsPerHost   int           `yaml:"max_idle_conns_per_host" validate:"min=0"`
+	MaxConnsPerHost       int           `yaml:"max_conns_per_host" validate:"min=0"`
+	IdleConnTimeout       time.Duration `yaml:"idle_conn_timeout" validate:"min=0"`
+	TLSHandshakeTimeout   time.Duration `yaml:"tls_handshake_timeout" validate:"min=0"`
+	ExpectContinueTimeout time.Duration `yaml:"expect_continue_timeout" validate:"min=0"`
+	ResponseHeaderTimeout time.Duration `yaml:"response_header_timeout" validate:"min=0"`
+
+	Dial struct {
+		Timeout   time.Duration `yaml:"timeout" validate:"min=0"`
+		KeepAlive time.Duration `yaml:"keep_alive" validate:"min=0"`
+	} `yaml:"dial"`
+}
+
+// serviceSchema the union of the service config of all clients
+type serviceSchema struct {
+	Name   string `yaml:"name" validate:"required"`
+	DSN    string `yaml:"dsn" validate:"required"`
+	Driver string `yaml:"driver"`
+
+	Mode             string   `yaml:"mode"`
+	MasterName       string   `yaml:"master_name"`
+	SentinelPassword string   `yaml:"sentinel_password"`
+	Replicas         []string `yaml:"replicas"`
+	ReplicaSelector  string   `yaml:"replica_selector"`
+
+	MaxIdle         int    `yaml:"max_idle" validate:"min=0"`
+	MaxActive       int    `yaml:"max_active" validate:"min=0"`
+	MaxOpen         int    `yaml:"max_open" validate:"min=0"`
+	MaxIdleTime     int    `yaml:"max_idle_time" validate:"min=0"`
+	MaxConnLifetime int    `yaml:"max_conn_lifetime" validate:"min=0"`
+	IdleTimeout     int    `yaml:"idle_timeout" validate:"min=0"`
+	Timeout         int64  `yaml:"timeout" validate:"min=0"`
+	Wait            bool   `yaml:"wait"`
+	TLSKeyPath      string `yaml:"tls_key"`
+	TLSCertPath     string `yaml:"tls_cert"`
+	CACertPath      string `yaml:"ca_cert"`
+
+	httpSchema `yaml:",inline"`
+}
+
+// ormDrivers the driver names of gopkg/orm
+var ormDrivers = []string{"mysql", "postgresql", "postgresql.simple", "sqlite", "sqlserver", "clickhouse"}
+
+// redisModes the modes of gopkg/redis
+var redisModes = []string{"standalone", "sentinel", "cluster"}
+
+// replicaSelectors the replica selectors of gopkg/redis
+var replicaSelectors = []string{"round_robin", "latency"}
+
+// schemaDefaults the built-in default values of the clients
+//
+// The defaults are applied when the section exists and the key not set.
+var schemaDefaults = map[string]map[string]interface{}{
+	"client.etcd": {
+		"timeout": 1000,
+	},
+	"client.http": {
+		"transport.max_idle_conns":          200,
+		"transport.max_idle_conns_per_host": 50,
+		"transport.max_conns_per_host":      100,
+		"transport.idle_conn_timeout":       "90s",
+		"transport.tls_handshake_timeout":   "3s",
+		"transport.expect_continue_timeout": "1s",
+		"transport.response_header_timeout": "5s",
+		"transport.dial.timeout":            "3s",
+		"transport.dial.keep_alive":         "30s",
+	},
+}
+
+// applyDefaults set the default values into data
+func applyDefaults(data map[string]interface{}) {
+	for section, defaults := range schemaDefaults {
+		m, ok := fetch(data, section).(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for k, v := range defaults {
+			if fetch(m, k) == nil {
+				set(m, k, v)
+			}
+		}
+	}
+}
+
+// serviceValidator validate the service names are unique, the orm drivers
+// and the redis modes are known
+func serviceValidator(c config.Configure) error {
+	var services []serviceSchema
+	if err := c.UnmarshalKey("client.service", &services); err != nil {
+		return nil
+	}
+
+	var errs []error
+	names := map[string]int{}
+	for i, v := range services {
+		if v.Name != "" {
+			if j, exist := names[v.Name]; exist {
+				errs = append(errs, fmt.Errorf("key:client.service.%d.name duplicate with client.service.%d.name", i, j))
+			}
+			names[v.Name] = i
+		}
+
+		if v.Driver != "" && !contains(ormDrivers, v.Driver) {
+			errs = append(errs, &config.RuleError{
+				Key:  fmt.Sprintf("client.service.%d.driver", i),
+				Rule: "oneof=" + strings.Join(ormDrivers, " "),
+			})
+		}
+
+		if v.Mode != "" && !contains(redisModes, v.Mode) {
+			errs = append(errs, &config.RuleError{
+				Key:  fmt.Sprintf("client.service.%d.mode", i),
+				Rule: "oneof=" + strings.Join(redisModes, " "),
+			})
+		}
+
+		if v.ReplicaSelector != "" && !contains(replicaSelectors, v.ReplicaSelector) {
+			errs = append(errs, &config.RuleError{
+				Key:  fmt.Sprintf("client.service.%d.replica_selector", i),
+				Rule: "oneof=" + strings.Join(replicaSelectors, " "),
+			})
+		}
+
+		if v.Mode == "sentinel" && v.MasterName == "" {
+			errs = append(errs, &config.RuleError{
+				Key:  fmt.Sprintf("client.service.%d.master_name", i),
+				Rule: "required",
+			})
+		}
+	}
+
+	return newValidationError(errs)
+}
+
+// unknownKeysValidator report the unknown keys in the client sections, the
+// misspelled key will be ignored by clients silently.
+//
+// Only the sections known by schema are checked, other client.* sections
+// are left to the application.
+func unknownKeysValidator(c config.Configure) error {
+	client := map[string]interface{}{}
+	if err := c.UnmarshalKey("client", &client); err != nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(clientSchema{})
+	fields := schemaFields(t)
+
+	var errs []error
+	for _, k := range sortedKeys(client) {
+		field, exist := fields[k]
+		if !exist {
+			continue
+		}
+
+		errs = append(errs, unknownKeys("client."+k, client[k], field)...)
+	}
+
+	return newValidationError(errs)
+}
+
+func unknownKeys(key string, val interface{}, t reflect.Type) []error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		var errs []error
+		fields := schemaFields(t)
+		for _, k := range sortedKeys(m) {
+			field, exist := fields[k]
+			if !exist {
+				errs = append(errs, fmt.Errorf("key:%s.%s unknown", key, k))
+				continue
+			}
+
+			errs = append(errs, unknownKeys(key+"."+k, m[k], field)...)
+		}
+		return errs
+	case reflect.Slice:
+		items, ok := val.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		var errs []error
+		for i, v := range items {
+			errs = append(errs, unknownKeys(key+"."+strconv.Itoa(i), v, t.Elem())...)
+		}
+		return errs
+	default:
+		return nil
+	}
+}
+
+// schemaFields return the field types by yaml key, the inline fields are
+// flattened.
+func schemaFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		if len(tag) > 1 && tag[1] == "inline" {
+			for k, v := range schemaFields(field.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+
+		if tag[0] == "" || tag[0] == "-" {
+			continue
+		}
+
+		fields[tag[0]] = field.Type
+	}
+
+	return fields
+}
+
+func newValidationError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &config.ValidationError{Errors: errs}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,12 +1,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cast"
 
 	"github.com/wwwangxc/gopkg/config/unmarshaler"
@@ -17,11 +21,22 @@ func Load(path string, opts ...LoadOption) (Configure, error) {
 	return defaultLoader.Load(path, opts...)
 }
 
+// LoadSource load config from source
+//
+// The source will be watched if it implements Watcher.
+// The unmarshaler is used to unmarshal the source data into struct, default yaml.
+func LoadSource(source Source, opts ...LoadOption) (Configure, error) {
+	return defaultLoader.LoadSource(source, opts...)
+}
+
 // Configure ...
 //go:generate mockgen -source=config.go -destination=mockconfig/config_mock.go -package=mockconfig
 type Configure interface {
 
 	// Unmarshal unmarshal config raw data
+	//
+	// The struct fields the config leaves out will be set by the default
+	// tag, e.g. `yaml:"timeout" default:"1s"`.
 	Unmarshal(interface{}) error
 
 	// IsExist check the key exist
@@ -92,36 +107,263 @@ type Configure interface {
 	// return defaultVal, when key not exist
 	// k support key1.key2.key3
 	GetFloat64(string, float64) float64
+
+	// GetDuration get time.Duration value by key
+	//
+	// return defaultVal, when key not exist
+	// integer value means nanoseconds, string value like "1s" or "500ms"
+	// k support key1.key2.key3
+	GetDuration(string, time.Duration) time.Duration
+
+	// GetTime get time.Time value by key
+	//
+	// return defaultVal, when key not exist
+	// k support key1.key2.key3
+	GetTime(string, time.Time) time.Time
+
+	// GetStringSlice get []string value by key
+	//
+	// return defaultVal, when key not exist
+	// k support key1.key2.key3
+	GetStringSlice(string, []string) []string
+
+	// GetIntSlice get []int value by key
+	//
+	// return defaultVal, when key not exist
+	// k support key1.key2.key3
+	GetIntSlice(string, []int) []int
+
+	// GetStringMap get map[string]interface{} value by key
+	//
+	// return defaultVal, when key not exist
+	// k support key1.key2.key3
+	GetStringMap(string, map[string]interface{}) map[string]interface{}
+
+	// GetStringMapString get map[string]string value by key
+	//
+	// return defaultVal, when key not exist
+	// k support key1.key2.key3
+	GetStringMapString(string, map[string]string) map[string]string
+
+	// GetStringE get string value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to string
+	// k support key1.key2.key3
+	GetStringE(string) (string, error)
+
+	// GetBoolE get bool value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to bool
+	// k support key1.key2.key3
+	GetBoolE(string) (bool, error)
+
+	// GetIntE get int value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to int
+	// k support key1.key2.key3
+	GetIntE(string) (int, error)
+
+	// GetInt32E get int32 value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to int32
+	// k support key1.key2.key3
+	GetInt32E(string) (int32, error)
+
+	// GetInt64E get int64 value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to int64
+	// k support key1.key2.key3
+	GetInt64E(string) (int64, error)
+
+	// GetUintE get uint value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to uint
+	// k support key1.key2.key3
+	GetUintE(string) (uint, error)
+
+	// GetUint32E get uint32 value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to uint32
+	// k support key1.key2.key3
+	GetUint32E(string) (uint32, error)
+
+	// GetUint64E get uint64 value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to uint64
+	// k support key1.key2.key3
+	GetUint64E(string) (uint64, error)
+
+	// GetFloat32E get float32 value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to float32
+	// k support key1.key2.key3
+	GetFloat32E(string) (float32, error)
+
+	// GetFloat64E get float64 value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to float64
+	// k support key1.key2.key3
+	GetFloat64E(string) (float64, error)
+
+	// GetDurationE get time.Duration value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to time.Duration
+	// integer value means nanoseconds, string value like "1s" or "500ms"
+	// k support key1.key2.key3
+	GetDurationE(string) (time.Duration, error)
+
+	// GetTimeE get time.Time value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to time.Time
+	// k support key1.key2.key3
+	GetTimeE(string) (time.Time, error)
+
+	// GetStringSliceE get []string value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to []string
+	// k support key1.key2.key3
+	GetStringSliceE(string) ([]string, error)
+
+	// GetIntSliceE get []int value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to []int
+	// k support key1.key2.key3
+	GetIntSliceE(string) ([]int, error)
+
+	// GetStringMapE get map[string]interface{} value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to map[string]interface{}
+	// k support key1.key2.key3
+	GetStringMapE(string) (map[string]interface{}, error)
+
+	// GetStringMapStringE get map[string]string value by key
+	//
+	// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch
+	// when the value can not cast to map[string]string
+	// k support key1.key2.key3
+	GetStringMapStringE(string) (map[string]string, error)
+
+	// UnmarshalKey unmarshal the value of key into out
+	//
+	// k support key1.key2.key3, and "" means the whole config.
+	// The default tag is supported as Unmarshal.
+	UnmarshalKey(string, interface{}) error
+
+	// Sub return the scoped view of key prefix
+	//
+	// All keys of the view are relative to the prefix, e.g.
+	// Sub("client").GetInt("redis.timeout", 0) equals to
+	// GetInt("client.redis.timeout", 0).
+	Sub(string) Configure
+
+	// OnChange subscribe the change of key
+	//
+	// callback will be called with the old and new value when the value of
+	// key changed after reload. The value is nil when key not exist.
+	// k support key1.key2.key3, a prefix key1.key2 will be notified when
+	// any key under it changed, and "" will be notified on any change.
+	// callback is called synchronously and should not block.
+	OnChange(string, func(old, new interface{}))
+
+	// Bind bind the value of key to a typed snapshot
+	//
+	// The snapshot is swapped atomically on every successful reload that
+	// changes the key. ptr must be a non-nil pointer and is used as the first
+	// snapshot, so do not modify it after bind.
+	// k support key1.key2.key3, and "" means the whole config.
+	Bind(string, interface{}) (*Binding, error)
+
+	// Close stop watching and release the watchers
+	//
+	// The config will be removed from the loader cache, and the next Load
+	// will load it again.
+	Close() error
 }
 
 // configureImpl ...
 type configureImpl struct {
 	path            string
+	source          Source
 	rawData         []byte
 	unmarshaledData map[string]interface{}
 
-	rw            sync.RWMutex
-	watchCallback func(Configure)
-	unmarshaler   unmarshaler.Unmarshaler
-	watcher       *fsnotify.Watcher
+	rw             sync.RWMutex
+	watchCallbacks []func(Configure)
+	unmarshaler    unmarshaler.Unmarshaler
+	watcher        Watcher
+	layers         []Source
+	subscribers    []changeSubscriber
+	validators     []Validator
+
+	reloadErrorCallbacks []func(error)
+
+	decryptor     Decryptor
+	secrets       map[string]struct{} // masked in logs
+	loadedSecrets map[string]struct{} // of the config loaded
+	readSecrets   map[string]struct{} // of the config read last time
+	secretsRW     sync.RWMutex
+
+	closeOnce sync.Once
+	onClose   func()
+}
+
+// changeSubscriber ...
+type changeSubscriber struct {
+	key      string
+	callback func(old, new interface{})
 }
 
 func defaultConfigure(path string) *configureImpl {
 	c := &configureImpl{
 		path:        path,
+		unmarshaler: unmarshalerByPath(path),
+	}
+
+	c.watcher = newFileWatcher(path)
+	return c
+}
+
+// unmarshalerByPath get unmarshaler by file extension, return yaml
+// unmarshaler when no unmarshaler registered for the extension.
+func unmarshalerByPath(path string) unmarshaler.Unmarshaler {
+	if u := unmarshaler.GetByExtension(filepath.Ext(path)); u != nil {
+		return u
+	}
+
+	return &unmarshaler.YAML{}
+}
+
+func sourceConfigure(source Source) *configureImpl {
+	c := &configureImpl{
+		source:      source,
 		unmarshaler: &unmarshaler.YAML{},
 	}
 
-	var err error
-	c.watcher, err = fsnotify.NewWatcher()
-	if err != nil {
-		logErrorf("%s: new file watcher fail. err:%v\n", packageName, err)
+	if watcher, ok := source.(Watcher); ok {
+		c.watcher = watcher
 	}
 
 	return c
 }
 
 // Unmarshal unmarshal config raw data
+//
+// The fields the config leaves out will be set by the default tag.
 func (c *configureImpl) Unmarshal(out interface{}) error {
 	if c.unmarshaler == nil {
 		return ErrUnmarshalerNotExist
@@ -130,7 +372,17 @@ func (c *configureImpl) Unmarshal(out interface{}) error {
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
-	return c.unmarshaler.Unmarshal(c.rawData, out)
+	// the values of sources and layers may be raw strings, e.g. env, they
+	// are converted by the type of out
+	if c.source != nil || len(c.layers) > 0 {
+		return c.decode(c.unmarshaledData, out)
+	}
+
+	if err := c.unmarshaler.Unmarshal(c.rawData, out); err != nil {
+		return err
+	}
+
+	return setDefaults(out, c.unmarshaledData, c.structTagName())
 }
 
 // IsExist check the key exist
@@ -232,6 +484,101 @@ func (c *configureImpl) GetFloat64(k string, defaultVal float64) float64 {
 	return cast.ToFloat64(c.getWithDefaultVal(k, defaultVal))
 }
 
+// GetDuration get time.Duration value by key
+//
+// return defaultVal, when key not exist
+// integer value means nanoseconds, string value like "1s" or "500ms"
+// k support key1.key2.key3
+func (c *configureImpl) GetDuration(k string, defaultVal time.Duration) time.Duration {
+	return cast.ToDuration(c.getWithDefaultVal(k, defaultVal))
+}
+
+// GetTime get time.Time value by key
+//
+// return defaultVal, when key not exist
+// k support key1.key2.key3
+func (c *configureImpl) GetTime(k string, defaultVal time.Time) time.Time {
+	return cast.ToTime(c.getWithDefaultVal(k, defaultVal))
+}
+
+// GetStringSlice get []string value by key
+//
+// return defaultVal, when key not exist
+// k support key1.key2.key3
+func (c *configureImpl) GetStringSlice(k string, defaultVal []string) []string {
+	return cast.ToStringSlice(c.getWithDefaultVal(k, defaultVal))
+}
+
+// GetIntSlice get []int value by key
+//
+// return defaultVal, when key not exist
+// k support key1.key2.key3
+func (c *configureImpl) GetIntSlice(k string, defaultVal []int) []int {
+	return cast.ToIntSlice(c.getWithDefaultVal(k, defaultVal))
+}
+
+// GetStringMap get map[string]interface{} value by key
+//
+// return defaultVal, when key not exist
+// k support key1.key2.key3
+func (c *configureImpl) GetStringMap(k string, defaultVal map[string]interface{}) map[string]interface{} {
+	return cast.ToStringMap(c.getWithDefaultVal(k, defaultVal))
+}
+
+// GetStringMapString get map[string]string value by key
+//
+// return defaultVal, when key not exist
+// k support key1.key2.key3
+func (c *configureImpl) GetStringMapString(k string, defaultVal map[string]string) map[string]string {
+	return cast.ToStringMapString(c.getWithDefaultVal(k, defaultVal))
+}
+
+// UnmarshalKey unmarshal the value of key into out
+//
+// k support key1.key2.key3, and "" means the whole config.
+func (c *configureImpl) UnmarshalKey(k string, out interface{}) error {
+	if k == "" {
+		return c.Unmarshal(out)
+	}
+
+	val, err := c.get(k)
+	if err != nil {
+		return err
+	}
+
+	return c.decode(val, out)
+}
+
+// Sub return the scoped view of key prefix
+//
+// All keys of the view are relative to the prefix, e.g.
+// Sub("client").GetInt("redis.timeout", 0) equals to
+// GetInt("client.redis.timeout", 0).
+func (c *configureImpl) Sub(prefix string) Configure {
+	return newSubConfigure(c, prefix)
+}
+
+// OnChange subscribe the change of key
+//
+// callback will be called with the old and new value when the value of
+// key changed after reload. The value is nil when key not exist.
+// k support key1.key2.key3, a prefix key1.key2 will be notified when
+// any key under it changed, and "" will be notified on any change.
+// callback is called synchronously and should not block.
+func (c *configureImpl) OnChange(k string, callback func(old, new interface{})) {
+	if callback == nil {
+		return
+	}
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.subscribers = append(c.subscribers, changeSubscriber{
+		key:      k,
+		callback: callback,
+	})
+}
+
 func (c *configureImpl) getWithDefaultVal(k string, defaultVal interface{}) interface{} {
 	data, err := c.get(k)
 	if err != nil {
@@ -259,6 +606,18 @@ func (c *configureImpl) getWithDefaultVal(k string, defaultVal interface{}) inte
 		_, err = cast.ToFloat64E(data)
 	case float32:
 		_, err = cast.ToFloat32E(data)
+	case time.Duration:
+		_, err = cast.ToDurationE(data)
+	case time.Time:
+		_, err = cast.ToTimeE(data)
+	case []string:
+		_, err = cast.ToStringSliceE(data)
+	case []int:
+		_, err = cast.ToIntSliceE(data)
+	case map[string]interface{}:
+		_, err = cast.ToStringMapE(data)
+	case map[string]string:
+		_, err = cast.ToStringMapStringE(data)
 	default:
 		return defaultVal
 	}
@@ -274,8 +633,7 @@ func (c *configureImpl) get(k string) (interface{}, error) {
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
-	subkeys := strings.Split(k, ".")
-	val, exist := fetchFromMap(c.unmarshaledData, subkeys)
+	val, exist := fetchByKey(c.unmarshaledData, k)
 	if !exist {
 		return nil, ErrConfigNotExist
 	}
@@ -289,16 +647,17 @@ func (c *configureImpl) Load() error {
 		return ErrUnmarshalerNotExist
 	}
 
-	data, err := ioutil.ReadFile(c.path)
+	data, unmarshaledData, err := c.read()
 	if err != nil {
-		return fmt.Errorf("read file fail. err:%w", err)
+		return err
 	}
 
-	unmarshaledData := map[string]interface{}{}
-	if err = c.unmarshaler.Unmarshal(data, &unmarshaledData); err != nil {
-		return fmt.Errorf("unmarshal fail. err:%w", err)
+	if err = c.validate(data, unmarshaledData); err != nil {
+		return err
 	}
 
+	c.applyReadSecrets()
+
 	c.rw.Lock()
 	defer c.rw.Unlock()
 
@@ -309,55 +668,259 @@ func (c *configureImpl) Load() error {
 }
 
 // Reload ...
-func (c *configureImpl) Reload() {
+//
+// The last good config will be kept when reload fail, and the error will be
+// reported to the reload error callback.
+func (c *configureImpl) Reload() error {
 	if c.unmarshaler == nil {
-		return
+		return ErrUnmarshalerNotExist
+	}
+
+	data, unmarshaledData, err := c.read()
+	if err == nil {
+		err = c.validate(data, unmarshaledData)
 	}
 
-	data, err := ioutil.ReadFile(c.path)
 	if err != nil {
-		logErrorf("%s: reload file fail. err:%v\n", packageName, err)
-		return
+		c.logErrorf("%s: reload fail. err:%v\n", packageName, err)
+
+		c.rw.RLock()
+		callbacks := c.reloadErrorCallbacks
+		c.rw.RUnlock()
+		for _, callback := range callbacks {
+			callback(err)
+		}
+		return err
 	}
 
-	unmarshalData := map[string]interface{}{}
-	if err = c.unmarshaler.Unmarshal(data, &unmarshalData); err != nil {
-		logErrorf("%s: reload file unmarshal fail. err:%v\n", packageName, err)
-		return
+	c.applyReadSecrets()
+
+	c.rw.Lock()
+	oldData := c.unmarshaledData
+	c.rawData = data
+	c.unmarshaledData = unmarshaledData
+	subscribers := c.subscribers
+	c.rw.Unlock()
+
+	notifyChange(subscribers, oldData, unmarshaledData)
+	return nil
+}
+
+// validate validate the new config with validators before it is applied
+func (c *configureImpl) validate(data []byte, unmarshaledData map[string]interface{}) error {
+	c.rw.RLock()
+	validators := c.validators
+	c.rw.RUnlock()
+
+	return c.validateWith(validators, data, unmarshaledData)
+}
+
+func (c *configureImpl) validateWith(validators []Validator, data []byte,
+	unmarshaledData map[string]interface{}) error {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	candidate := &configureImpl{
+		path:            c.path,
+		source:          c.source,
+		rawData:         data,
+		unmarshaledData: unmarshaledData,
+		unmarshaler:     c.unmarshaler,
+	}
+
+	var errs []error
+	for _, validator := range validators {
+		err := validator(candidate)
+		if err == nil {
+			continue
+		}
+
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			errs = append(errs, validationErr.Errors...)
+			continue
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{
+		Name:   c.name(),
+		Errors: errs,
+	}
+}
+
+// name return config file path or source name
+func (c *configureImpl) name() string {
+	if c.source != nil {
+		return c.source.Name()
+	}
+
+	return c.path
+}
+
+// notifyChange call the subscribers whose key value changed
+func notifyChange(subscribers []changeSubscriber, oldData, newData map[string]interface{}) {
+	for _, v := range subscribers {
+		oldVal, _ := fetchByKey(oldData, v.key)
+		newVal, _ := fetchByKey(newData, v.key)
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+
+		v.callback(oldVal, newVal)
+	}
+}
+
+// read read config file or source and merge the layers over it
+//
+// The raw data will be re-marshaled from the merged data when config is
+// loaded from source or any layer exist.
+func (c *configureImpl) read() ([]byte, map[string]interface{}, error) {
+	var data []byte
+	var unmarshaledData map[string]interface{}
+	var err error
+
+	if c.source != nil {
+		unmarshaledData, err = c.source.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read source %s fail. err:%w", c.source.Name(), err)
+		}
+	} else {
+		data, err = ioutil.ReadFile(c.path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read file fail. err:%w", err)
+		}
+
+		unmarshaledData = map[string]interface{}{}
+		if err = c.unmarshaler.Unmarshal(data, &unmarshaledData); err != nil {
+			return nil, nil, fmt.Errorf("unmarshal fail. err:%w", err)
+		}
+
+	}
+
+	for _, layer := range c.layers {
+		layerData, err := layer.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read layer %s fail. err:%w", layer.Name(), err)
+		}
+
+		mergeMap(unmarshaledData, layerData)
+	}
+
+	encrypted, err := c.decrypt(unmarshaledData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.source == nil && len(c.layers) == 0 && !encrypted {
+		return data, unmarshaledData, nil
+	}
+
+	marshaler, ok := c.unmarshaler.(unmarshaler.Marshaler)
+	if !ok {
+		return nil, nil, fmt.Errorf("unmarshaler %s not support marshal", c.unmarshaler.Name())
+	}
+
+	data, err = marshaler.Marshal(unmarshaledData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal merged data fail. err:%w", err)
+	}
+
+	return data, unmarshaledData, nil
+}
+
+// attach add the callbacks and validators of other to the config, the
+// current config is validated by the added validators first
+func (c *configureImpl) attach(other *configureImpl) error {
+	c.rw.RLock()
+	data, unmarshaledData := c.rawData, c.unmarshaledData
+	c.rw.RUnlock()
+
+	if err := c.validateWith(other.validators, data, unmarshaledData); err != nil {
+		return err
 	}
 
 	c.rw.Lock()
 	defer c.rw.Unlock()
 
-	c.rawData = data
-	c.unmarshaledData = unmarshalData
+	c.watchCallbacks = append(c.watchCallbacks, other.watchCallbacks...)
+	c.reloadErrorCallbacks = append(c.reloadErrorCallbacks, other.reloadErrorCallbacks...)
+	c.validators = append(c.validators, other.validators...)
+	return nil
 }
 
-func (c *configureImpl) watch(callback func(*configureImpl)) {
-	if c.watcher == nil {
-		return
+func (c *configureImpl) watch() {
+	notify := func() {
+		if err := c.Reload(); err != nil {
+			return
+		}
+
+		c.rw.RLock()
+		callbacks := c.watchCallbacks
+		c.rw.RUnlock()
+		for _, callback := range callbacks {
+			go callback(c)
+		}
 	}
 
-	go func() {
-		for event := range c.watcher.Events {
-			if event.Op&fsnotify.Write != fsnotify.Write {
-				logInfo("%s: ignore file event:%s. file:%s\n", packageName, event.Name, c.path)
-				continue
-			}
+	for _, watcher := range c.watchers() {
+		if err := watcher.Watch(notify); err != nil {
+			logErrorf("%s: watch fail. err:%v\n", packageName, err)
+		}
+	}
+}
 
-			c.Reload()
+// Close stop watching and release the watchers
+func (c *configureImpl) Close() error {
+	var errs []string
+	c.closeOnce.Do(func() {
+		if c.onClose != nil {
+			c.onClose()
+		}
 
-			if callback != nil {
-				callback(c)
+		for _, watcher := range c.watchers() {
+			if err := watcher.Close(); err != nil {
+				errs = append(errs, err.Error())
 			}
+		}
+	})
 
-			if c.watchCallback != nil {
-				go c.watchCallback(c)
-			}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: close watcher fail. err:%s", packageName, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// watchers return the watcher of config file or source and the watchers of layers
+func (c *configureImpl) watchers() []Watcher {
+	var watchers []Watcher
+	if c.watcher != nil {
+		watchers = append(watchers, c.watcher)
+	}
+
+	for _, layer := range c.layers {
+		if watcher, ok := layer.(Watcher); ok {
+			watchers = append(watchers, watcher)
 		}
+	}
 
-		logInfo("%s: break file watch. file:%s\n", packageName, c.path)
-	}()
+	return watchers
+}
+
+// fetchByKey fetch value by key, return the whole map when key is empty
+func fetchByKey(m map[string]interface{}, k string) (interface{}, bool) {
+	if k == "" {
+		return m, m != nil
+	}
+
+	return fetchFromMap(m, strings.Split(k, "."))
 }
 
 func fetchFromMap(m map[string]interface{}, subkeys []string) (interface{}, bool) {
@@ -374,11 +937,72 @@ func fetchFromMap(m map[string]interface{}, subkeys []string) (interface{}, bool
 		return data, true
 	}
 
+	return fetchFromValue(data, subkeys[1:])
+}
+
+// fetchFromValue fetch from map or slice, the subkey of slice is the index
+func fetchFromValue(data interface{}, subkeys []string) (interface{}, bool) {
 	switch val := data.(type) {
 	case map[interface{}]interface{}:
-		return fetchFromMap(cast.ToStringMap(val), subkeys[1:])
+		return fetchFromMap(cast.ToStringMap(val), subkeys)
+	case map[string]interface{}:
+		return fetchFromMap(val, subkeys)
+	case []interface{}:
+		i, err := strconv.Atoi(subkeys[0])
+		if err != nil || i < 0 || i >= len(val) {
+			return nil, false
+		}
+
+		if len(subkeys) == 1 {
+			return val[i], true
+		}
+
+		return fetchFromValue(val[i], subkeys[1:])
+	default:
+		return nil, false
+	}
+}
+
+func setToMap(m map[string]interface{}, subkeys []string, val interface{}) {
+	if len(subkeys) == 0 {
+		return
+	}
+
+	if len(subkeys) == 1 {
+		m[subkeys[0]] = val
+		return
+	}
+
+	sub, ok := toStringMap(m[subkeys[0]])
+	if !ok {
+		sub = map[string]interface{}{}
+	}
+
+	setToMap(sub, subkeys[1:], val)
+	m[subkeys[0]] = sub
+}
+
+// mergeMap deep merge src into dst, the value of src takes precedence
+func mergeMap(dst, src map[string]interface{}) {
+	for k, srcVal := range src {
+		srcMap, srcIsMap := toStringMap(srcVal)
+		dstMap, dstIsMap := toStringMap(dst[k])
+		if srcIsMap && dstIsMap {
+			mergeMap(dstMap, srcMap)
+			dst[k] = dstMap
+			continue
+		}
+
+		dst[k] = srcVal
+	}
+}
+
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		return cast.ToStringMap(val), true
 	case map[string]interface{}:
-		return fetchFromMap(val, subkeys[1:])
+		return val, true
 	default:
 		return nil, false
 	}
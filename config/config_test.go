@@ -1,6 +1,8 @@
 package config
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -843,3 +845,243 @@ func Test_configureImpl_load(t *testing.T) {
 		})
 	}
 }
+
+func Test_configureImpl_LoadWithLayer(t *testing.T) {
+	c := defaultConfigure("./testdata/config.yaml")
+	c.watcher = nil
+	WithLayer(NewFileSource("./testdata/config.prod.yaml", "yaml"))(c)
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	if got := c.GetString("string_value", ""); got != "prod string value" {
+		t.Errorf("configureImpl.GetString() = %v, want %v", got, "prod string value")
+	}
+
+	if got := c.GetInt("subkey.int_value", 0); got != -100 {
+		t.Errorf("configureImpl.GetInt() = %v, want %v", got, -100)
+	}
+
+	if got := c.GetString("subkey.string_value", ""); got != "string value" {
+		t.Errorf("configureImpl.GetString() = %v, want %v", got, "string value")
+	}
+
+	out := struct {
+		Subkey struct {
+			IntValue  int    `yaml:"int_value"`
+			ProdValue string `yaml:"prod_value"`
+		} `yaml:"subkey"`
+	}{}
+	if err := c.Unmarshal(&out); err != nil {
+		t.Fatalf("configureImpl.Unmarshal() error = %v", err)
+	}
+
+	if out.Subkey.IntValue != -100 || out.Subkey.ProdValue != "prod" {
+		t.Errorf("configureImpl.Unmarshal() = %+v", out)
+	}
+}
+
+func Test_configureImpl_LoadWithEnvLayer(t *testing.T) {
+	t.Setenv("GOPKG_TEST_SUBKEY__INT_VALUE", "-200")
+	t.Setenv("GOPKG_TEST_SUBKEY__ZIP", "007")
+	t.Setenv("GOPKG_TEST_SUBKEY__VERSION", "1.10")
+	t.Setenv("GOPKG_TEST_SUBKEY__NAN", "nan")
+	t.Setenv("GOPKG_TEST_SUBKEY__RATE", "1.10")
+	t.Setenv("GOPKG_TEST_SUBKEY__ENABLE", "true")
+
+	c := defaultConfigure("./testdata/config.yaml")
+	c.watcher = nil
+	WithLayer(NewEnvSource("GOPKG_TEST_", "__"))(c)
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	if !c.IsExist("subkey.int_value") {
+		t.Errorf("configureImpl.IsExist() = false, want true")
+	}
+
+	if got := c.GetInt("subkey.int_value", 0); got != -200 {
+		t.Errorf("configureImpl.GetInt() = %v, want %v", got, -200)
+	}
+
+	// the raw strings are kept
+	for k, want := range map[string]string{
+		"subkey.zip":     "007",
+		"subkey.version": "1.10",
+		"subkey.nan":     "nan",
+	} {
+		if got := c.GetString(k, ""); got != want {
+			t.Errorf("configureImpl.GetString(%s) = %v, want %v", k, got, want)
+		}
+	}
+
+	type subkey struct {
+		IntValue int     `yaml:"int_value"`
+		Zip      string  `yaml:"zip"`
+		Version  string  `yaml:"version"`
+		Nan      string  `yaml:"nan"`
+		Rate     float64 `yaml:"rate"`
+		Enable   bool    `yaml:"enable"`
+	}
+	out := struct {
+		Subkey subkey `yaml:"subkey"`
+	}{}
+	if err := c.Unmarshal(&out); err != nil {
+		t.Fatalf("configureImpl.Unmarshal() error = %v", err)
+	}
+
+	want := subkey{
+		IntValue: -200,
+		Zip:      "007",
+		Version:  "1.10",
+		Nan:      "nan",
+		Rate:     1.1,
+		Enable:   true,
+	}
+	if out.Subkey != want {
+		t.Errorf("configureImpl.Unmarshal() = %+v, want %+v", out.Subkey, want)
+	}
+
+	var sub subkey
+	if err := c.UnmarshalKey("subkey", &sub); err != nil {
+		t.Fatalf("configureImpl.UnmarshalKey() error = %v", err)
+	}
+
+	if sub != want {
+		t.Errorf("configureImpl.UnmarshalKey() = %+v, want %+v", sub, want)
+	}
+}
+
+func Test_configureImpl_OnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("feature:\n  a: true\n  b: false\nname: test\n"), 0644); err != nil {
+		t.Fatalf("write file fail. err:%v", err)
+	}
+
+	c := defaultConfigure(path)
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	type change struct {
+		old interface{}
+		new interface{}
+	}
+	changes := map[string][]change{}
+	for _, k := range []string{"feature.a", "feature.b", "feature", "name", "feature.c"} {
+		k := k
+		c.OnChange(k, func(old, new interface{}) {
+			changes[k] = append(changes[k], change{old: old, new: new})
+		})
+	}
+
+	if err := ioutil.WriteFile(path, []byte("feature:\n  a: true\n  b: true\n  c: 1\nname: test\n"), 0644); err != nil {
+		t.Fatalf("write file fail. err:%v", err)
+	}
+	c.Reload()
+
+	want := map[string][]change{
+		"feature.b": {{old: false, new: true}},
+		"feature.c": {{old: nil, new: 1}},
+		"feature": {{
+			old: map[string]interface{}{"a": true, "b": false},
+			new: map[string]interface{}{"a": true, "b": true, "c": 1},
+		}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("configureImpl.OnChange() changes = %v, want %v", changes, want)
+	}
+}
+
+func Test_configureImpl_LoadHCLWithLayer(t *testing.T) {
+	t.Setenv("GOPKG_TEST_ENV", "env value")
+	t.Setenv("GOPKG_HCL_SUBKEY__INT_VALUE", "2")
+
+	c := defaultConfigure("./testdata/config.hcl")
+	c.watcher = nil
+	WithLayer(NewEnvSource("GOPKG_HCL_", "__"))(c)
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	if got := c.GetString("string_value", ""); got != "string value" {
+		t.Errorf("configureImpl.GetString() = %v, want %v", got, "string value")
+	}
+
+	if got := c.GetInt("subkey.int_value", 0); got != 2 {
+		t.Errorf("configureImpl.GetInt() = %v, want %v", got, 2)
+	}
+
+	out := struct {
+		IntValue  int  `hcl:"int_value"`
+		BoolValue bool `hcl:"bool_value"`
+	}{}
+	if err := c.UnmarshalKey("subkey", &out); err != nil {
+		t.Fatalf("configureImpl.UnmarshalKey() error = %v", err)
+	}
+
+	if out.IntValue != 2 || !out.BoolValue {
+		t.Errorf("configureImpl.UnmarshalKey() = %+v", out)
+	}
+}
+
+func Test_configureImpl_LoadByExtension(t *testing.T) {
+	t.Setenv("GOPKG_TEST_ENV", "env value")
+
+	tests := []struct {
+		name        string
+		path        string
+		unmarshaler string
+	}{
+		{
+			name:        "ini",
+			path:        "./testdata/config.ini",
+			unmarshaler: "ini",
+		},
+		{
+			name:        "dotenv",
+			path:        "./testdata/config.env",
+			unmarshaler: "dotenv",
+		},
+		{
+			name:        "properties",
+			path:        "./testdata/config.properties",
+			unmarshaler: "properties",
+		},
+		{
+			name:        "hcl",
+			path:        "./testdata/config.hcl",
+			unmarshaler: "hcl",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfigure(tt.path)
+			c.watcher = nil
+			if err := c.Load(); err != nil {
+				t.Fatalf("configureImpl.Load() error = %v", err)
+			}
+
+			if got := c.unmarshaler.Name(); got != tt.unmarshaler {
+				t.Errorf("configureImpl.unmarshaler = %v, want %v", got, tt.unmarshaler)
+			}
+
+			if got := c.GetString("string_value", ""); got != "string value" {
+				t.Errorf("configureImpl.GetString() = %v, want %v", got, "string value")
+			}
+
+			if got := c.GetString("env_value", ""); got != "env value" {
+				t.Errorf("configureImpl.GetString() = %v, want %v", got, "env value")
+			}
+
+			if got := c.GetInt("subkey.int_value", 0); got != -1 {
+				t.Errorf("configureImpl.GetInt() = %v, want %v", got, -1)
+			}
+
+			if got := c.GetBool("subkey.bool_value", false); !got {
+				t.Errorf("configureImpl.GetBool() = %v, want %v", got, true)
+			}
+		})
+	}
+}
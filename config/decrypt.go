@@ -0,0 +1,326 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	encryptedPrefix = "ENC("
+	encryptedSuffix = ")"
+
+	// EnvDecryptKey the environment variable of the base64 encoded aes key
+	// used by the default decryptor
+	EnvDecryptKey = "GOPKG_CONFIG_KEY"
+
+	// EnvDecryptKeyFile the environment variable of the aes key file path
+	// used by the default decryptor
+	EnvDecryptKeyFile = "GOPKG_CONFIG_KEY_FILE"
+
+	secretMask = "******"
+
+	// secretMinLength the shorter secrets are not masked, they are too
+	// common in logs
+	secretMinLength = 4
+)
+
+var (
+	defaultDecryptor   Decryptor
+	defaultDecryptorRW sync.RWMutex
+)
+
+// Decryptor config value decryptor
+//
+// The encrypted value is written as ENC(base64 ciphertext) in config, and
+// will be decrypted transparently in Get* and Unmarshal.
+type Decryptor interface {
+
+	// Decrypt decrypt the ciphertext
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// SetDefaultDecryptor set the decryptor used when WithDecryptor is not given
+//
+// By default, the aes-gcm decryptor will be used when the environment
+// variable GOPKG_CONFIG_KEY or GOPKG_CONFIG_KEY_FILE is set.
+func SetDefaultDecryptor(decryptor Decryptor) {
+	defaultDecryptorRW.Lock()
+	defer defaultDecryptorRW.Unlock()
+	defaultDecryptor = decryptor
+}
+
+func getDefaultDecryptor() (Decryptor, error) {
+	defaultDecryptorRW.RLock()
+	decryptor := defaultDecryptor
+	defaultDecryptorRW.RUnlock()
+
+	if decryptor != nil {
+		return decryptor, nil
+	}
+
+	if path := os.Getenv(EnvDecryptKeyFile); path != "" {
+		return NewAESGCMDecryptorFromFile(path)
+	}
+
+	if _, exist := os.LookupEnv(EnvDecryptKey); exist {
+		return NewAESGCMDecryptorFromEnv(EnvDecryptKey)
+	}
+
+	return nil, fmt.Errorf("decryptor not exist, set %s or %s", EnvDecryptKey, EnvDecryptKeyFile)
+}
+
+// aesGCMDecryptor aes-gcm decryptor
+//
+// The ciphertext is the nonce followed by the sealed data.
+type aesGCMDecryptor struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMDecryptor new aes-gcm decryptor
+//
+// The key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewAESGCMDecryptor(key []byte) (Decryptor, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesGCMDecryptor{
+		aead: aead,
+	}, nil
+}
+
+// NewAESGCMDecryptorFromFile new aes-gcm decryptor with the base64 encoded
+// key in file
+func NewAESGCMDecryptorFromFile(path string) (Decryptor, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read key file fail. err:%w", packageName, err)
+	}
+
+	key, err := decodeKey(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAESGCMDecryptor(key)
+}
+
+// NewAESGCMDecryptorFromEnv new aes-gcm decryptor with the base64 encoded
+// key in environment variable
+func NewAESGCMDecryptorFromEnv(name string) (Decryptor, error) {
+	key, err := decodeKey(os.Getenv(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAESGCMDecryptor(key)
+}
+
+// Decrypt decrypt the ciphertext
+func (a *aesGCMDecryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := a.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("%s: ciphertext too short", packageName)
+	}
+
+	plaintext, err := a.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decrypt fail. err:%w", packageName, err)
+	}
+
+	return plaintext, nil
+}
+
+// EncryptAESGCM encrypt the plaintext with aes-gcm and return the value
+// written in config, e.g. ENC(base64 ciphertext)
+func EncryptAESGCM(key, plaintext []byte) (string, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("%s: generate nonce fail. err:%w", packageName, err)
+	}
+
+	ciphertext := aead.Seal(nonce, nonce, plaintext, nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext) + encryptedSuffix, nil
+}
+
+// IsEncrypted is encrypted value, e.g. ENC(base64 ciphertext)
+func IsEncrypted(val string) bool {
+	val = strings.TrimSpace(val)
+	return strings.HasPrefix(val, encryptedPrefix) && strings.HasSuffix(val, encryptedSuffix)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid aes key. err:%w", packageName, err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: new gcm fail. err:%w", packageName, err)
+	}
+
+	return aead, nil
+}
+
+func decodeKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%s: key must be base64 encoded. err:%w", packageName, err)
+	}
+
+	return key, nil
+}
+
+// decrypt decrypt the encrypted values in data in place
+//
+// Return true when any value decrypted. The plaintext will be recorded as
+// secret and masked in logs. The decryptor is resolved once at the first
+// encrypted value.
+func (c *configureImpl) decrypt(data map[string]interface{}) (bool, error) {
+	decrypted := false
+	secrets := map[string]struct{}{}
+	decryptor := c.decryptor
+	var decryptValue func(key string, val interface{}) (interface{}, error)
+	decryptValue = func(key string, val interface{}) (interface{}, error) {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			for k, item := range v {
+				newItem, err := decryptValue(joinKey(key, k), item)
+				if err != nil {
+					return nil, err
+				}
+				v[k] = newItem
+			}
+			return v, nil
+		case []interface{}:
+			for i, item := range v {
+				newItem, err := decryptValue(joinKey(key, fmt.Sprint(i)), item)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = newItem
+			}
+			return v, nil
+		case string:
+			if !IsEncrypted(v) {
+				return v, nil
+			}
+
+			if decryptor == nil {
+				var err error
+				if decryptor, err = getDefaultDecryptor(); err != nil {
+					return nil, fmt.Errorf("decrypt key %s fail. err:%w", key, err)
+				}
+			}
+
+			plaintext, err := decryptString(decryptor, v)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt key %s fail. err:%w", key, err)
+			}
+
+			decrypted = true
+			secrets[plaintext] = struct{}{}
+			return plaintext, nil
+		default:
+			return v, nil
+		}
+	}
+
+	if _, err := decryptValue("", data); err != nil {
+		return false, err
+	}
+
+	c.setReadSecrets(secrets)
+	return decrypted, nil
+}
+
+func decryptString(decryptor Decryptor, val string) (string, error) {
+	val = strings.TrimSpace(val)
+	ciphertext, err := base64.StdEncoding.DecodeString(val[len(encryptedPrefix) : len(val)-len(encryptedSuffix)])
+	if err != nil {
+		return "", fmt.Errorf("ciphertext must be base64 encoded. err:%w", err)
+	}
+
+	plaintext, err := decryptor.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// setReadSecrets rebuild the masked secrets by the secrets of the config
+// read, and keep the ones of the config loaded, which is kept when the read
+// one fail to apply
+func (c *configureImpl) setReadSecrets(secrets map[string]struct{}) {
+	c.secretsRW.Lock()
+	defer c.secretsRW.Unlock()
+
+	c.readSecrets = secrets
+	c.secrets = make(map[string]struct{}, len(secrets)+len(c.loadedSecrets))
+	for _, m := range []map[string]struct{}{c.loadedSecrets, secrets} {
+		for secret := range m {
+			c.secrets[secret] = struct{}{}
+		}
+	}
+}
+
+// applyReadSecrets drop the secrets of the last config when the config read
+// is applied
+func (c *configureImpl) applyReadSecrets() {
+	c.secretsRW.Lock()
+	defer c.secretsRW.Unlock()
+
+	c.loadedSecrets = c.readSecrets
+	c.secrets = c.readSecrets
+}
+
+// mask replace every decrypted secret in s with secretMask
+//
+// The longer secret is masked first, so the shorter one in it will not
+// leave the rest unmasked. The secrets shorter than secretMinLength are not
+// masked.
+func (c *configureImpl) mask(s string) string {
+	c.secretsRW.RLock()
+	secrets := make([]string, 0, len(c.secrets))
+	for secret := range c.secrets {
+		secrets = append(secrets, secret)
+	}
+	c.secretsRW.RUnlock()
+
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	for _, secret := range secrets {
+		// keep the masked ones fixed width
+		if len(secret) < secretMinLength || strings.Contains(secretMask, secret) {
+			continue
+		}
+
+		s = strings.ReplaceAll(s, secret, secretMask)
+	}
+
+	return s
+}
+
+// logErrorf log error with the decrypted secrets masked
+func (c *configureImpl) logErrorf(format string, args ...interface{}) {
+	logErrorf("%s", c.mask(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+)
+
+func Test_configureImpl_Decrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encrypted, err := EncryptAESGCM(key, []byte("p@ssw0rd"))
+	if err != nil {
+		t.Fatalf("EncryptAESGCM() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := fmt.Sprintf("client:\n  mysql:\n    dsn: %s\n    password: %s\n", "root@tcp(127.0.0.1:3306)/db", encrypted)
+	if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write file error = %v", err)
+	}
+
+	decryptor, err := NewAESGCMDecryptor(key)
+	if err != nil {
+		t.Fatalf("NewAESGCMDecryptor() error = %v", err)
+	}
+
+	t.Run("decrypt in get and unmarshal", func(t *testing.T) {
+		c := defaultConfigure(path)
+		c.watcher = nil
+		WithDecryptor(decryptor)(c)
+		if err := c.Load(); err != nil {
+			t.Fatalf("configureImpl.Load() error = %v", err)
+		}
+
+		if got := c.GetString("client.mysql.password", ""); got != "p@ssw0rd" {
+			t.Errorf("configureImpl.GetString() = %v, want %v", got, "p@ssw0rd")
+		}
+
+		out := struct {
+			Client struct {
+				MySQL struct {
+					Password string `yaml:"password"`
+				} `yaml:"mysql"`
+			} `yaml:"client"`
+		}{}
+		if err := c.Unmarshal(&out); err != nil {
+			t.Fatalf("configureImpl.Unmarshal() error = %v", err)
+		}
+
+		if out.Client.MySQL.Password != "p@ssw0rd" {
+			t.Errorf("configureImpl.Unmarshal() = %+v", out)
+		}
+	})
+
+	t.Run("default decryptor from env", func(t *testing.T) {
+		t.Setenv(EnvDecryptKey, base64.StdEncoding.EncodeToString(key))
+
+		c := defaultConfigure(path)
+		c.watcher = nil
+		if err := c.Load(); err != nil {
+			t.Fatalf("configureImpl.Load() error = %v", err)
+		}
+
+		if got := c.GetString("client.mysql.password", ""); got != "p@ssw0rd" {
+			t.Errorf("configureImpl.GetString() = %v, want %v", got, "p@ssw0rd")
+		}
+	})
+
+	t.Run("default decryptor resolved once", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.yaml")
+		content := fmt.Sprintf("client:\n  mysql:\n    user: %s\n    password: %s\n", encrypted, encrypted)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write file error = %v", err)
+		}
+
+		calls := 0
+		patches := gomonkey.ApplyFunc(getDefaultDecryptor, func() (Decryptor, error) {
+			calls++
+			return decryptor, nil
+		})
+		defer patches.Reset()
+
+		c := defaultConfigure(path)
+		c.watcher = nil
+		if err := c.Load(); err != nil {
+			t.Fatalf("configureImpl.Load() error = %v", err)
+		}
+
+		if calls != 1 {
+			t.Errorf("getDefaultDecryptor() calls = %v, want 1", calls)
+		}
+	})
+
+	t.Run("decryptor not exist", func(t *testing.T) {
+		os.Unsetenv(EnvDecryptKey)
+		os.Unsetenv(EnvDecryptKeyFile)
+
+		c := defaultConfigure(path)
+		c.watcher = nil
+		if err := c.Load(); err == nil {
+			t.Errorf("configureImpl.Load() error = nil, wantErr")
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		wrong, _ := NewAESGCMDecryptor([]byte("fedcba9876543210"))
+
+		c := defaultConfigure(path)
+		c.watcher = nil
+		WithDecryptor(wrong)(c)
+		if err := c.Load(); err == nil {
+			t.Errorf("configureImpl.Load() error = nil, wantErr")
+		}
+	})
+
+	t.Run("mask secret in logs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		log.SetOutput(buf)
+		defer log.SetOutput(os.Stderr)
+
+		c := defaultConfigure(path)
+		c.watcher = nil
+		WithDecryptor(decryptor)(c)
+		if err := c.Load(); err != nil {
+			t.Fatalf("configureImpl.Load() error = %v", err)
+		}
+
+		WithValidator(func(c Configure) error {
+			return fmt.Errorf("invalid password %s", c.GetString("client.mysql.password", ""))
+		})(c)
+		if err := c.Reload(); err == nil {
+			t.Fatalf("configureImpl.Reload() error = nil, wantErr")
+		}
+
+		if strings.Contains(buf.String(), "p@ssw0rd") {
+			t.Errorf("log contains plaintext: %s", buf.String())
+		}
+
+		if !strings.Contains(buf.String(), secretMask) {
+			t.Errorf("log not masked: %s", buf.String())
+		}
+	})
+}
+
+func Test_configureImpl_mask(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		s       string
+		want    string
+	}{
+		{
+			name:    "value",
+			secrets: []string{"p@ssw0rd"},
+			s:       "dsn: root:p@ssw0rd@tcp(127.0.0.1:3306)/db",
+			want:    "dsn: root:******@tcp(127.0.0.1:3306)/db",
+		},
+		{
+			name:    "part of word",
+			secrets: []string{"s3cret"},
+			s:       "token=xs3cret s3cret_id s3cret",
+			want:    "token=x****** ******_id ******",
+		},
+		{
+			name:    "longer secret first",
+			secrets: []string{"abcd", "abcd-efgh"},
+			s:       "key abcd-efgh, abcd",
+			want:    "key ******, ******",
+		},
+		{
+			name:    "shorter than min length",
+			secrets: []string{"abc", "a-very-long-secret"},
+			s:       "abc a-very-long-secret",
+			want:    "abc ******",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfigure("path")
+			secrets := map[string]struct{}{}
+			for _, v := range tt.secrets {
+				secrets[v] = struct{}{}
+			}
+			c.setReadSecrets(secrets)
+
+			if got := c.mask(tt.s); got != tt.want {
+				t.Errorf("configureImpl.mask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_configureImpl_secrets(t *testing.T) {
+	c := defaultConfigure("path")
+	c.setReadSecrets(map[string]struct{}{"secret-v1": {}})
+	c.applyReadSecrets()
+
+	// the secrets of the loaded config are kept until the read one applied
+	c.setReadSecrets(map[string]struct{}{"secret-v2": {}})
+	if got := c.mask("secret-v1 secret-v2"); got != "****** ******" {
+		t.Errorf("configureImpl.mask() = %v, want %v", got, "****** ******")
+	}
+
+	c.applyReadSecrets()
+	if got := c.mask("secret-v1 secret-v2"); got != "secret-v1 ******" {
+		t.Errorf("configureImpl.mask() = %v, want %v", got, "secret-v1 ******")
+	}
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+const defaultTagName = "default"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// setDefaults set the default tag value to the fields which the document
+// leaves out
+//
+// The field set in document keeps its value even if it is zero, and the
+// field has been set before unmarshal will not be overwritten. e.g.
+//
+//	type Config struct {
+//		Timeout time.Duration `yaml:"timeout" default:"1s"`
+//		Hosts   []string      `yaml:"hosts" default:"127.0.0.1,127.0.0.2"`
+//	}
+//
+// The slice default is separated by comma.
+func setDefaults(out interface{}, doc interface{}, tagName string) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+
+	return setDefaultsValue(v.Elem(), doc, tagName, "")
+}
+
+func setDefaultsValue(v reflect.Value, doc interface{}, tagName, prefix string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return setDefaultsValue(v.Elem(), doc, tagName, prefix)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return nil
+		}
+		return setDefaultsStruct(v, doc, tagName, prefix)
+	case reflect.Slice, reflect.Array:
+		items := toSlice(doc)
+		for i := 0; i < v.Len(); i++ {
+			var item interface{}
+			if i < len(items) {
+				item = items[i]
+			}
+
+			if err := setDefaultsValue(v.Index(i), item, tagName, joinKey(prefix, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func setDefaultsStruct(v reflect.Value, doc interface{}, tagName, prefix string) error {
+	m, _ := toStringMap(doc)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline := fieldKey(field, tagName)
+		if name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if inline {
+			if err := setDefaultsValue(fv, m, tagName, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := joinKey(prefix, name)
+		fieldDoc, exist := lookupKey(m, name)
+		if def, ok := field.Tag.Lookup(defaultTagName); ok && !exist && fv.CanSet() && fv.IsZero() {
+			if err := setDefault(fv, def); err != nil {
+				return fmt.Errorf("%s: set default of key %s fail. default:%s err:%w", packageName, key, def, err)
+			}
+			continue
+		}
+
+		if err := setDefaultsValue(fv, fieldDoc, tagName, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lookupKey lookup the key in document, the key is case-insensitive as the
+// json and toml decoder do
+func lookupKey(m map[string]interface{}, k string) (interface{}, bool) {
+	if val, exist := m[k]; exist {
+		return val, true
+	}
+
+	for key, val := range m {
+		if strings.EqualFold(key, k) {
+			return val, true
+		}
+	}
+
+	return nil, false
+}
+
+func toSlice(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		return val
+	case []map[string]interface{}:
+		s := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			s = append(s, item)
+		}
+		return s
+	default:
+		return nil
+	}
+}
+
+// setDefault parse the default tag value into v
+func setDefault(v reflect.Value, s string) error {
+	switch v.Type() {
+	case durationType:
+		d, err := cast.ToDurationE(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := cast.ToTimeE(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		slice := reflect.MakeSlice(v.Type(), 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setDefault(elem, part); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		v.Set(slice)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setDefault(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// structTagName return the struct tag name used by unmarshaler
+func (c *configureImpl) structTagName() string {
+	if c.unmarshaler == nil {
+		return "yaml"
+	}
+
+	// the key/value formats decode struct by yaml tag
+	switch name := c.unmarshaler.Name(); name {
+	case "ini", "dotenv", "properties":
+		return "yaml"
+	default:
+		return name
+	}
+}
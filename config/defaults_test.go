@@ -0,0 +1,156 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_configureImpl_UnmarshalDefaults(t *testing.T) {
+	c := defaultConfigure("./testdata/rich_config.yaml")
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	type redisConfig struct {
+		Timeout int  `yaml:"timeout" default:"3000"`
+		MaxIdle int  `yaml:"max_idle" default:"10"`
+		Wait    bool `yaml:"wait" default:"true"`
+	}
+
+	type serviceConfig struct {
+		Name    string        `yaml:"name"`
+		DSN     string        `yaml:"dsn"`
+		Timeout time.Duration `yaml:"timeout" default:"1s"`
+	}
+
+	type inlineConfig struct {
+		Env string `yaml:"env" default:"prod"`
+	}
+
+	out := struct {
+		Timeout time.Duration `yaml:"timeout" default:"3s"`
+		Hosts   []string      `yaml:"hosts" default:"127.0.0.3"`
+		Ports   []int         `yaml:"not_exist_ports" default:"80, 443"`
+		Ratio   *float64      `yaml:"ratio" default:"0.5"`
+		Name    string        `yaml:"name" default:"app"`
+		Client  struct {
+			Redis   redisConfig     `yaml:"redis"`
+			Service []serviceConfig `yaml:"service"`
+		} `yaml:"client"`
+		Missing redisConfig `yaml:"missing"`
+		Labels  struct {
+			inlineConfig `yaml:",inline"`
+			Region       string `yaml:"region" default:"cn"`
+		} `yaml:"labels"`
+	}{
+		Name: "preset",
+	}
+
+	if err := c.Unmarshal(&out); err != nil {
+		t.Fatalf("configureImpl.Unmarshal() error = %v", err)
+	}
+
+	if out.Timeout != 1500*time.Millisecond {
+		t.Errorf("Timeout = %v, want value in config", out.Timeout)
+	}
+
+	if !reflect.DeepEqual(out.Hosts, []string{"127.0.0.1", "127.0.0.2"}) {
+		t.Errorf("Hosts = %v, want value in config", out.Hosts)
+	}
+
+	if !reflect.DeepEqual(out.Ports, []int{80, 443}) {
+		t.Errorf("Ports = %v, want default", out.Ports)
+	}
+
+	if out.Ratio == nil || *out.Ratio != 0.5 {
+		t.Errorf("Ratio = %v, want default", out.Ratio)
+	}
+
+	if out.Name != "preset" {
+		t.Errorf("Name = %v, want preset value kept", out.Name)
+	}
+
+	if want := (redisConfig{Timeout: 1000, MaxIdle: 10, Wait: true}); out.Client.Redis != want {
+		t.Errorf("Client.Redis = %+v, want %+v", out.Client.Redis, want)
+	}
+
+	for _, v := range out.Client.Service {
+		if v.Timeout != time.Second {
+			t.Errorf("Client.Service.Timeout = %v, want default", v.Timeout)
+		}
+	}
+
+	if want := (redisConfig{Timeout: 3000, MaxIdle: 10, Wait: true}); out.Missing != want {
+		t.Errorf("Missing = %+v, want %+v", out.Missing, want)
+	}
+
+	if out.Labels.Env != "test" || out.Labels.Region != "cn" {
+		t.Errorf("Labels = %+v, want env in config and default region", out.Labels)
+	}
+
+	var service serviceConfig
+	if err := c.UnmarshalKey("client.service.0", &service); err != nil || service.Timeout != time.Second {
+		t.Errorf("configureImpl.UnmarshalKey() = %+v, error = %v", service, err)
+	}
+}
+
+func Test_setDefaults(t *testing.T) {
+	type config struct {
+		Timeout int `yaml:"timeout" default:"1000"`
+	}
+
+	tests := []struct {
+		name    string
+		out     interface{}
+		doc     interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "key exist with zero value",
+			out:  &config{},
+			doc:  map[string]interface{}{"timeout": 0},
+			want: &config{},
+		},
+		{
+			name: "key not exist",
+			out:  &config{},
+			doc:  map[string]interface{}{},
+			want: &config{Timeout: 1000},
+		},
+		{
+			name: "case insensitive key",
+			out:  &config{},
+			doc:  map[string]interface{}{"Timeout": 0},
+			want: &config{},
+		},
+		{
+			name: "toml array of tables",
+			out:  &[]config{{}, {}},
+			doc:  []map[string]interface{}{{"timeout": 0}, {}},
+			want: &[]config{{}, {Timeout: 1000}},
+		},
+		{
+			name: "invalid default",
+			out: &struct {
+				Timeout int `yaml:"timeout" default:"1s"`
+			}{},
+			doc:     map[string]interface{}{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := setDefaults(tt.out, tt.doc, "yaml")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(tt.out, tt.want) {
+				t.Errorf("setDefaults() = %+v, want %+v", tt.out, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,52 @@
 package config
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrUnmarshalerNotExist unmarshaler not exist
 	ErrUnmarshalerNotExist = fmt.Errorf("%s: unmarshaler not exist", packageName)
 
+	// ErrSourceNotExist source not exist
+	ErrSourceNotExist = fmt.Errorf("%s: source not exist", packageName)
+
 	// ErrConfigNotExist config not exist
 	ErrConfigNotExist = fmt.Errorf("%s: config not exist", packageName)
+
+	// ErrLoadOptionConflict the config has been loaded with a different
+	// decryptor
+	ErrLoadOptionConflict = fmt.Errorf("%s: config has been loaded with a different decryptor", packageName)
 )
+
+// ErrTypeMismatch the value of key can not cast to the wanted type
+type ErrTypeMismatch struct {
+	// Key the config key, e.g. client.redis.timeout
+	Key string
+
+	// Type the actual type of value, e.g. string
+	Type string
+
+	// Want the wanted type, e.g. int
+	Want string
+
+	// Err the cast error
+	Err error
+}
+
+// Error ...
+func (e *ErrTypeMismatch) Error() string {
+	return fmt.Sprintf("%s: key %s type mismatch, can not cast %s to %s. err:%v", packageName, e.Key, e.Type, e.Want, e.Err)
+}
+
+// Unwrap return the cast error
+func (e *ErrTypeMismatch) Unwrap() error {
+	return e.Err
+}
+
+// IsTypeMismatch is type mismatch error
+func IsTypeMismatch(err error) bool {
+	var target *ErrTypeMismatch
+	return errors.As(err, &target)
+}
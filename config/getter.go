@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// getE get value by key and cast it by castFn
+//
+// return ErrConfigNotExist when key not exist, and *ErrTypeMismatch when
+// cast fail.
+func (c *configureImpl) getE(k, want string, castFn func(interface{}) (interface{}, error)) (interface{}, error) {
+	val, err := c.get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := castFn(val)
+	if err != nil {
+		return nil, &ErrTypeMismatch{
+			Key:  k,
+			Type: fmt.Sprintf("%T", val),
+			Want: want,
+			Err:  err,
+		}
+	}
+
+	return ret, nil
+}
+
+// GetStringE get string value by key
+func (c *configureImpl) GetStringE(k string) (string, error) {
+	val, err := c.getE(k, "string", func(v interface{}) (interface{}, error) {
+		return cast.ToStringE(v)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return val.(string), nil
+}
+
+// GetBoolE get bool value by key
+func (c *configureImpl) GetBoolE(k string) (bool, error) {
+	val, err := c.getE(k, "bool", func(v interface{}) (interface{}, error) {
+		return cast.ToBoolE(v)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return val.(bool), nil
+}
+
+// GetIntE get int value by key
+func (c *configureImpl) GetIntE(k string) (int, error) {
+	val, err := c.getE(k, "int", func(v interface{}) (interface{}, error) {
+		return cast.ToIntE(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(int), nil
+}
+
+// GetInt32E get int32 value by key
+func (c *configureImpl) GetInt32E(k string) (int32, error) {
+	val, err := c.getE(k, "int32", func(v interface{}) (interface{}, error) {
+		return cast.ToInt32E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(int32), nil
+}
+
+// GetInt64E get int64 value by key
+func (c *configureImpl) GetInt64E(k string) (int64, error) {
+	val, err := c.getE(k, "int64", func(v interface{}) (interface{}, error) {
+		return cast.ToInt64E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(int64), nil
+}
+
+// GetUintE get uint value by key
+func (c *configureImpl) GetUintE(k string) (uint, error) {
+	val, err := c.getE(k, "uint", func(v interface{}) (interface{}, error) {
+		return cast.ToUintE(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(uint), nil
+}
+
+// GetUint32E get uint32 value by key
+func (c *configureImpl) GetUint32E(k string) (uint32, error) {
+	val, err := c.getE(k, "uint32", func(v interface{}) (interface{}, error) {
+		return cast.ToUint32E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(uint32), nil
+}
+
+// GetUint64E get uint64 value by key
+func (c *configureImpl) GetUint64E(k string) (uint64, error) {
+	val, err := c.getE(k, "uint64", func(v interface{}) (interface{}, error) {
+		return cast.ToUint64E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(uint64), nil
+}
+
+// GetFloat32E get float32 value by key
+func (c *configureImpl) GetFloat32E(k string) (float32, error) {
+	val, err := c.getE(k, "float32", func(v interface{}) (interface{}, error) {
+		return cast.ToFloat32E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(float32), nil
+}
+
+// GetFloat64E get float64 value by key
+func (c *configureImpl) GetFloat64E(k string) (float64, error) {
+	val, err := c.getE(k, "float64", func(v interface{}) (interface{}, error) {
+		return cast.ToFloat64E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(float64), nil
+}
+
+// GetDurationE get time.Duration value by key
+func (c *configureImpl) GetDurationE(k string) (time.Duration, error) {
+	val, err := c.getE(k, "time.Duration", func(v interface{}) (interface{}, error) {
+		return cast.ToDurationE(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return val.(time.Duration), nil
+}
+
+// GetTimeE get time.Time value by key
+func (c *configureImpl) GetTimeE(k string) (time.Time, error) {
+	val, err := c.getE(k, "time.Time", func(v interface{}) (interface{}, error) {
+		return cast.ToTimeE(v)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return val.(time.Time), nil
+}
+
+// GetStringSliceE get []string value by key
+func (c *configureImpl) GetStringSliceE(k string) ([]string, error) {
+	val, err := c.getE(k, "[]string", func(v interface{}) (interface{}, error) {
+		return cast.ToStringSliceE(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val.([]string), nil
+}
+
+// GetIntSliceE get []int value by key
+func (c *configureImpl) GetIntSliceE(k string) ([]int, error) {
+	val, err := c.getE(k, "[]int", func(v interface{}) (interface{}, error) {
+		return cast.ToIntSliceE(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val.([]int), nil
+}
+
+// GetStringMapE get map[string]interface{} value by key
+func (c *configureImpl) GetStringMapE(k string) (map[string]interface{}, error) {
+	val, err := c.getE(k, "map[string]interface{}", func(v interface{}) (interface{}, error) {
+		return cast.ToStringMapE(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val.(map[string]interface{}), nil
+}
+
+// GetStringMapStringE get map[string]string value by key
+func (c *configureImpl) GetStringMapStringE(k string) (map[string]string, error) {
+	val, err := c.getE(k, "map[string]string", func(v interface{}) (interface{}, error) {
+		return cast.ToStringMapStringE(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val.(map[string]string), nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_configureImpl_GetE(t *testing.T) {
+	c := defaultConfigure("./testdata/rich_config.yaml")
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		get      func() (interface{}, error)
+		want     interface{}
+		wantErr  error
+		wantType string
+	}{
+		{
+			name: "int",
+			get:  func() (interface{}, error) { return c.GetIntE("client.redis.timeout") },
+			want: 1000,
+		},
+		{
+			name: "duration",
+			get:  func() (interface{}, error) { return c.GetDurationE("timeout") },
+			want: 1500 * time.Millisecond,
+		},
+		{
+			name: "string slice",
+			get:  func() (interface{}, error) { return c.GetStringSliceE("hosts") },
+			want: []string{"127.0.0.1", "127.0.0.2"},
+		},
+		{
+			name: "string map string",
+			get:  func() (interface{}, error) { return c.GetStringMapStringE("labels") },
+			want: map[string]string{"env": "test", "zone": "a"},
+		},
+		{
+			name:    "not exist",
+			get:     func() (interface{}, error) { return c.GetIntE("not exist key") },
+			want:    0,
+			wantErr: ErrConfigNotExist,
+		},
+		{
+			name:     "type mismatch",
+			get:      func() (interface{}, error) { return c.GetIntE("labels.env") },
+			want:     0,
+			wantType: "string",
+		},
+		{
+			name:     "slice type mismatch",
+			get:      func() (interface{}, error) { return c.GetIntSliceE("hosts") },
+			want:     []int(nil),
+			wantType: "[]interface {}",
+		},
+		{
+			name:     "duration type mismatch",
+			get:      func() (interface{}, error) { return c.GetDurationE("labels") },
+			want:     time.Duration(0),
+			wantType: "map[string]interface {}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configureImpl.GetE() = %#v, want %#v", got, tt.want)
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("configureImpl.GetE() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantType == "" {
+				if tt.wantErr == nil && err != nil {
+					t.Errorf("configureImpl.GetE() error = %v", err)
+				}
+				return
+			}
+
+			var mismatch *ErrTypeMismatch
+			if !errors.As(err, &mismatch) || !IsTypeMismatch(err) {
+				t.Fatalf("configureImpl.GetE() error = %v, want *ErrTypeMismatch", err)
+			}
+
+			if mismatch.Type != tt.wantType || mismatch.Key == "" || mismatch.Err == nil {
+				t.Errorf("configureImpl.GetE() error = %+v, want type %s", mismatch, tt.wantType)
+			}
+		})
+	}
+}
+
+func Test_subConfigure_GetE(t *testing.T) {
+	c := defaultConfigure("./testdata/rich_config.yaml")
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	got, err := c.Sub("client").GetIntE("redis.timeout")
+	if err != nil || got != 1000 {
+		t.Errorf("subConfigure.GetIntE() = %v, error = %v", got, err)
+	}
+
+	_, err = c.Sub("labels").GetBoolE("env")
+	var mismatch *ErrTypeMismatch
+	if !errors.As(err, &mismatch) || mismatch.Key != "labels.env" {
+		t.Errorf("subConfigure.GetBoolE() error = %v, want mismatch of labels.env", err)
+	}
+}
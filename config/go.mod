@@ -7,7 +7,10 @@ require (
 	github.com/agiledragon/gomonkey v2.0.2+incompatible
 	github.com/fsnotify/fsnotify v1.5.1
 	github.com/golang/mock v1.6.0
+	github.com/hashicorp/hcl v1.0.0
+	github.com/magiconair/properties v1.8.7
 	github.com/spf13/cast v1.4.1
+	gopkg.in/ini.v1 v1.67.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
 
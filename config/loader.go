@@ -2,27 +2,155 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+
+	"github.com/wwwangxc/gopkg/config/unmarshaler"
 )
 
 var (
-	defaultLoader = newLoader()
+	defaultLoader = NewLoader()
 )
 
-type loader struct {
+// Loader config loader
+//
+// Each loader has its own cache, the config with the same path, unmarshaler
+// and layers will be loaded and watched once. The watch callbacks, reload
+// error callbacks and validators given by the later loads are added to the
+// cached config, and the later load with a different decryptor returns
+// ErrLoadOptionConflict. Use different loaders to isolate configs, e.g. in
+// tests or multi-tenant binaries.
+type Loader struct {
 	m  map[string]Configure
 	rw sync.RWMutex
+
+	baseDir     string
+	searchPaths []string
+	unmarshaler string
 }
 
-func newLoader() *loader {
-	return &loader{
+// NewLoader new config loader
+func NewLoader(opts ...LoaderOption) *Loader {
+	l := &Loader{
 		m: map[string]Configure{},
 	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
 }
 
 // Load load and cache config
-func (l *loader) Load(path string, opts ...LoadOption) (Configure, error) {
+//
+// The relative path will be resolved by the base directory and the search
+// paths of loader.
+func (l *Loader) Load(path string, opts ...LoadOption) (Configure, error) {
+	path = l.resolve(path)
 	c := defaultConfigure(path)
+	if l.unmarshaler != "" && unmarshaler.GetByExtension(filepath.Ext(path)) == nil {
+		c.unmarshaler = unmarshaler.Get(l.unmarshaler)
+	}
+
+	return l.load(path, c, opts...)
+}
+
+// LoadSource load and cache config from source
+func (l *Loader) LoadSource(source Source, opts ...LoadOption) (Configure, error) {
+	if source == nil {
+		return nil, ErrSourceNotExist
+	}
+
+	c := sourceConfigure(source)
+	if l.unmarshaler != "" {
+		c.unmarshaler = unmarshaler.Get(l.unmarshaler)
+	}
+
+	return l.load(source.Name(), c, opts...)
+}
+
+// Invalidate evict the cached configs of path or source name
+//
+// The evicted configs will be closed and stop watching, the next Load will
+// load the config again.
+func (l *Loader) Invalidate(name string) {
+	prefix := l.resolve(name) + ":"
+
+	var configs []Configure
+	l.rw.RLock()
+	for k, c := range l.m {
+		if strings.HasPrefix(k, prefix) || strings.HasPrefix(k, name+":") {
+			configs = append(configs, c)
+		}
+	}
+	l.rw.RUnlock()
+
+	for _, c := range configs {
+		if err := c.Close(); err != nil {
+			logErrorf("%s: close config fail. err:%v\n", packageName, err)
+		}
+	}
+}
+
+// Close close all the cached configs and reset the cache
+func (l *Loader) Close() error {
+	l.rw.RLock()
+	configs := make([]Configure, 0, len(l.m))
+	for _, c := range l.m {
+		configs = append(configs, c)
+	}
+	l.rw.RUnlock()
+
+	var errs []string
+	for _, c := range configs {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	l.rw.Lock()
+	l.m = map[string]Configure{}
+	l.rw.Unlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: close loader fail. err:%s", packageName, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// resolve resolve the relative path by the base directory and search paths
+//
+// Return the first existing file in the search paths, or the path joined
+// with base directory when not found.
+func (l *Loader) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	for _, dir := range l.searchPaths {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(l.baseDir, dir)
+		}
+
+		candidate := filepath.Join(dir, path)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	if l.baseDir == "" {
+		return path
+	}
+
+	return filepath.Join(l.baseDir, path)
+}
+
+func (l *Loader) load(name string, c *configureImpl, opts ...LoadOption) (Configure, error) {
 	for _, opt := range opts {
 		opt(c)
 	}
@@ -31,27 +159,73 @@ func (l *loader) Load(path string, opts ...LoadOption) (Configure, error) {
 		return nil, ErrUnmarshalerNotExist
 	}
 
-	key := fmt.Sprintf("%s:%s", path, c.unmarshaler.Name())
+	key := fmt.Sprintf("%s:%s", name, c.unmarshaler.Name())
+	for _, layer := range c.layers {
+		key = fmt.Sprintf("%s|%s", key, layer.Name())
+	}
+
 	l.rw.RLock()
 	tmp, exist := l.m[key]
 	l.rw.RUnlock()
 	if exist {
-		return tmp, nil
+		return cachedConfigure(tmp, c)
+	}
+
+	// load and watch under the lock, so the concurrent loads of the key
+	// share one config and one watcher
+	l.rw.Lock()
+	defer l.rw.Unlock()
+
+	if tmp, exist = l.m[key]; exist {
+		return cachedConfigure(tmp, c)
 	}
 
 	if err := c.Load(); err != nil {
 		return nil, fmt.Errorf("%s: config load fail. err:%w", packageName, err)
 	}
 
-	l.rw.Lock()
 	l.m[key] = c
-	l.rw.Unlock()
-
-	c.watch(func(c *configureImpl) {
+	c.onClose = func() {
 		l.rw.Lock()
 		defer l.rw.Unlock()
-		l.m[key] = c
-	})
+		if l.m[key] == Configure(c) {
+			delete(l.m, key)
+		}
+	}
+
+	c.watch()
 
 	return c, nil
 }
+
+// cachedConfigure add the callbacks and validators of the load to the
+// cached config
+//
+// Return ErrLoadOptionConflict when the decryptor differs from the cached
+// one, or the validate error when the cached config is invalid for the
+// added validators.
+func cachedConfigure(cached Configure, c *configureImpl) (Configure, error) {
+	impl, ok := cached.(*configureImpl)
+	if !ok {
+		return cached, nil
+	}
+
+	if c.decryptor != nil && !sameDecryptor(impl.decryptor, c.decryptor) {
+		return nil, ErrLoadOptionConflict
+	}
+
+	if err := impl.attach(c); err != nil {
+		return nil, fmt.Errorf("%s: config load fail. err:%w", packageName, err)
+	}
+
+	return cached, nil
+}
+
+func sameDecryptor(a, b Decryptor) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || ta == nil || !ta.Comparable() {
+		return false
+	}
+
+	return a == b
+}
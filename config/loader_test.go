@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey"
 )
@@ -18,7 +23,7 @@ func Test_loader_Load(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		l       *loader
+		l       *Loader
 		args    args
 		want    Configure
 		wantErr bool
@@ -26,7 +31,7 @@ func Test_loader_Load(t *testing.T) {
 	}{
 		{
 			name: "unmarshaler not exist",
-			l:    newLoader(),
+			l:    NewLoader(),
 			args: args{
 				opts: []LoadOption{
 					WithUnmarshaler("not exist unmarshaler"),
@@ -38,7 +43,7 @@ func Test_loader_Load(t *testing.T) {
 		},
 		{
 			name: "hit cache",
-			l: &loader{
+			l: &Loader{
 				m: map[string]Configure{
 					"path:yaml": defaultConfig,
 				},
@@ -52,9 +57,26 @@ func Test_loader_Load(t *testing.T) {
 			want:    defaultConfig,
 			wantErr: false,
 		},
+		{
+			name: "cached config invalid for validator",
+			l: &Loader{
+				m: map[string]Configure{
+					"path:yaml": defaultConfig,
+				},
+			},
+			args: args{
+				path: "path",
+				opts: []LoadOption{
+					WithRequiredKeys("key"),
+					withTest(),
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "load fail",
-			l:    newLoader(),
+			l:    NewLoader(),
 			args: args{
 				path: "path",
 				opts: []LoadOption{
@@ -67,7 +89,7 @@ func Test_loader_Load(t *testing.T) {
 		},
 		{
 			name: "normal",
-			l:    newLoader(),
+			l:    NewLoader(),
 			args: args{
 				path: "path",
 				opts: []LoadOption{
@@ -92,6 +114,12 @@ func Test_loader_Load(t *testing.T) {
 				t.Errorf("loader.Load() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if impl, ok := got.(*configureImpl); ok && impl != defaultConfig {
+				if impl.onClose == nil {
+					t.Errorf("loader.Load() onClose not set")
+				}
+				impl.onClose = nil
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("loader.Load() = %v, want %v", got, tt.want)
 			}
@@ -104,3 +132,275 @@ func Test_loader_Load(t *testing.T) {
 		})
 	}
 }
+
+type testSource struct {
+	data   map[string]interface{}
+	notify func()
+}
+
+func (t *testSource) Name() string {
+	return "test"
+}
+
+func (t *testSource) Read() (map[string]interface{}, error) {
+	return t.data, nil
+}
+
+func (t *testSource) Watch(notify func()) error {
+	t.notify = notify
+	return nil
+}
+
+func (t *testSource) Close() error {
+	return nil
+}
+
+func Test_loader_LoadSource(t *testing.T) {
+	l := NewLoader()
+	if _, err := l.LoadSource(nil); err == nil {
+		t.Errorf("loader.LoadSource() error = nil, want error")
+	}
+
+	source := &testSource{
+		data: map[string]interface{}{
+			"key": "value",
+		},
+	}
+
+	watched := make(chan Configure, 1)
+	c, err := l.LoadSource(source, WithWatchCallback(func(c Configure) {
+		watched <- c
+	}))
+	if err != nil {
+		t.Fatalf("loader.LoadSource() error = %v", err)
+	}
+
+	if got := c.GetString("key", ""); got != "value" {
+		t.Errorf("configure.GetString() = %v, want %v", got, "value")
+	}
+
+	out := map[string]interface{}{}
+	if err := c.Unmarshal(&out); err != nil || out["key"] != "value" {
+		t.Errorf("configure.Unmarshal() = %v, error = %v", out, err)
+	}
+
+	if source.notify == nil {
+		t.Fatalf("source not watched")
+	}
+
+	source.data = map[string]interface{}{
+		"key": "new value",
+	}
+	source.notify()
+
+	if got := (<-watched).GetString("key", ""); got != "new value" {
+		t.Errorf("configure.GetString() = %v, want %v", got, "new value")
+	}
+}
+
+func Test_Loader_load_attach(t *testing.T) {
+	l := NewLoader()
+	defer l.Close()
+
+	source := &testSource{
+		data: map[string]interface{}{"key": "value"},
+	}
+
+	c, err := l.LoadSource(source)
+	if err != nil {
+		t.Fatalf("loader.LoadSource() error = %v", err)
+	}
+
+	watched := make(chan Configure, 1)
+	got, err := l.LoadSource(source, WithRequiredKeys("key"), WithWatchCallback(func(c Configure) {
+		watched <- c
+	}))
+	if err != nil || got != c {
+		t.Fatalf("loader.LoadSource() = %v, error = %v, want cached", got, err)
+	}
+
+	// the later watch callback is called
+	source.notify()
+	if <-watched != c {
+		t.Errorf("watch callback not called with cached config")
+	}
+
+	// invalid for the added validator
+	if _, err = l.LoadSource(source, WithRequiredKeys("not_exist")); err == nil {
+		t.Errorf("loader.LoadSource() error = nil, want validate error")
+	}
+
+	// different decryptor
+	if _, err = l.LoadSource(source, WithDecryptor(&aesGCMDecryptor{})); !errors.Is(err, ErrLoadOptionConflict) {
+		t.Errorf("loader.LoadSource() error = %v, want %v", err, ErrLoadOptionConflict)
+	}
+}
+
+// countSource count the watches of source
+type countSource struct {
+	testSource
+	mu      sync.Mutex
+	watches int
+}
+
+// Read slow down the load, so the loads are concurrent
+func (c *countSource) Read() (map[string]interface{}, error) {
+	time.Sleep(10 * time.Millisecond)
+	return c.data, nil
+}
+
+func (c *countSource) Watch(notify func()) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watches++
+	return nil
+}
+
+func Test_Loader_load_concurrent(t *testing.T) {
+	l := NewLoader()
+	defer l.Close()
+
+	source := &countSource{
+		testSource: testSource{
+			data: map[string]interface{}{"key": "value"},
+		},
+	}
+
+	var wg sync.WaitGroup
+	configs := make([]Configure, 10)
+	for i := range configs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := l.LoadSource(source)
+			if err != nil {
+				t.Errorf("loader.LoadSource() error = %v", err)
+			}
+			configs[i] = c
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range configs {
+		if c != configs[0] {
+			t.Errorf("loader.LoadSource() = %p, want %p", c, configs[0])
+		}
+	}
+
+	if source.watches != 1 {
+		t.Errorf("source watches = %v, want 1", source.watches)
+	}
+}
+
+func Test_Loader_resolve(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"conf", "etc"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("mkdir error = %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "etc", "app.yaml"), "key: etc")
+	writeFile(t, filepath.Join(dir, "etc", "other.yaml"), "key: etc")
+	writeFile(t, filepath.Join(dir, "conf", "app.yaml"), "key: conf")
+
+	tests := []struct {
+		name   string
+		loader *Loader
+		path   string
+		want   string
+	}{
+		{
+			name:   "absolute path",
+			loader: NewLoader(WithBaseDir(dir)),
+			path:   "/tmp/app.yaml",
+			want:   "/tmp/app.yaml",
+		},
+		{
+			name:   "base dir",
+			loader: NewLoader(WithBaseDir(dir)),
+			path:   "app.yaml",
+			want:   filepath.Join(dir, "app.yaml"),
+		},
+		{
+			name:   "first existing in search paths",
+			loader: NewLoader(WithBaseDir(dir), WithSearchPaths("./", "./conf", filepath.Join(dir, "etc"))),
+			path:   "app.yaml",
+			want:   filepath.Join(dir, "conf", "app.yaml"),
+		},
+		{
+			name:   "absolute search path",
+			loader: NewLoader(WithBaseDir(dir), WithSearchPaths("./", "./conf", filepath.Join(dir, "etc"))),
+			path:   "other.yaml",
+			want:   filepath.Join(dir, "etc", "other.yaml"),
+		},
+		{
+			name:   "not found",
+			loader: NewLoader(WithSearchPaths("./conf")),
+			path:   "app.yaml",
+			want:   "app.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loader.resolve(tt.path); got != tt.want {
+				t.Errorf("Loader.resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Loader_Invalidate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app.conf"), `{"key": "value"}`)
+
+	l := NewLoader(WithBaseDir(dir), WithDefaultUnmarshaler("json"))
+	c, err := l.Load("app.conf")
+	if err != nil {
+		t.Fatalf("Loader.Load() error = %v", err)
+	}
+
+	if got := c.GetString("key", ""); got != "value" {
+		t.Errorf("configure.GetString() = %v, want %v", got, "value")
+	}
+
+	// hit cache
+	if cached, _ := l.Load("app.conf"); cached != c {
+		t.Errorf("Loader.Load() not cached")
+	}
+
+	// isolated from other loaders
+	if other, _ := NewLoader(WithBaseDir(dir), WithDefaultUnmarshaler("json")).Load("app.conf"); other == c {
+		t.Errorf("Loader.Load() not isolated")
+	}
+
+	l.Invalidate("app.conf")
+	if len(l.m) != 0 {
+		t.Errorf("Loader.m = %v, want empty", l.m)
+	}
+
+	reloaded, err := l.Load("app.conf")
+	if err != nil {
+		t.Fatalf("Loader.Load() error = %v", err)
+	}
+
+	if reloaded == c {
+		t.Errorf("Loader.Load() return the invalidated config")
+	}
+
+	// the reload of the invalidated config does not put it back to cache
+	if err = c.(*configureImpl).Reload(); err != nil {
+		t.Fatalf("configure.Reload() error = %v", err)
+	}
+
+	if cached, _ := l.Load("app.conf"); cached != reloaded {
+		t.Errorf("Loader.Load() return the invalidated config after reload")
+	}
+
+	if err = l.Close(); err != nil {
+		t.Fatalf("Loader.Close() error = %v", err)
+	}
+
+	if len(l.m) != 0 {
+		t.Errorf("Loader.m = %v, want empty", l.m)
+	}
+}
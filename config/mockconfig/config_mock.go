@@ -6,7 +6,9 @@ package mockconfig
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	config "github.com/wwwangxc/gopkg/config"
 	reflect "reflect"
+	time "time"
 )
 
 // MockConfigure is a mock of Configure interface
@@ -213,3 +215,396 @@ func (mr *MockConfigureMockRecorder) GetFloat64(arg0, arg1 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFloat64", reflect.TypeOf((*MockConfigure)(nil).GetFloat64), arg0, arg1)
 }
+
+// GetDuration mocks base method
+func (m *MockConfigure) GetDuration(arg0 string, arg1 time.Duration) time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDuration", arg0, arg1)
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// GetDuration indicates an expected call of GetDuration
+func (mr *MockConfigureMockRecorder) GetDuration(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDuration", reflect.TypeOf((*MockConfigure)(nil).GetDuration), arg0, arg1)
+}
+
+// GetTime mocks base method
+func (m *MockConfigure) GetTime(arg0 string, arg1 time.Time) time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTime", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// GetTime indicates an expected call of GetTime
+func (mr *MockConfigureMockRecorder) GetTime(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTime", reflect.TypeOf((*MockConfigure)(nil).GetTime), arg0, arg1)
+}
+
+// GetStringSlice mocks base method
+func (m *MockConfigure) GetStringSlice(arg0 string, arg1 []string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringSlice", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetStringSlice indicates an expected call of GetStringSlice
+func (mr *MockConfigureMockRecorder) GetStringSlice(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringSlice", reflect.TypeOf((*MockConfigure)(nil).GetStringSlice), arg0, arg1)
+}
+
+// GetIntSlice mocks base method
+func (m *MockConfigure) GetIntSlice(arg0 string, arg1 []int) []int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIntSlice", arg0, arg1)
+	ret0, _ := ret[0].([]int)
+	return ret0
+}
+
+// GetIntSlice indicates an expected call of GetIntSlice
+func (mr *MockConfigureMockRecorder) GetIntSlice(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIntSlice", reflect.TypeOf((*MockConfigure)(nil).GetIntSlice), arg0, arg1)
+}
+
+// GetStringMap mocks base method
+func (m *MockConfigure) GetStringMap(arg0 string, arg1 map[string]interface{}) map[string]interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringMap", arg0, arg1)
+	ret0, _ := ret[0].(map[string]interface{})
+	return ret0
+}
+
+// GetStringMap indicates an expected call of GetStringMap
+func (mr *MockConfigureMockRecorder) GetStringMap(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringMap", reflect.TypeOf((*MockConfigure)(nil).GetStringMap), arg0, arg1)
+}
+
+// GetStringMapString mocks base method
+func (m *MockConfigure) GetStringMapString(arg0 string, arg1 map[string]string) map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringMapString", arg0, arg1)
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// GetStringMapString indicates an expected call of GetStringMapString
+func (mr *MockConfigureMockRecorder) GetStringMapString(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringMapString", reflect.TypeOf((*MockConfigure)(nil).GetStringMapString), arg0, arg1)
+}
+
+// UnmarshalKey mocks base method
+func (m *MockConfigure) UnmarshalKey(arg0 string, arg1 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarshalKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmarshalKey indicates an expected call of UnmarshalKey
+func (mr *MockConfigureMockRecorder) UnmarshalKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarshalKey", reflect.TypeOf((*MockConfigure)(nil).UnmarshalKey), arg0, arg1)
+}
+
+// Sub mocks base method
+func (m *MockConfigure) Sub(arg0 string) config.Configure {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sub", arg0)
+	ret0, _ := ret[0].(config.Configure)
+	return ret0
+}
+
+// Sub indicates an expected call of Sub
+func (mr *MockConfigureMockRecorder) Sub(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sub", reflect.TypeOf((*MockConfigure)(nil).Sub), arg0)
+}
+
+// OnChange mocks base method
+func (m *MockConfigure) OnChange(arg0 string, arg1 func(interface{}, interface{})) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnChange", arg0, arg1)
+}
+
+// OnChange indicates an expected call of OnChange
+func (mr *MockConfigureMockRecorder) OnChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnChange", reflect.TypeOf((*MockConfigure)(nil).OnChange), arg0, arg1)
+}
+
+// Bind mocks base method
+func (m *MockConfigure) Bind(arg0 string, arg1 interface{}) (*config.Binding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bind", arg0, arg1)
+	ret0, _ := ret[0].(*config.Binding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Bind indicates an expected call of Bind
+func (mr *MockConfigureMockRecorder) Bind(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bind", reflect.TypeOf((*MockConfigure)(nil).Bind), arg0, arg1)
+}
+
+// Close mocks base method
+func (m *MockConfigure) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockConfigureMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockConfigure)(nil).Close))
+}
+
+// GetStringE mocks base method
+func (m *MockConfigure) GetStringE(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringE", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStringE indicates an expected call of GetStringE
+func (mr *MockConfigureMockRecorder) GetStringE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringE", reflect.TypeOf((*MockConfigure)(nil).GetStringE), arg0)
+}
+
+// GetBoolE mocks base method
+func (m *MockConfigure) GetBoolE(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoolE", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoolE indicates an expected call of GetBoolE
+func (mr *MockConfigureMockRecorder) GetBoolE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoolE", reflect.TypeOf((*MockConfigure)(nil).GetBoolE), arg0)
+}
+
+// GetIntE mocks base method
+func (m *MockConfigure) GetIntE(arg0 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIntE", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIntE indicates an expected call of GetIntE
+func (mr *MockConfigureMockRecorder) GetIntE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIntE", reflect.TypeOf((*MockConfigure)(nil).GetIntE), arg0)
+}
+
+// GetInt32E mocks base method
+func (m *MockConfigure) GetInt32E(arg0 string) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInt32E", arg0)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInt32E indicates an expected call of GetInt32E
+func (mr *MockConfigureMockRecorder) GetInt32E(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInt32E", reflect.TypeOf((*MockConfigure)(nil).GetInt32E), arg0)
+}
+
+// GetInt64E mocks base method
+func (m *MockConfigure) GetInt64E(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInt64E", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInt64E indicates an expected call of GetInt64E
+func (mr *MockConfigureMockRecorder) GetInt64E(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInt64E", reflect.TypeOf((*MockConfigure)(nil).GetInt64E), arg0)
+}
+
+// GetUintE mocks base method
+func (m *MockConfigure) GetUintE(arg0 string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUintE", arg0)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUintE indicates an expected call of GetUintE
+func (mr *MockConfigureMockRecorder) GetUintE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUintE", reflect.TypeOf((*MockConfigure)(nil).GetUintE), arg0)
+}
+
+// GetUint32E mocks base method
+func (m *MockConfigure) GetUint32E(arg0 string) (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUint32E", arg0)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUint32E indicates an expected call of GetUint32E
+func (mr *MockConfigureMockRecorder) GetUint32E(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUint32E", reflect.TypeOf((*MockConfigure)(nil).GetUint32E), arg0)
+}
+
+// GetUint64E mocks base method
+func (m *MockConfigure) GetUint64E(arg0 string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUint64E", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUint64E indicates an expected call of GetUint64E
+func (mr *MockConfigureMockRecorder) GetUint64E(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUint64E", reflect.TypeOf((*MockConfigure)(nil).GetUint64E), arg0)
+}
+
+// GetFloat32E mocks base method
+func (m *MockConfigure) GetFloat32E(arg0 string) (float32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFloat32E", arg0)
+	ret0, _ := ret[0].(float32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFloat32E indicates an expected call of GetFloat32E
+func (mr *MockConfigureMockRecorder) GetFloat32E(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFloat32E", reflect.TypeOf((*MockConfigure)(nil).GetFloat32E), arg0)
+}
+
+// GetFloat64E mocks base method
+func (m *MockConfigure) GetFloat64E(arg0 string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFloat64E", arg0)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFloat64E indicates an expected call of GetFloat64E
+func (mr *MockConfigureMockRecorder) GetFloat64E(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFloat64E", reflect.TypeOf((*MockConfigure)(nil).GetFloat64E), arg0)
+}
+
+// GetDurationE mocks base method
+func (m *MockConfigure) GetDurationE(arg0 string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDurationE", arg0)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDurationE indicates an expected call of GetDurationE
+func (mr *MockConfigureMockRecorder) GetDurationE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDurationE", reflect.TypeOf((*MockConfigure)(nil).GetDurationE), arg0)
+}
+
+// GetTimeE mocks base method
+func (m *MockConfigure) GetTimeE(arg0 string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeE", arg0)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeE indicates an expected call of GetTimeE
+func (mr *MockConfigureMockRecorder) GetTimeE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeE", reflect.TypeOf((*MockConfigure)(nil).GetTimeE), arg0)
+}
+
+// GetStringSliceE mocks base method
+func (m *MockConfigure) GetStringSliceE(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringSliceE", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStringSliceE indicates an expected call of GetStringSliceE
+func (mr *MockConfigureMockRecorder) GetStringSliceE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringSliceE", reflect.TypeOf((*MockConfigure)(nil).GetStringSliceE), arg0)
+}
+
+// GetIntSliceE mocks base method
+func (m *MockConfigure) GetIntSliceE(arg0 string) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIntSliceE", arg0)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIntSliceE indicates an expected call of GetIntSliceE
+func (mr *MockConfigureMockRecorder) GetIntSliceE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIntSliceE", reflect.TypeOf((*MockConfigure)(nil).GetIntSliceE), arg0)
+}
+
+// GetStringMapE mocks base method
+func (m *MockConfigure) GetStringMapE(arg0 string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringMapE", arg0)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStringMapE indicates an expected call of GetStringMapE
+func (mr *MockConfigureMockRecorder) GetStringMapE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringMapE", reflect.TypeOf((*MockConfigure)(nil).GetStringMapE), arg0)
+}
+
+// GetStringMapStringE mocks base method
+func (m *MockConfigure) GetStringMapStringE(arg0 string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStringMapStringE", arg0)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStringMapStringE indicates an expected call of GetStringMapStringE
+func (mr *MockConfigureMockRecorder) GetStringMapStringE(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStringMapStringE", reflect.TypeOf((*MockConfigure)(nil).GetStringMapStringE), arg0)
+}
@@ -14,10 +14,78 @@ func WithUnmarshaler(name string) LoadOption {
 	}
 }
 
-// WithWatchCallback with watch callback
+// WithWatchCallback add watch callback
+//
+// The callbacks given by all the loads of the same config will be called.
 func WithWatchCallback(callback func(Configure)) LoadOption {
 	return func(c *configureImpl) {
-		c.watchCallback = callback
+		if callback != nil {
+			c.watchCallbacks = append(c.watchCallbacks, callback)
+		}
+	}
+}
+
+// WithLayer add a config layer over the config file
+//
+// Layers are merged in the order they are added, the later one has the
+// higher priority. e.g.
+//
+//	config.Load("./app.yaml",
+//		config.WithLayer(config.NewFileSource("./app.prod.yaml", "yaml")),
+//		config.WithLayer(config.NewFlagSource(flag.CommandLine)))
+func WithLayer(source Source) LoadOption {
+	return func(c *configureImpl) {
+		if source != nil {
+			c.layers = append(c.layers, source)
+		}
+	}
+}
+
+// WithValidator add config validator
+//
+// Validators are called with the new config on Load and Reload. Load will
+// fail and Reload will keep the last good config when validate fail.
+func WithValidator(validator Validator) LoadOption {
+	return func(c *configureImpl) {
+		if validator != nil {
+			c.validators = append(c.validators, validator)
+		}
+	}
+}
+
+// WithValidate validate config by the validate tag of schema struct
+//
+// schema is a struct or pointer to struct, the config will be unmarshaled
+// into a new instance of it and checked with the rules in validate tag.
+//
+//	type Config struct {
+//		Timeout int    `yaml:"timeout" validate:"required,min=1"`
+//		Mode    string `yaml:"mode" validate:"oneof=single cluster"`
+//	}
+//
+// Support rules: required, min=N, max=N, oneof=a b c.
+// min and max check the length of string, slice and map.
+func WithValidate(schema interface{}) LoadOption {
+	return WithValidator(structValidator(schema))
+}
+
+// WithRequiredKeys validate the keys exist
+//
+// k support key1.key2.key3
+func WithRequiredKeys(keys ...string) LoadOption {
+	return WithValidator(requiredKeysValidator(keys...))
+}
+
+// WithReloadErrorCallback add reload error callback
+//
+// callback will be called with the error when reload fail, e.g. the new
+// config is invalid. The error is *ValidationError when validate fail.
+// The last good config is kept when reload fail.
+func WithReloadErrorCallback(callback func(error)) LoadOption {
+	return func(c *configureImpl) {
+		if callback != nil {
+			c.reloadErrorCallbacks = append(c.reloadErrorCallbacks, callback)
+		}
 	}
 }
 
@@ -26,3 +94,47 @@ func withTest() LoadOption {
 		c.watcher = nil
 	}
 }
+
+// WithDecryptor assign the decryptor of the encrypted values
+//
+// The encrypted value is written as ENC(base64 ciphertext). The default
+// decryptor will be used when not given, see SetDefaultDecryptor.
+func WithDecryptor(decryptor Decryptor) LoadOption {
+	return func(c *configureImpl) {
+		c.decryptor = decryptor
+	}
+}
+
+// LoaderOption loader option
+type LoaderOption func(*Loader)
+
+// WithBaseDir set the base directory of loader
+//
+// The relative path and search paths will be resolved by the base directory.
+func WithBaseDir(dir string) LoaderOption {
+	return func(l *Loader) {
+		l.baseDir = dir
+	}
+}
+
+// WithSearchPaths set the search paths of loader
+//
+// The relative path will be searched in order, the first existing file
+// will be loaded. e.g.
+//
+//	config.NewLoader(config.WithSearchPaths("./", "./conf", "/etc/app"))
+func WithSearchPaths(paths ...string) LoaderOption {
+	return func(l *Loader) {
+		l.searchPaths = paths
+	}
+}
+
+// WithDefaultUnmarshaler set the default unmarshaler of loader
+//
+// Used when the file extension is unknown or loading from source, and
+// WithUnmarshaler not given.
+func WithDefaultUnmarshaler(name string) LoaderOption {
+	return func(l *Loader) {
+		l.unmarshaler = name
+	}
+}
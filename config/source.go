@@ -0,0 +1,189 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/wwwangxc/gopkg/config/unmarshaler"
+)
+
+// Source config source
+//
+// Sources are used as layers on top of the loaded config file. Each layer
+// is deep merged over the layers before it, so the value of a key resolves
+// from the highest-priority layer that defines it.
+type Source interface {
+
+	// Name source name
+	Name() string
+
+	// Read read source and return the unmarshaled data
+	Read() (map[string]interface{}, error)
+}
+
+// fileSource config file source
+type fileSource struct {
+	path        string
+	unmarshaler unmarshaler.Unmarshaler
+	watcher     *fileWatcher
+}
+
+// NewFileSource new config file source
+//
+// The file will be unmarshaled with the named unmarshaler, e.g. "yaml".
+// The unmarshaler will be picked by the file extension when unmarshalerName
+// is empty.
+func NewFileSource(path string, unmarshalerName string) Source {
+	u := unmarshaler.Get(unmarshalerName)
+	if unmarshalerName == "" {
+		u = unmarshalerByPath(path)
+	}
+
+	return &fileSource{
+		path:        path,
+		unmarshaler: u,
+	}
+}
+
+// Name source name
+func (f *fileSource) Name() string {
+	if f.unmarshaler == nil {
+		return fmt.Sprintf("file:%s", f.path)
+	}
+
+	return fmt.Sprintf("file:%s:%s", f.path, f.unmarshaler.Name())
+}
+
+// Read read file and return the unmarshaled data
+func (f *fileSource) Read() (map[string]interface{}, error) {
+	if f.unmarshaler == nil {
+		return nil, ErrUnmarshalerNotExist
+	}
+
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("read file fail. err:%w", err)
+	}
+
+	unmarshaledData := map[string]interface{}{}
+	if err = f.unmarshaler.Unmarshal(data, &unmarshaledData); err != nil {
+		return nil, fmt.Errorf("unmarshal fail. err:%w", err)
+	}
+
+	return unmarshaledData, nil
+}
+
+// Watch watch the file and notify when file changed
+func (f *fileSource) Watch(notify func()) error {
+	f.watcher = newFileWatcher(f.path)
+	return f.watcher.Watch(notify)
+}
+
+// Close stop watching
+func (f *fileSource) Close() error {
+	if f.watcher == nil {
+		return nil
+	}
+
+	return f.watcher.Close()
+}
+
+// flagSource command-line flag source
+type flagSource struct {
+	flagSet *flag.FlagSet
+}
+
+// NewFlagSource new command-line flag source
+//
+// Only the flags set on the command line will be read, and the flag name
+// is used as the key. e.g. -client.redis.timeout=2000 will override
+// client.redis.timeout.
+// The flag set must be parsed before config loaded.
+// Use flag.CommandLine when flagSet is nil.
+func NewFlagSource(flagSet *flag.FlagSet) Source {
+	if flagSet == nil {
+		flagSet = flag.CommandLine
+	}
+
+	return &flagSource{
+		flagSet: flagSet,
+	}
+}
+
+// Name source name
+func (f *flagSource) Name() string {
+	return fmt.Sprintf("flag:%s", f.flagSet.Name())
+}
+
+// Read read the flags set on the command line
+func (f *flagSource) Read() (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	f.flagSet.Visit(func(fl *flag.Flag) {
+		var val interface{} = fl.Value.String()
+		if getter, ok := fl.Value.(flag.Getter); ok {
+			val = getter.Get()
+		}
+
+		setToMap(data, strings.Split(fl.Name, "."), val)
+	})
+
+	return data, nil
+}
+
+// envSource environment variable source
+type envSource struct {
+	prefix    string
+	separator string
+}
+
+// NewEnvSource new environment variable source
+//
+// Only the environment variables with the prefix will be read. The prefix
+// will be trimmed, the rest will be split by separator and lowercased as
+// the key. e.g. with prefix "APP_" and separator "__",
+// APP_CLIENT__REDIS__MAX_IDLE=20 will override client.redis.max_idle.
+// Use "__" when separator is empty.
+//
+// The values are kept as strings, and converted to the target type by the
+// getters or Unmarshal, so "007" is still "007" for GetString.
+func NewEnvSource(prefix, separator string) Source {
+	if separator == "" {
+		separator = "__"
+	}
+
+	return &envSource{
+		prefix:    prefix,
+		separator: separator,
+	}
+}
+
+// Name source name
+func (e *envSource) Name() string {
+	return fmt.Sprintf("env:%s:%s", e.prefix, e.separator)
+}
+
+// Read read the environment variables with the prefix
+func (e *envSource) Read() (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	for _, env := range os.Environ() {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], e.prefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(kv[0], e.prefix)
+		if name == "" {
+			continue
+		}
+
+		subkeys := strings.Split(strings.ToLower(name), e.separator)
+		// keep the raw string, e.g. "007" and "1.10", it is converted by
+		// the getters or Unmarshal when read
+		setToMap(data, subkeys, kv[1])
+	}
+
+	return data, nil
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func Test_fileSource_Read(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  Source
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "unmarshaler not exist",
+			source:  NewFileSource("./testdata/config.prod.yaml", "not exist unmarshaler"),
+			wantErr: true,
+		},
+		{
+			name:    "read file fail",
+			source:  NewFileSource("./abc.yaml", "yaml"),
+			wantErr: true,
+		},
+		{
+			name:    "unmarshal fail",
+			source:  NewFileSource("./testdata/invalid_config.yaml", "yaml"),
+			wantErr: true,
+		},
+		{
+			name:   "normal",
+			source: NewFileSource("./testdata/config.prod.yaml", "yaml"),
+			want: map[string]interface{}{
+				"string_value": "prod string value",
+				"subkey": map[string]interface{}{
+					"int_value":  -100,
+					"prod_value": "prod",
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.source.Read()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fileSource.Read() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fileSource.Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_flagSource_Read(t *testing.T) {
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.Int("client.redis.timeout", 1000, "")
+	flagSet.String("client.redis.dsn", "", "")
+	flagSet.Bool("debug", false, "")
+	if err := flagSet.Parse([]string{"-client.redis.timeout=2000", "-debug"}); err != nil {
+		t.Fatalf("flag set parse fail. err:%v", err)
+	}
+
+	got, err := NewFlagSource(flagSet).Read()
+	if err != nil {
+		t.Errorf("flagSource.Read() error = %v", err)
+		return
+	}
+
+	want := map[string]interface{}{
+		"debug": true,
+		"client": map[string]interface{}{
+			"redis": map[string]interface{}{
+				"timeout": 2000,
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flagSource.Read() = %v, want %v", got, want)
+	}
+}
+
+func Test_envSource_Read(t *testing.T) {
+	t.Setenv("GOPKG_TEST_CLIENT__REDIS__TIMEOUT", "2000")
+	t.Setenv("GOPKG_TEST_CLIENT__REDIS__DSN", "redis://127.0.0.1:6379")
+	t.Setenv("GOPKG_TEST_CLIENT__REDIS__WAIT", "true")
+	t.Setenv("GOPKG_TEST_RATE", "0.5")
+	t.Setenv("GOPKG_TEST_", "ignored")
+
+	got, err := NewEnvSource("GOPKG_TEST_", "").Read()
+	if err != nil {
+		t.Errorf("envSource.Read() error = %v", err)
+		return
+	}
+
+	want := map[string]interface{}{
+		"rate": "0.5",
+		"client": map[string]interface{}{
+			"redis": map[string]interface{}{
+				"timeout": "2000",
+				"dsn":     "redis://127.0.0.1:6379",
+				"wait":    "true",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("envSource.Read() = %v, want %v", got, want)
+	}
+}
+
+func Test_mergeMap(t *testing.T) {
+	dst := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{
+			"c": 2,
+			"d": 3,
+		},
+		"e": map[interface{}]interface{}{
+			"f": 4,
+		},
+	}
+	src := map[string]interface{}{
+		"a": 10,
+		"b": map[string]interface{}{
+			"c": 20,
+		},
+		"e": map[string]interface{}{
+			"g": 50,
+		},
+	}
+	want := map[string]interface{}{
+		"a": 10,
+		"b": map[string]interface{}{
+			"c": 20,
+			"d": 3,
+		},
+		"e": map[string]interface{}{
+			"f": 4,
+			"g": 50,
+		},
+	}
+
+	mergeMap(dst, src)
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("mergeMap() = %v, want %v", dst, want)
+	}
+}
+
+func Test_unmarshalerByPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "./app.yaml", want: "yaml"},
+		{path: "./app.YML", want: "yaml"},
+		{path: "./app.json", want: "json"},
+		{path: "./app.toml", want: "toml"},
+		{path: "./app.ini", want: "ini"},
+		{path: "./app.hcl", want: "hcl"},
+		{path: "./main.tf", want: "yaml"},
+		{path: "./.env", want: "dotenv"},
+		{path: "./app.properties", want: "properties"},
+		{path: "./app", want: "yaml"},
+		{path: "./app.unknown", want: "yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := unmarshalerByPath(tt.path).Name(); got != tt.want {
+				t.Errorf("unmarshalerByPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
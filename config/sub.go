@@ -0,0 +1,227 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// subConfigure the scoped view of key prefix
+type subConfigure struct {
+	parent Configure
+	prefix string
+}
+
+func newSubConfigure(parent Configure, prefix string) *subConfigure {
+	return &subConfigure{
+		parent: parent,
+		prefix: strings.Trim(prefix, "."),
+	}
+}
+
+func (s *subConfigure) key(k string) string {
+	if k == "" {
+		return s.prefix
+	}
+
+	return joinKey(s.prefix, k)
+}
+
+// Unmarshal unmarshal the value of prefix
+func (s *subConfigure) Unmarshal(out interface{}) error {
+	return s.parent.UnmarshalKey(s.prefix, out)
+}
+
+// IsExist check the key exist
+func (s *subConfigure) IsExist(k string) bool {
+	return s.parent.IsExist(s.key(k))
+}
+
+// Get get value by key
+func (s *subConfigure) Get(k string, defaultVal interface{}) interface{} {
+	return s.parent.Get(s.key(k), defaultVal)
+}
+
+// GetString get string value by key
+func (s *subConfigure) GetString(k string, defaultVal string) string {
+	return s.parent.GetString(s.key(k), defaultVal)
+}
+
+// GetBool get bool value by key
+func (s *subConfigure) GetBool(k string, defaultVal bool) bool {
+	return s.parent.GetBool(s.key(k), defaultVal)
+}
+
+// GetInt get int value by key
+func (s *subConfigure) GetInt(k string, defaultVal int) int {
+	return s.parent.GetInt(s.key(k), defaultVal)
+}
+
+// GetInt32 get int32 value by key
+func (s *subConfigure) GetInt32(k string, defaultVal int32) int32 {
+	return s.parent.GetInt32(s.key(k), defaultVal)
+}
+
+// GetInt64 get int64 value by key
+func (s *subConfigure) GetInt64(k string, defaultVal int64) int64 {
+	return s.parent.GetInt64(s.key(k), defaultVal)
+}
+
+// GetUint get uint value by key
+func (s *subConfigure) GetUint(k string, defaultVal uint) uint {
+	return s.parent.GetUint(s.key(k), defaultVal)
+}
+
+// GetUint32 get uint32 value by key
+func (s *subConfigure) GetUint32(k string, defaultVal uint32) uint32 {
+	return s.parent.GetUint32(s.key(k), defaultVal)
+}
+
+// GetUint64 get uint64 value by key
+func (s *subConfigure) GetUint64(k string, defaultVal uint64) uint64 {
+	return s.parent.GetUint64(s.key(k), defaultVal)
+}
+
+// GetFloat32 get float32 value by key
+func (s *subConfigure) GetFloat32(k string, defaultVal float32) float32 {
+	return s.parent.GetFloat32(s.key(k), defaultVal)
+}
+
+// GetFloat64 get float64 value by key
+func (s *subConfigure) GetFloat64(k string, defaultVal float64) float64 {
+	return s.parent.GetFloat64(s.key(k), defaultVal)
+}
+
+// GetDuration get time.Duration value by key
+func (s *subConfigure) GetDuration(k string, defaultVal time.Duration) time.Duration {
+	return s.parent.GetDuration(s.key(k), defaultVal)
+}
+
+// GetTime get time.Time value by key
+func (s *subConfigure) GetTime(k string, defaultVal time.Time) time.Time {
+	return s.parent.GetTime(s.key(k), defaultVal)
+}
+
+// GetStringSlice get []string value by key
+func (s *subConfigure) GetStringSlice(k string, defaultVal []string) []string {
+	return s.parent.GetStringSlice(s.key(k), defaultVal)
+}
+
+// GetIntSlice get []int value by key
+func (s *subConfigure) GetIntSlice(k string, defaultVal []int) []int {
+	return s.parent.GetIntSlice(s.key(k), defaultVal)
+}
+
+// GetStringMap get map[string]interface{} value by key
+func (s *subConfigure) GetStringMap(k string, defaultVal map[string]interface{}) map[string]interface{} {
+	return s.parent.GetStringMap(s.key(k), defaultVal)
+}
+
+// GetStringMapString get map[string]string value by key
+func (s *subConfigure) GetStringMapString(k string, defaultVal map[string]string) map[string]string {
+	return s.parent.GetStringMapString(s.key(k), defaultVal)
+}
+
+// UnmarshalKey unmarshal the value of key into out
+func (s *subConfigure) UnmarshalKey(k string, out interface{}) error {
+	return s.parent.UnmarshalKey(s.key(k), out)
+}
+
+// Sub return the scoped view of key prefix
+func (s *subConfigure) Sub(prefix string) Configure {
+	return newSubConfigure(s.parent, s.key(prefix))
+}
+
+// OnChange subscribe the change of key
+func (s *subConfigure) OnChange(k string, callback func(old, new interface{})) {
+	s.parent.OnChange(s.key(k), callback)
+}
+
+// Bind bind the value of key to a typed snapshot
+func (s *subConfigure) Bind(k string, ptr interface{}) (*Binding, error) {
+	return s.parent.Bind(s.key(k), ptr)
+}
+
+// Close do nothing, the sub view shares the watchers of parent
+func (s *subConfigure) Close() error {
+	return nil
+}
+
+// GetStringE get string value by key
+func (s *subConfigure) GetStringE(k string) (string, error) {
+	return s.parent.GetStringE(s.key(k))
+}
+
+// GetBoolE get bool value by key
+func (s *subConfigure) GetBoolE(k string) (bool, error) {
+	return s.parent.GetBoolE(s.key(k))
+}
+
+// GetIntE get int value by key
+func (s *subConfigure) GetIntE(k string) (int, error) {
+	return s.parent.GetIntE(s.key(k))
+}
+
+// GetInt32E get int32 value by key
+func (s *subConfigure) GetInt32E(k string) (int32, error) {
+	return s.parent.GetInt32E(s.key(k))
+}
+
+// GetInt64E get int64 value by key
+func (s *subConfigure) GetInt64E(k string) (int64, error) {
+	return s.parent.GetInt64E(s.key(k))
+}
+
+// GetUintE get uint value by key
+func (s *subConfigure) GetUintE(k string) (uint, error) {
+	return s.parent.GetUintE(s.key(k))
+}
+
+// GetUint32E get uint32 value by key
+func (s *subConfigure) GetUint32E(k string) (uint32, error) {
+	return s.parent.GetUint32E(s.key(k))
+}
+
+// GetUint64E get uint64 value by key
+func (s *subConfigure) GetUint64E(k string) (uint64, error) {
+	return s.parent.GetUint64E(s.key(k))
+}
+
+// GetFloat32E get float32 value by key
+func (s *subConfigure) GetFloat32E(k string) (float32, error) {
+	return s.parent.GetFloat32E(s.key(k))
+}
+
+// GetFloat64E get float64 value by key
+func (s *subConfigure) GetFloat64E(k string) (float64, error) {
+	return s.parent.GetFloat64E(s.key(k))
+}
+
+// GetDurationE get time.Duration value by key
+func (s *subConfigure) GetDurationE(k string) (time.Duration, error) {
+	return s.parent.GetDurationE(s.key(k))
+}
+
+// GetTimeE get time.Time value by key
+func (s *subConfigure) GetTimeE(k string) (time.Time, error) {
+	return s.parent.GetTimeE(s.key(k))
+}
+
+// GetStringSliceE get []string value by key
+func (s *subConfigure) GetStringSliceE(k string) ([]string, error) {
+	return s.parent.GetStringSliceE(s.key(k))
+}
+
+// GetIntSliceE get []int value by key
+func (s *subConfigure) GetIntSliceE(k string) ([]int, error) {
+	return s.parent.GetIntSliceE(s.key(k))
+}
+
+// GetStringMapE get map[string]interface{} value by key
+func (s *subConfigure) GetStringMapE(k string) (map[string]interface{}, error) {
+	return s.parent.GetStringMapE(s.key(k))
+}
+
+// GetStringMapStringE get map[string]string value by key
+func (s *subConfigure) GetStringMapStringE(k string) (map[string]string, error) {
+	return s.parent.GetStringMapStringE(s.key(k))
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_configureImpl_RichGetters(t *testing.T) {
+	c := defaultConfigure("./testdata/rich_config.yaml")
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	if got := c.GetDuration("timeout", time.Second); got != 1500*time.Millisecond {
+		t.Errorf("configureImpl.GetDuration() = %v, want %v", got, 1500*time.Millisecond)
+	}
+
+	if got := c.GetDuration("not exist key", time.Second); got != time.Second {
+		t.Errorf("configureImpl.GetDuration() = %v, want %v", got, time.Second)
+	}
+
+	want := time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC)
+	if got := c.GetTime("start_at", time.Time{}); !got.Equal(want) {
+		t.Errorf("configureImpl.GetTime() = %v, want %v", got, want)
+	}
+
+	if got := c.GetStringSlice("hosts", nil); !reflect.DeepEqual(got, []string{"127.0.0.1", "127.0.0.2"}) {
+		t.Errorf("configureImpl.GetStringSlice() = %v", got)
+	}
+
+	if got := c.GetIntSlice("ports", nil); !reflect.DeepEqual(got, []int{6379, 6380}) {
+		t.Errorf("configureImpl.GetIntSlice() = %v", got)
+	}
+
+	if got := c.GetIntSlice("hosts", []int{1}); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("configureImpl.GetIntSlice() = %v, want default value", got)
+	}
+
+	if got := c.GetStringMap("labels", nil); !reflect.DeepEqual(got, map[string]interface{}{"env": "test", "zone": "a"}) {
+		t.Errorf("configureImpl.GetStringMap() = %v", got)
+	}
+
+	if got := c.GetStringMapString("labels", nil); !reflect.DeepEqual(got, map[string]string{"env": "test", "zone": "a"}) {
+		t.Errorf("configureImpl.GetStringMapString() = %v", got)
+	}
+}
+
+func Test_configureImpl_ArrayIndex(t *testing.T) {
+	c := defaultConfigure("./testdata/rich_config.yaml")
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		k    string
+		want interface{}
+	}{
+		{name: "index", k: "hosts.1", want: "127.0.0.2"},
+		{name: "index of map", k: "client.service.1.name", want: "redis_2"},
+		{name: "index out of range", k: "client.service.2.name", want: nil},
+		{name: "invalid index", k: "client.service.a.name", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Get(tt.k, nil); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configureImpl.Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_configureImpl_UnmarshalKey(t *testing.T) {
+	c := defaultConfigure("./testdata/rich_config.yaml")
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	type serviceConfig struct {
+		Name string `yaml:"name"`
+		DSN  string `yaml:"dsn"`
+	}
+
+	var services []serviceConfig
+	if err := c.UnmarshalKey("client.service", &services); err != nil {
+		t.Fatalf("configureImpl.UnmarshalKey() error = %v", err)
+	}
+
+	want := []serviceConfig{
+		{Name: "redis_1", DSN: "redis://127.0.0.1:6379/1"},
+		{Name: "redis_2", DSN: "redis://127.0.0.1:6379/2"},
+	}
+	if !reflect.DeepEqual(services, want) {
+		t.Errorf("configureImpl.UnmarshalKey() = %v, want %v", services, want)
+	}
+
+	if err := c.UnmarshalKey("not exist key", &services); err == nil {
+		t.Errorf("configureImpl.UnmarshalKey() error = nil, want error")
+	}
+}
+
+func Test_subConfigure(t *testing.T) {
+	c := defaultConfigure("./testdata/rich_config.yaml")
+	c.watcher = nil
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	sub := c.Sub("client")
+	if !sub.IsExist("redis.timeout") {
+		t.Errorf("subConfigure.IsExist() = false, want true")
+	}
+
+	if got := sub.GetInt("redis.timeout", 0); got != 1000 {
+		t.Errorf("subConfigure.GetInt() = %v, want %v", got, 1000)
+	}
+
+	if got := sub.Sub("service.0").GetString("name", ""); got != "redis_1" {
+		t.Errorf("subConfigure.Sub().GetString() = %v, want %v", got, "redis_1")
+	}
+
+	out := struct {
+		Redis struct {
+			Timeout int `yaml:"timeout"`
+		} `yaml:"redis"`
+	}{}
+	if err := sub.Unmarshal(&out); err != nil || out.Redis.Timeout != 1000 {
+		t.Errorf("subConfigure.Unmarshal() = %+v, error = %v", out, err)
+	}
+}
@@ -0,0 +1,7 @@
+string_value = "string value"
+env_value = "${GOPKG_TEST_ENV}"
+
+subkey {
+  int_value = -1
+  bool_value = true
+}
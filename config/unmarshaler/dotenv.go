@@ -0,0 +1,134 @@
+package unmarshaler
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(&DotEnv{})
+}
+
+// DotEnv dotenv unmarshaler
+//
+// Each line is a KEY=VALUE pair, the optional export prefix, comments,
+// single and double quoted values are supported. Only ${VAR} is expanded.
+// The key is split by "." as the nested keys, unquoted values are parsed
+// into int, float and bool.
+// Struct fields use the yaml tag.
+type DotEnv struct{}
+
+// Unmarshal unmarshal by dotenv
+func (d *DotEnv) Unmarshal(in []byte, out interface{}) error {
+	data, err := parseDotEnv(expandEnv(string(in)))
+	if err != nil {
+		return err
+	}
+
+	return assign(data, out)
+}
+
+// Marshal marshal by dotenv
+func (d *DotEnv) Marshal(in interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	flatten("", in, func(k string, v interface{}) {
+		switch val := v.(type) {
+		case string:
+			fmt.Fprintf(buf, "%s=%s\n", k, strconv.Quote(val))
+		case nil:
+			fmt.Fprintf(buf, "%s=\n", k)
+		default:
+			fmt.Fprintf(buf, "%s=%v\n", k, val)
+		}
+	})
+
+	return buf.Bytes(), nil
+}
+
+// Name unmarshal name
+func (d *DotEnv) Name() string {
+	return "dotenv"
+}
+
+// Extensions file extensions
+func (d *DotEnv) Extensions() []string {
+	return []string{".env"}
+}
+
+func parseDotEnv(s string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		idx := strings.Index(line, "=")
+		if idx < 1 {
+			return nil, fmt.Errorf("dotenv: line %d: invalid format", i+1)
+		}
+
+		k := strings.TrimSpace(line[:idx])
+		v, err := parseDotEnvValue(strings.TrimSpace(line[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("dotenv: line %d: %w", i+1, err)
+		}
+
+		setValue(data, strings.Split(k, "."), v)
+	}
+
+	return data, nil
+}
+
+func parseDotEnvValue(v string) (interface{}, error) {
+	if v == "" {
+		return "", nil
+	}
+
+	switch v[0] {
+	case '\'':
+		end := strings.Index(v[1:], "'")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quoted value")
+		}
+		return v[1 : end+1], nil
+	case '"':
+		var buf strings.Builder
+		for i := 1; i < len(v); i++ {
+			switch v[i] {
+			case '"':
+				return buf.String(), nil
+			case '\\':
+				if i+1 < len(v) {
+					i++
+					buf.WriteByte(unescape(v[i]))
+				}
+			default:
+				buf.WriteByte(v[i])
+			}
+		}
+		return nil, fmt.Errorf("unterminated quoted value")
+	}
+
+	if idx := strings.Index(v, " #"); idx >= 0 {
+		v = strings.TrimSpace(v[:idx])
+	}
+
+	return parseValue(v), nil
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	default:
+		return c
+	}
+}
@@ -0,0 +1,82 @@
+package unmarshaler
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/hcl"
+)
+
+func init() {
+	Register(&HCL{})
+}
+
+// HCL hcl unmarshaler
+//
+// The blocks are merged into nested maps when unmarshal into map, e.g.
+// int_value in block subkey is subkey.int_value.
+// Struct fields use the hcl tag.
+type HCL struct{}
+
+// Unmarshal unmarshal by hcl
+func (h *HCL) Unmarshal(in []byte, out interface{}) error {
+	in = []byte(expandEnv(string(in)))
+
+	m, ok := out.(*map[string]interface{})
+	if !ok {
+		return hcl.Unmarshal(in, out)
+	}
+
+	data := map[string]interface{}{}
+	if err := hcl.Unmarshal(in, &data); err != nil {
+		return err
+	}
+
+	if *m == nil {
+		*m = map[string]interface{}{}
+	}
+
+	for k, v := range data {
+		(*m)[k] = mergeHCLBlocks(v)
+	}
+
+	return nil
+}
+
+// Marshal marshal into the json form of hcl, which can be unmarshaled by
+// hcl
+func (h *HCL) Marshal(in interface{}) ([]byte, error) {
+	return json.Marshal(in)
+}
+
+// Name unmarshal name
+func (h *HCL) Name() string {
+	return "hcl"
+}
+
+// Extensions file extensions
+//
+// .tf is not registered, the terraform files are not supported by hcl v1.
+func (h *HCL) Extensions() []string {
+	return []string{".hcl"}
+}
+
+// mergeHCLBlocks merge the list of blocks into one map
+func mergeHCLBlocks(val interface{}) interface{} {
+	switch v := val.(type) {
+	case []map[string]interface{}:
+		merged := map[string]interface{}{}
+		for _, block := range v {
+			for k, item := range block {
+				merged[k] = mergeHCLBlocks(item)
+			}
+		}
+		return merged
+	case []interface{}:
+		for i, item := range v {
+			v[i] = mergeHCLBlocks(item)
+		}
+		return v
+	default:
+		return val
+	}
+}
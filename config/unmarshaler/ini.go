@@ -0,0 +1,99 @@
+package unmarshaler
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+func init() {
+	Register(&INI{})
+}
+
+// INI ini unmarshaler
+//
+// The section name and key are split by "." as the nested keys, e.g. key
+// timeout in section [client.redis] is client.redis.timeout.
+// Struct fields use the yaml tag.
+type INI struct{}
+
+// Unmarshal unmarshal by ini
+func (i *INI) Unmarshal(in []byte, out interface{}) error {
+	in = []byte(expandEnv(string(in)))
+	f, err := ini.Load(in)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{}
+	for _, section := range f.Sections() {
+		var prefix []string
+		if section.Name() != ini.DefaultSection {
+			prefix = strings.Split(section.Name(), ".")
+		}
+
+		for _, key := range section.Keys() {
+			subkeys := make([]string, 0, len(prefix)+1)
+			subkeys = append(subkeys, prefix...)
+			subkeys = append(subkeys, strings.Split(key.Name(), ".")...)
+			setValue(data, subkeys, parseValue(key.Value()))
+		}
+	}
+
+	return assign(data, out)
+}
+
+// Marshal marshal by ini
+//
+// The top-level map values are written as sections.
+func (i *INI) Marshal(in interface{}) ([]byte, error) {
+	data, ok := in.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ini: unsupported type %T", in)
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	f := ini.Empty()
+	for _, k := range keys {
+		section, prefix := f.Section(ini.DefaultSection), k
+		if _, isMap := data[k].(map[string]interface{}); isMap {
+			section, prefix = f.Section(k), ""
+		}
+
+		var err error
+		flatten(prefix, data[k], func(k string, v interface{}) {
+			if err == nil {
+				_, err = section.NewKey(k, fmt.Sprint(v))
+			}
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := f.WriteTo(buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Name unmarshal name
+func (i *INI) Name() string {
+	return "ini"
+}
+
+// Extensions file extensions
+func (i *INI) Extensions() []string {
+	return []string{".ini"}
+}
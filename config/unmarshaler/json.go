@@ -5,7 +5,7 @@ import (
 )
 
 func init() {
-	register(&JSON{})
+	Register(&JSON{})
 }
 
 // JSON json unmarshaler
@@ -17,7 +17,17 @@ func (j *JSON) Unmarshal(in []byte, out interface{}) error {
 	return json.Unmarshal(in, out)
 }
 
+// Marshal marshal by json
+func (j *JSON) Marshal(in interface{}) ([]byte, error) {
+	return json.Marshal(in)
+}
+
 // Name unmarshal name
 func (j *JSON) Name() string {
 	return "json"
 }
+
+// Extensions file extensions
+func (j *JSON) Extensions() []string {
+	return []string{".json"}
+}
@@ -0,0 +1,77 @@
+package unmarshaler
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/magiconair/properties"
+)
+
+func init() {
+	Register(&Properties{})
+}
+
+// Properties java properties unmarshaler
+//
+// The key is split by "." as the nested keys, e.g. client.redis.timeout.
+// Only ${VAR} of environment variable is expanded, the reference of other
+// keys is not supported.
+// Struct fields use the yaml tag.
+type Properties struct{}
+
+// Unmarshal unmarshal by properties
+func (p *Properties) Unmarshal(in []byte, out interface{}) error {
+	in = []byte(expandEnv(string(in)))
+	loader := &properties.Loader{
+		Encoding:         properties.UTF8,
+		DisableExpansion: true,
+	}
+
+	props, err := loader.LoadBytes(in)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{}
+	for _, k := range props.Keys() {
+		v, _ := props.Get(k)
+		setValue(data, strings.Split(k, "."), parseValue(v))
+	}
+
+	return assign(data, out)
+}
+
+// Marshal marshal by properties
+func (p *Properties) Marshal(in interface{}) ([]byte, error) {
+	props := properties.NewProperties()
+	props.DisableExpansion = true
+
+	var err error
+	flatten("", in, func(k string, v interface{}) {
+		if err == nil {
+			_, _, err = props.Set(k, fmt.Sprint(v))
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err = props.Write(buf, properties.UTF8); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Name unmarshal name
+func (p *Properties) Name() string {
+	return "properties"
+}
+
+// Extensions file extensions
+func (p *Properties) Extensions() []string {
+	return []string{".properties", ".props"}
+}
@@ -1,11 +1,13 @@
 package unmarshaler
 
 import (
+	"bytes"
+
 	"github.com/BurntSushi/toml"
 )
 
 func init() {
-	register(&TOML{})
+	Register(&TOML{})
 }
 
 // TOML toml unmarshaler
@@ -17,7 +19,22 @@ func (t *TOML) Unmarshal(in []byte, out interface{}) error {
 	return toml.Unmarshal(in, out)
 }
 
+// Marshal marshal by toml
+func (t *TOML) Marshal(in interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(in); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 // Name unmarshal name
 func (t *TOML) Name() string {
 	return "toml"
 }
+
+// Extensions file extensions
+func (t *TOML) Extensions() []string {
+	return []string{".toml"}
+}
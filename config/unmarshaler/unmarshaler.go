@@ -1,20 +1,40 @@
 package unmarshaler
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+
+	"gopkg.in/yaml.v3"
 )
 
 var (
 	unmarshalerMap   = map[string]Unmarshaler{}
+	extensionMap     = map[string]string{}
 	unmarshalerMapRW sync.RWMutex
 )
 
-// register register unmarshaler
-func register(unmarshaler Unmarshaler) {
+// Register register unmarshaler
+//
+// The unmarshaler with the same name will be replaced. If the unmarshaler
+// implements Extensioner, it will also be registered for the file extensions.
+func Register(unmarshaler Unmarshaler) {
+	if unmarshaler == nil {
+		return
+	}
+
 	unmarshalerMapRW.Lock()
 	defer unmarshalerMapRW.Unlock()
 	unmarshalerMap[unmarshaler.Name()] = unmarshaler
+
+	if e, ok := unmarshaler.(Extensioner); ok {
+		for _, ext := range e.Extensions() {
+			extensionMap[normalizeExtension(ext)] = unmarshaler.Name()
+		}
+	}
 }
 
 // Get get unmarshaler by name
@@ -24,6 +44,27 @@ func Get(name string) Unmarshaler {
 	return unmarshalerMap[name]
 }
 
+// GetByExtension get unmarshaler by file extension, e.g. ".yml"
+//
+// The unmarshaler named as the extension will be returned when no
+// unmarshaler registered for the extension.
+func GetByExtension(ext string) Unmarshaler {
+	ext = normalizeExtension(ext)
+
+	unmarshalerMapRW.RLock()
+	defer unmarshalerMapRW.RUnlock()
+
+	if name, exist := extensionMap[ext]; exist {
+		return unmarshalerMap[name]
+	}
+
+	return unmarshalerMap[ext]
+}
+
+func normalizeExtension(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
 // Unmarshaler ...
 type Unmarshaler interface {
 
@@ -34,6 +75,121 @@ type Unmarshaler interface {
 	Name() string
 }
 
+// Marshaler ...
+//
+// Used to encode the merged config data when config has layers.
+type Marshaler interface {
+
+	// Marshal ...
+	Marshal(interface{}) ([]byte, error)
+}
+
+// Extensioner ...
+//
+// Used to pick the unmarshaler by file extension.
+type Extensioner interface {
+
+	// Extensions the file extensions of the format, e.g. ".yaml", ".yml"
+	Extensions() []string
+}
+
+// assign assign the parsed key/value data to out
+//
+// out can be a pointer to map or any value yaml can decode into, struct
+// fields use the yaml tag.
+func assign(data map[string]interface{}, out interface{}) error {
+	if m, ok := out.(*map[string]interface{}); ok {
+		if *m == nil {
+			*m = map[string]interface{}{}
+		}
+
+		for k, v := range data {
+			(*m)[k] = v
+		}
+
+		return nil
+	}
+
+	in, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(in, out)
+}
+
+// setValue set value to map by subkeys, the nested map will be created if not exist
+func setValue(m map[string]interface{}, subkeys []string, val interface{}) {
+	if len(subkeys) == 1 {
+		m[subkeys[0]] = val
+		return
+	}
+
+	sub, ok := m[subkeys[0]].(map[string]interface{})
+	if !ok {
+		sub = map[string]interface{}{}
+		m[subkeys[0]] = sub
+	}
+
+	setValue(sub, subkeys[1:], val)
+}
+
+// parseValue parse the string value into int, float or bool
+//
+// The value will be returned as is when parse fail.
+func parseValue(val string) interface{} {
+	if i, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f
+	}
+
+	if val == "true" || val == "false" {
+		return val == "true"
+	}
+
+	return val
+}
+
+// flatten flatten the nested data into sorted key/value pairs, the keys
+// are joined by "." and slice index is used as key.
+func flatten(prefix string, data interface{}, fn func(k string, v interface{})) {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			flatten(joinKey(prefix, k), val[k], fn)
+		}
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			m[fmt.Sprint(k)] = v
+		}
+		flatten(prefix, m, fn)
+	case []interface{}:
+		for i, v := range val {
+			flatten(joinKey(prefix, strconv.Itoa(i)), v, fn)
+		}
+	default:
+		fn(prefix, val)
+	}
+}
+
+func joinKey(prefix, k string) string {
+	if prefix == "" {
+		return k
+	}
+
+	return prefix + "." + k
+}
+
 // expandEnv 寻找 ${var} 并替换为环境变量的值，没有则替换为空，不解析 $var
 //
 // os.ExpandEnv 会同时处理${var}和$var，配置文件中可能包含一些含特殊字符$的配置项，
@@ -5,7 +5,7 @@ import (
 )
 
 func init() {
-	register(&YAML{})
+	Register(&YAML{})
 }
 
 // YAML yaml unmarshaler
@@ -17,7 +17,17 @@ func (y *YAML) Unmarshal(in []byte, out interface{}) error {
 	return yaml.Unmarshal(in, out)
 }
 
+// Marshal marshal by yaml
+func (y *YAML) Marshal(in interface{}) ([]byte, error) {
+	return yaml.Marshal(in)
+}
+
 // Name unmarshal name
 func (y *YAML) Name() string {
 	return "yaml"
 }
+
+// Extensions file extensions
+func (y *YAML) Extensions() []string {
+	return []string{".yaml", ".yml"}
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const validateTagName = "validate"
+
+// Validator config validator
+//
+// Validator will be called with the new config before it is applied on
+// Load and Reload. The config will not be applied when validate fail.
+type Validator func(Configure) error
+
+// ValidationError config validate fail
+type ValidationError struct {
+	// Name config file path or source name
+	Name string
+
+	// Errors validate errors
+	Errors []error
+}
+
+// Error ...
+func (v *ValidationError) Error() string {
+	errs := make([]string, 0, len(v.Errors))
+	for _, err := range v.Errors {
+		errs = append(errs, err.Error())
+	}
+
+	return fmt.Sprintf("%s: %s validate fail. %s", packageName, v.Name, strings.Join(errs, "; "))
+}
+
+// IsValidationError is validation error
+func IsValidationError(err error) bool {
+	var target *ValidationError
+	return errors.As(err, &target)
+}
+
+// RuleError the key break the validate rule
+type RuleError struct {
+	// Key the config key, e.g. client.redis.timeout
+	Key string
+
+	// Rule the broken rule, e.g. min=1
+	Rule string
+}
+
+// Error ...
+func (r *RuleError) Error() string {
+	return fmt.Sprintf("key:%s rule:%s", r.Key, r.Rule)
+}
+
+// requiredKeysValidator validate the keys exist
+func requiredKeysValidator(keys ...string) Validator {
+	return func(c Configure) error {
+		var errs []error
+		for _, k := range keys {
+			if !c.IsExist(k) {
+				errs = append(errs, &RuleError{Key: k, Rule: "required"})
+			}
+		}
+
+		return newValidationError(errs)
+	}
+}
+
+// structValidator validate config by the validate tag of schema struct
+func structValidator(schema interface{}) Validator {
+	return func(c Configure) error {
+		t := reflect.TypeOf(schema)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t == nil || t.Kind() != reflect.Struct {
+			return fmt.Errorf("invalid schema type %T", schema)
+		}
+
+		out := reflect.New(t)
+		if err := c.Unmarshal(out.Interface()); err != nil {
+			return err
+		}
+
+		tagName := "yaml"
+		if impl, ok := c.(*configureImpl); ok {
+			tagName = impl.structTagName()
+		}
+
+		return newValidationError(validateStruct(out.Elem(), tagName, ""))
+	}
+}
+
+func newValidationError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: errs}
+}
+
+func validateStruct(v reflect.Value, tagName, prefix string) []error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline := fieldKey(field, tagName)
+		if name == "-" {
+			continue
+		}
+
+		key := prefix
+		if !inline {
+			key = joinKey(prefix, name)
+		}
+
+		fv := v.Field(i)
+		for _, rule := range strings.Split(field.Tag.Get(validateTagName), ",") {
+			if rule = strings.TrimSpace(rule); rule == "" {
+				continue
+			}
+
+			ok, err := checkRule(fv, rule)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("key:%s rule:%s err:%w", key, rule, err))
+				continue
+			}
+
+			if !ok {
+				errs = append(errs, &RuleError{Key: key, Rule: rule})
+			}
+		}
+
+		errs = append(errs, validateNested(fv, tagName, key)...)
+	}
+
+	return errs
+}
+
+func validateNested(v reflect.Value, tagName, key string) []error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return validateNested(v.Elem(), tagName, key)
+	case reflect.Struct:
+		return validateStruct(v, tagName, key)
+	case reflect.Slice, reflect.Array:
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateNested(v.Index(i), tagName, joinKey(key, strconv.Itoa(i)))...)
+		}
+		return errs
+	default:
+		return nil
+	}
+}
+
+// fieldKey return the key name of field by the unmarshaler tag
+func fieldKey(field reflect.StructField, tagName string) (string, bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		return "-", false
+	}
+
+	tag := strings.Split(field.Tag.Get(tagName), ",")
+	for _, v := range tag[1:] {
+		if v == "inline" {
+			return "", true
+		}
+	}
+
+	if tag[0] != "" {
+		return tag[0], false
+	}
+
+	if field.Anonymous && field.Type.Kind() == reflect.Struct {
+		return "", true
+	}
+
+	return strings.ToLower(field.Name), false
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s.%s", prefix, name)
+}
+
+// checkRule check value with rule
+//
+// Support rules:
+//
+//	required        value is not zero value
+//	min=N           number >= N, or length of string, slice and map >= N
+//	max=N           number <= N, or length of string, slice and map <= N
+//	oneof=a b c     value is one of the space separated values, the nil
+//	                pointer is skipped, use required to check it
+func checkRule(v reflect.Value, rule string) (bool, error) {
+	name, param := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		name, param = rule[:i], rule[i+1:]
+	}
+
+	switch name {
+	case "required":
+		return !v.IsZero(), nil
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid param %s", param)
+		}
+
+		val, ok := ruleNumber(v)
+		if !ok {
+			return false, fmt.Errorf("unsupported type %s", v.Type())
+		}
+
+		if name == "min" {
+			return val >= limit, nil
+		}
+		return val <= limit, nil
+	case "oneof":
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return true, nil
+		}
+
+		val := fmt.Sprint(reflect.Indirect(v).Interface())
+		for _, v := range strings.Fields(param) {
+			if v == val {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown rule %s", name)
+	}
+}
+
+// ruleNumber return the number value or length used by min and max
+func ruleNumber(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	case reflect.Ptr:
+		if v.IsNil() {
+			return 0, true
+		}
+		return ruleNumber(v.Elem())
+	default:
+		return 0, false
+	}
+}
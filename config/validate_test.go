@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testRedisConfig struct {
+	Timeout int     `yaml:"timeout" validate:"required,min=1,max=5000"`
+	Mode    string  `yaml:"mode" validate:"oneof=single sentinel cluster"`
+	Role    *string `yaml:"role" validate:"oneof=master replica"`
+}
+
+type testServiceConfig struct {
+	Name string `yaml:"name" validate:"required"`
+	DSN  string `yaml:"dsn" validate:"min=1"`
+
+	testRedisConfig `yaml:",inline"`
+}
+
+type testSchema struct {
+	Client struct {
+		Redis   testRedisConfig     `yaml:"redis"`
+		Service []testServiceConfig `yaml:"service" validate:"min=1"`
+	} `yaml:"client"`
+}
+
+func Test_structValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantErrs []error
+	}{
+		{
+			name: "valid",
+			data: `
+client:
+  redis:
+    timeout: 1000
+    mode: single
+  service:
+    - name: redis_1
+      dsn: redis://127.0.0.1:6379
+      timeout: 1000
+      mode: cluster
+`,
+		},
+		{
+			name: "invalid",
+			data: `
+client:
+  redis:
+    timeout: 0
+    mode: unknown
+    role: unknown
+  service:
+    - dsn: redis://127.0.0.1:6379
+      timeout: 9999
+      mode: single
+`,
+			wantErrs: []error{
+				&RuleError{Key: "client.redis.timeout", Rule: "required"},
+				&RuleError{Key: "client.redis.timeout", Rule: "min=1"},
+				&RuleError{Key: "client.redis.mode", Rule: "oneof=single sentinel cluster"},
+				&RuleError{Key: "client.redis.role", Rule: "oneof=master replica"},
+				&RuleError{Key: "client.service.0.name", Rule: "required"},
+				&RuleError{Key: "client.service.0.timeout", Rule: "max=5000"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaultConfigure("")
+			c.watcher = nil
+			c.rawData = []byte(tt.data)
+
+			err := structValidator(&testSchema{})(c)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("structValidator() error = %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("structValidator() error = %v, want *ValidationError", err)
+			}
+
+			if !reflect.DeepEqual(validationErr.Errors, tt.wantErrs) {
+				t.Errorf("structValidator() errors = %v, want %v", validationErr.Errors, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_configureImpl_Validate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("client:\n  redis:\n    timeout: 0\n"), 0644); err != nil {
+		t.Fatalf("write file fail. err:%v", err)
+	}
+
+	c := defaultConfigure(path)
+	c.watcher = nil
+	WithRequiredKeys("client.redis.timeout", "client.service")(c)
+	if err := c.Load(); !IsValidationError(err) {
+		t.Fatalf("configureImpl.Load() error = %v, want validation error", err)
+	}
+
+	valid := "client:\n  redis:\n    timeout: 1000\n    mode: single\n  service:\n    - name: redis_1\n      dsn: dsn\n      timeout: 1000\n      mode: single\n"
+	if err := ioutil.WriteFile(path, []byte(valid), 0644); err != nil {
+		t.Fatalf("write file fail. err:%v", err)
+	}
+
+	var reloadErr error
+	WithValidate(testSchema{})(c)
+	WithReloadErrorCallback(func(err error) { reloadErr = err })(c)
+	if err := c.Load(); err != nil {
+		t.Fatalf("configureImpl.Load() error = %v", err)
+	}
+
+	invalid := "client:\n  redis:\n    timeout: 0\n    mode: single\n  service:\n    - name: redis_1\n      dsn: dsn\n      timeout: 1000\n      mode: single\n"
+	if err := ioutil.WriteFile(path, []byte(invalid), 0644); err != nil {
+		t.Fatalf("write file fail. err:%v", err)
+	}
+
+	if err := c.Reload(); !IsValidationError(err) {
+		t.Errorf("configureImpl.Reload() error = %v, want validation error", err)
+	}
+
+	if !IsValidationError(reloadErr) {
+		t.Errorf("reload error callback error = %v, want validation error", reloadErr)
+	}
+
+	if got := c.GetInt("client.redis.timeout", 0); got != 1000 {
+		t.Errorf("configureImpl.GetInt() = %v, want last good value %v", got, 1000)
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultDebounce the bursts of file events in debounce will be notified once
+const defaultDebounce = 100 * time.Millisecond
+
+// Watcher config source watcher
+//
+// A source implementing Watcher will be watched after config loaded, the
+// config will be reloaded and the watch callback will be called when notified.
+type Watcher interface {
+
+	// Watch start watching without blocking, notify should be called
+	// when the source changed.
+	Watch(notify func()) error
+
+	// Close stop watching
+	Close() error
+}
+
+// fileWatcher config file watcher based on fsnotify
+//
+// The directory of file is watched instead of the file, so the file
+// replaced by rename (e.g. saved by editor) and the symlink retargeted
+// (e.g. kubernetes configmap ..data swap) can be detected.
+type fileWatcher struct {
+	path     string
+	realPath string
+	debounce time.Duration
+
+	rw      sync.Mutex
+	watcher *fsnotify.Watcher
+}
+
+func newFileWatcher(path string) *fileWatcher {
+	return &fileWatcher{
+		path:     filepath.Clean(path),
+		debounce: defaultDebounce,
+	}
+}
+
+// Watch start watching the directory of file
+func (f *fileWatcher) Watch(notify func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("new file watcher fail. err:%w", err)
+	}
+
+	if err = watcher.Add(filepath.Dir(f.path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watch file fail. err:%w", err)
+	}
+
+	f.rw.Lock()
+	if f.watcher != nil {
+		f.watcher.Close()
+	}
+	f.watcher = watcher
+	f.rw.Unlock()
+
+	f.realPath, _ = filepath.EvalSymlinks(f.path)
+	go f.run(watcher, notify)
+
+	return nil
+}
+
+func (f *fileWatcher) run(watcher *fsnotify.Watcher, notify func()) {
+	timer := time.NewTimer(f.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				logInfo("%s: break file watch. file:%s\n", packageName, f.path)
+				return
+			}
+
+			if !f.changed(event) {
+				continue
+			}
+
+			timer.Reset(f.debounce)
+
+		case <-timer.C:
+			notify()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				logInfo("%s: break file watch. file:%s\n", packageName, f.path)
+				return
+			}
+
+			logErrorf("%s: file watch error. file:%s err:%v\n", packageName, f.path, err)
+		}
+	}
+}
+
+// changed is the file changed by the event of directory
+func (f *fileWatcher) changed(event fsnotify.Event) bool {
+	realPath, _ := filepath.EvalSymlinks(f.path)
+	if realPath != "" && realPath != f.realPath {
+		logInfo("%s: file target changed. file:%s target:%s\n", packageName, f.path, realPath)
+		f.realPath = realPath
+		return true
+	}
+
+	if filepath.Clean(event.Name) != f.path {
+		return false
+	}
+
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		logInfo("%s: file removed, wait for it to be created. file:%s\n", packageName, f.path)
+		return false
+	}
+
+	return event.Op&(fsnotify.Write|fsnotify.Create) != 0
+}
+
+// Close stop watching
+func (f *fileWatcher) Close() error {
+	f.rw.Lock()
+	defer f.rw.Unlock()
+
+	if f.watcher == nil {
+		return nil
+	}
+
+	err := f.watcher.Close()
+	f.watcher = nil
+
+	return err
+}
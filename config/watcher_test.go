@@ -0,0 +1,148 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileWatcher(t *testing.T, path string) (*fileWatcher, chan struct{}) {
+	notified := make(chan struct{}, 10)
+	w := newFileWatcher(path)
+	w.debounce = 20 * time.Millisecond
+	if err := w.Watch(func() { notified <- struct{}{} }); err != nil {
+		t.Fatalf("fileWatcher.Watch() error = %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+
+	return w, notified
+}
+
+func waitNotified(t *testing.T, notified chan struct{}, want int) {
+	got := 0
+	timeout := time.After(500 * time.Millisecond)
+	for {
+		select {
+		case <-notified:
+			got++
+		case <-timeout:
+			if got != want {
+				t.Errorf("notified %d times, want %d", got, want)
+			}
+			return
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write file error = %v", err)
+	}
+}
+
+func Test_fileWatcher_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeFile(t, path, "key: 1")
+
+	_, notified := newTestFileWatcher(t, path)
+
+	// the bursts of write will be notified once
+	for i := 0; i < 5; i++ {
+		writeFile(t, path, "key: 2")
+	}
+
+	waitNotified(t, notified, 1)
+}
+
+func Test_fileWatcher_Rename(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	writeFile(t, path, "key: 1")
+
+	_, notified := newTestFileWatcher(t, path)
+
+	// ignore other files in directory
+	writeFile(t, filepath.Join(dir, "other.yaml"), "key: 1")
+	waitNotified(t, notified, 0)
+
+	// write then rename, like editors
+	tmp := filepath.Join(dir, "app.yaml.swp")
+	writeFile(t, tmp, "key: 2")
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("rename error = %v", err)
+	}
+
+	waitNotified(t, notified, 1)
+}
+
+func Test_fileWatcher_Symlink(t *testing.T) {
+	// the layout of kubernetes configmap volume
+	// app.yaml -> ..data/app.yaml, ..data -> ..v1
+	dir := t.TempDir()
+	for _, v := range []string{"..v1", "..v2"} {
+		if err := os.Mkdir(filepath.Join(dir, v), 0755); err != nil {
+			t.Fatalf("mkdir error = %v", err)
+		}
+		writeFile(t, filepath.Join(dir, v, "app.yaml"), "version: "+v)
+	}
+
+	if err := os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("symlink error = %v", err)
+	}
+
+	path := filepath.Join(dir, "app.yaml")
+	if err := os.Symlink(filepath.Join("..data", "app.yaml"), path); err != nil {
+		t.Fatalf("symlink error = %v", err)
+	}
+
+	_, notified := newTestFileWatcher(t, path)
+
+	// atomic swap of ..data
+	if err := os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatalf("symlink error = %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("rename error = %v", err)
+	}
+
+	waitNotified(t, notified, 1)
+}
+
+func Test_fileWatcher_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeFile(t, path, "key: 1")
+
+	w, notified := newTestFileWatcher(t, path)
+	if err := w.Close(); err != nil {
+		t.Fatalf("fileWatcher.Close() error = %v", err)
+	}
+
+	writeFile(t, path, "key: 2")
+	waitNotified(t, notified, 0)
+}
+
+func Test_configureImpl_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeFile(t, path, "key: 1")
+
+	l := NewLoader()
+	c, err := l.Load(path)
+	if err != nil {
+		t.Fatalf("loader.Load() error = %v", err)
+	}
+
+	if err = c.Close(); err != nil {
+		t.Fatalf("configureImpl.Close() error = %v", err)
+	}
+
+	if len(l.m) != 0 {
+		t.Errorf("loader.m = %v, want empty", l.m)
+	}
+
+	// close twice
+	if err = c.Close(); err != nil {
+		t.Fatalf("configureImpl.Close() error = %v", err)
+	}
+}
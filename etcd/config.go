@@ -13,11 +13,17 @@ import (
 	clientv3 "go.etcd.io/etcd/client/v3"
 
 	"github.com/wwwangxc/gopkg/config"
+	"github.com/wwwangxc/gopkg/etcd/log"
 )
 
 var (
 	clientConfigMap = map[string]clientConfig{}
 	clientConfigRW  sync.RWMutex
+
+	// clientConfigSources the configure each client config loaded from
+	clientConfigSources = map[string]config.Configure{}
+
+	watchedConfigures sync.Map
 )
 
 func init() {
@@ -25,10 +31,24 @@ func init() {
 }
 
 // LoadConfig load config from file
+//
+// The clients already created will be reloaded when their config changed.
 func LoadConfig(path string) error {
 	return initAppConfig(path)
 }
 
+// LoadConfigure load config from configure
+//
+// The clients already created will be reloaded when their config changed.
+//
+// Use it to load config by a config.Loader, or from any config source. e.g.
+//
+//	configure, err := config.NewLoader(config.WithSearchPaths("./", "./conf")).Load("app.yaml")
+//	err = etcd.LoadConfigure(configure)
+func LoadConfigure(configure config.Configure) error {
+	return initAppConfigure(configure)
+}
+
 type appConfig struct {
 	Client struct {
 		ETCDCfg etcdConfig     `yaml:"etcd"`
@@ -42,32 +62,60 @@ func initAppConfig(path string) error {
 		return err
 	}
 
-	c, err := loadAppConfig(path)
+	configure, err := config.Load(path)
 	if err != nil {
 		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
-	c.registerClientConfig()
-	return nil
+	return initAppConfigure(configure)
 }
 
-func loadAppConfig(path string) (*appConfig, error) {
-	configure, err := config.Load(path)
+func initAppConfigure(configure config.Configure) error {
+	c, err := loadAppConfig(configure)
 	if err != nil {
-		return &appConfig{}, nil
+		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
+	c.registerClientConfig(configure)
+	reloadETCDClients()
+	watchAppConfig(configure)
+	return nil
+}
+
+func loadAppConfig(configure config.Configure) (*appConfig, error) {
 	c := &appConfig{}
-	if err = configure.Unmarshal(c); err != nil {
+	if err := configure.Unmarshal(c); err != nil {
 		return nil, fmt.Errorf("config unmarshal fail. error:%v", err)
 	}
 
 	return c, nil
 }
 
-func (a *appConfig) registerClientConfig() {
+// watchAppConfig apply the client config to the clients when it changed
+//
+// The clients will be updated or replaced without restart, see
+// reloadETCDClients.
+func watchAppConfig(configure config.Configure) {
+	if _, watched := watchedConfigures.LoadOrStore(configure, struct{}{}); watched {
+		return
+	}
+
+	configure.OnChange("client", func(_, _ interface{}) {
+		c, err := loadAppConfig(configure)
+		if err != nil {
+			log.Errorf("config reload fail. error:%v", err)
+			return
+		}
+
+		c.registerClientConfig(configure)
+		reloadETCDClients()
+	})
+}
+
+func (a *appConfig) registerClientConfig(configure config.Configure) {
 	defaultTimeout := defaultClientConfig("").Timeout
 
+	configs := make([]clientConfig, 0, len(a.Client.Service))
 	for _, v := range a.Client.Service {
 		if v.Timeout < 1 {
 			v.Timeout = a.Client.ETCDCfg.Timeout
@@ -93,8 +141,10 @@ func (a *appConfig) registerClientConfig() {
 		v.Username = v.getUsername()
 		v.Password = v.getPassword()
 
-		registerClientConfig(v)
+		configs = append(configs, v)
 	}
+
+	registerClientConfigs(configure, configs)
 }
 
 type etcdConfig struct {
@@ -202,10 +252,29 @@ func (s *clientConfig) tlsConfig() (*tls.Config, error) {
 	return tlsInfo.ClientConfig()
 }
 
-func registerClientConfig(c clientConfig) {
+// registerClientConfigs replace the client configs loaded from configure
+//
+// The clients removed from configure fall back to the default config, and
+// the ones loaded from the other configures are kept.
+func registerClientConfigs(configure config.Configure, configs []clientConfig) {
 	clientConfigRW.Lock()
 	defer clientConfigRW.Unlock()
-	clientConfigMap[c.Name] = c
+
+	names := make(map[string]struct{}, len(configs))
+	for _, c := range configs {
+		clientConfigMap[c.Name] = c
+		clientConfigSources[c.Name] = configure
+		names[c.Name] = struct{}{}
+	}
+
+	for name, source := range clientConfigSources {
+		if _, exist := names[name]; exist || source != configure {
+			continue
+		}
+
+		delete(clientConfigMap, name)
+		delete(clientConfigSources, name)
+	}
 }
 
 func getClientConfig(name string) clientConfig {
@@ -0,0 +1,248 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/wwwangxc/gopkg/config"
+	"github.com/wwwangxc/gopkg/config/unmarshaler"
+	"github.com/wwwangxc/gopkg/etcd/log"
+)
+
+// NewConfigSource new config source backed by etcd
+//
+// By default, the value of key will be unmarshaled as a yaml document.
+// With WithConfigSourcePrefix, every key under the prefix will be read as a
+// key/value tree, e.g. key /app/client/redis/timeout will be mapped to
+// client.redis.timeout with prefix /app/. Each value is unmarshaled by the
+// unmarshaler to keep its type, and falls back to the raw string.
+//
+// The source implements config.Watcher, config will be reloaded when the
+// key changed.
+//
+//	configure, err := config.LoadSource(etcd.NewConfigSource(etcd.NewClientProxy("etcd1"), "/app/config"))
+func NewConfigSource(cli ClientProxy, key string, opts ...ConfigSourceOption) config.Source {
+	s := &configSourceImpl{
+		cli:         cli,
+		key:         key,
+		unmarshaler: &unmarshaler.YAML{},
+		timeout:     time.Second,
+		retry:       time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+type configSourceImpl struct {
+	cli         ClientProxy
+	key         string
+	prefix      bool
+	unmarshaler unmarshaler.Unmarshaler
+	timeout     time.Duration
+	retry       time.Duration
+	cancel      context.CancelFunc
+
+	// revision the etcd revision of the last read, the watch starts after
+	// it so the changes between read and watch are not lost
+	revision int64
+}
+
+// Name source name
+func (c *configSourceImpl) Name() string {
+	if c.prefix {
+		return fmt.Sprintf("etcd:%s*", c.key)
+	}
+
+	return fmt.Sprintf("etcd:%s", c.key)
+}
+
+// Read read the config document or key/value tree from etcd
+func (c *configSourceImpl) Read() (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if c.prefix {
+		return c.readPrefix(ctx)
+	}
+
+	return c.readDocument(ctx)
+}
+
+func (c *configSourceImpl) readDocument(ctx context.Context) (map[string]interface{}, error) {
+	if c.unmarshaler == nil {
+		return nil, config.ErrUnmarshalerNotExist
+	}
+
+	rsp, err := c.cli.Get(ctx, c.key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setRevision(rsp)
+	if len(rsp.Kvs) == 0 {
+		return nil, fmt.Errorf("key %s not exist", c.key)
+	}
+
+	data := map[string]interface{}{}
+	if err = c.unmarshaler.Unmarshal(rsp.Kvs[0].Value, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal fail. err:%w", err)
+	}
+
+	return data, nil
+}
+
+func (c *configSourceImpl) readPrefix(ctx context.Context) (map[string]interface{}, error) {
+	prefix := c.prefixKey()
+	rsp, err := c.cli.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	c.setRevision(rsp)
+	data := map[string]interface{}{}
+	for _, kv := range rsp.Kvs {
+		k := strings.Trim(strings.TrimPrefix(string(kv.Key), prefix), "/")
+		if k == "" {
+			continue
+		}
+
+		setToMap(data, strings.Split(k, "/"), c.parseValue(kv.Value))
+	}
+
+	return data, nil
+}
+
+// parseValue unmarshal value by the unmarshaler to keep the value type,
+// return the raw string when unmarshal fail.
+func (c *configSourceImpl) parseValue(value []byte) interface{} {
+	if c.unmarshaler == nil {
+		return string(value)
+	}
+
+	var val interface{}
+	if err := c.unmarshaler.Unmarshal(value, &val); err != nil || val == nil {
+		return string(value)
+	}
+
+	return val
+}
+
+// Watch watch the key and notify when key changed
+//
+// The watch starts after the revision of the last read, and resumes after
+// the last event when re-watch. Will re-watch when the watch channel closed
+// until the source closed.
+func (c *configSourceImpl) Watch(notify func()) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	key := c.key
+	var opts []clientv3.OpOption
+	if c.prefix {
+		key = c.prefixKey()
+		opts = append(opts, clientv3.WithPrefix())
+	}
+
+	go func() {
+		var rev int64
+		for {
+			// the changes before the last read are included in it
+			if r := atomic.LoadInt64(&c.revision); r > rev {
+				rev = r
+			}
+
+			watchOpts := opts
+			if rev > 0 {
+				watchOpts = append(watchOpts[:len(watchOpts):len(watchOpts)], clientv3.WithRev(rev+1))
+			}
+
+			ch, err := c.cli.Watch(clientv3.WithRequireLeader(ctx), key, watchOpts...)
+			if err != nil {
+				log.Errorf("config source watch fail. key:%s err:%v", c.key, err)
+			} else {
+				for rsp := range ch {
+					if rsp.CompactRevision > 0 {
+						// the changes have been compacted, notify to re-read the
+						// latest and resume from the compacted revision
+						log.Errorf("config source watch compacted. key:%s revision:%d", c.key, rsp.CompactRevision)
+						rev = rsp.CompactRevision - 1
+						notify()
+						continue
+					}
+
+					if err := rsp.Err(); err != nil {
+						log.Errorf("config source watch error. key:%s err:%v", c.key, err)
+						continue
+					}
+
+					for _, ev := range rsp.Events {
+						if ev.Kv != nil && ev.Kv.ModRevision > rev {
+							rev = ev.Kv.ModRevision
+						}
+					}
+
+					if len(rsp.Events) > 0 {
+						notify()
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.retry):
+			}
+		}
+	}()
+
+	return nil
+}
+
+// prefixKey returns the key ends with "/", so that the sibling keys with
+// the same prefix are not matched, e.g. /app/config2 for /app/config
+func (c *configSourceImpl) prefixKey() string {
+	if strings.HasSuffix(c.key, "/") {
+		return c.key
+	}
+
+	return c.key + "/"
+}
+
+func (c *configSourceImpl) setRevision(rsp *clientv3.GetResponse) {
+	if rsp != nil && rsp.Header != nil {
+		atomic.StoreInt64(&c.revision, rsp.Header.Revision)
+	}
+}
+
+// Close stop watching
+func (c *configSourceImpl) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	return nil
+}
+
+func setToMap(m map[string]interface{}, subkeys []string, val interface{}) {
+	if len(subkeys) == 1 {
+		m[subkeys[0]] = val
+		return
+	}
+
+	sub, ok := m[subkeys[0]].(map[string]interface{})
+	if !ok {
+		sub = map[string]interface{}{}
+		m[subkeys[0]] = sub
+	}
+
+	setToMap(sub, subkeys[1:], val)
+}
@@ -0,0 +1,143 @@
+package etcd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func Test_configSourceImpl_Read(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ConfigSourceOption
+		kvs     []*mvccpb.KeyValue
+		wantKey string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "unmarshaler not exist",
+			opts:    []ConfigSourceOption{WithConfigSourceUnmarshaler("not exist")},
+			wantErr: true,
+		},
+		{
+			name:    "key not exist",
+			wantErr: true,
+		},
+		{
+			name: "unmarshal fail",
+			kvs: []*mvccpb.KeyValue{
+				{Key: []byte("/app/config"), Value: []byte("asdf")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "document",
+			kvs: []*mvccpb.KeyValue{
+				{Key: []byte("/app/config"), Value: []byte("client:\n  redis:\n    timeout: 2000\n")},
+			},
+			want: map[string]interface{}{
+				"client": map[string]interface{}{
+					"redis": map[string]interface{}{
+						"timeout": 2000,
+					},
+				},
+			},
+		},
+		{
+			name:    "prefix",
+			opts:    []ConfigSourceOption{WithConfigSourcePrefix()},
+			wantKey: "/app/config/",
+			kvs: []*mvccpb.KeyValue{
+				{Key: []byte("/app/config/client/redis/timeout"), Value: []byte("2000")},
+				{Key: []byte("/app/config/client/redis/dsn"), Value: []byte("redis://127.0.0.1:6379")},
+			},
+			want: map[string]interface{}{
+				"client": map[string]interface{}{
+					"redis": map[string]interface{}{
+						"timeout": 2000,
+						"dsn":     "redis://127.0.0.1:6379",
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var key string
+			var cli *clientProxyImpl
+			patches := gomonkey.ApplyMethod(reflect.TypeOf(cli), "Get",
+				func(_ *clientProxyImpl, _ context.Context, k string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+					key = k
+					return &clientv3.GetResponse{
+						Header: &etcdserverpb.ResponseHeader{Revision: 10},
+						Kvs:    tt.kvs,
+					}, nil
+				})
+			defer patches.Reset()
+
+			source := NewConfigSource(NewClientProxy("etcd1"), "/app/config", tt.opts...).(*configSourceImpl)
+			got, err := source.Read()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("configSourceImpl.Read() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantKey == "" {
+				tt.wantKey = "/app/config"
+			}
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, int64(10), source.revision)
+		})
+	}
+}
+
+func Test_configSourceImpl_Watch(t *testing.T) {
+	ch := make(chan clientv3.WatchResponse, 1)
+	watched := make(chan clientv3.Op, 2)
+
+	var cli *clientProxyImpl
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(cli), "Watch",
+		func(_ *clientProxyImpl, _ context.Context, key string, opts ...clientv3.OpOption) (clientv3.WatchChan, error) {
+			watched <- clientv3.OpGet(key, opts...)
+			return ch, nil
+		})
+	defer patches.Reset()
+
+	notified := make(chan struct{}, 1)
+	source := NewConfigSource(NewClientProxy("etcd1"), "/app/config", WithConfigSourcePrefix()).(*configSourceImpl)
+	source.retry = time.Millisecond
+	source.revision = 10
+	assert.Nil(t, source.Watch(func() { notified <- struct{}{} }))
+	defer source.Close()
+
+	// watch the prefix after the read revision
+	op := <-watched
+	assert.Equal(t, "/app/config/", string(op.KeyBytes()))
+	assert.NotEmpty(t, op.RangeBytes())
+	assert.Equal(t, int64(11), op.Rev())
+
+	ch <- clientv3.WatchResponse{Events: []*clientv3.Event{{Kv: &mvccpb.KeyValue{ModRevision: 12}}}}
+
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Errorf("configSourceImpl.Watch() not notified")
+	}
+
+	// resume after the last event
+	close(ch)
+	op = <-watched
+	assert.Equal(t, int64(13), op.Rev())
+}
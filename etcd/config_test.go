@@ -1,9 +1,15 @@
 package etcd
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
 func TestInit(t *testing.T) {
@@ -17,3 +23,91 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, "/usr/local/etcd_conf/cert.pem", e1.TLSCertPath)
 	assert.Equal(t, "/usr/local/etcd_conf/cacert.pem", e1.CACertPath)
 }
+
+func TestReload(t *testing.T) {
+	t.Cleanup(func() {
+		cliPoolRW.Lock()
+		if cli, ok := cliPool["etcd_reload"]; ok {
+			cli.Close()
+		}
+		delete(cliPool, "etcd_reload")
+		delete(cliConfigs, "etcd_reload")
+		delete(cliOptions, "etcd_reload")
+		cliPoolRW.Unlock()
+
+		clientConfigRW.Lock()
+		delete(clientConfigMap, "etcd_reload")
+		clientConfigRW.Unlock()
+	})
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeAppConfig := func(dsn string, timeout int) {
+		content := fmt.Sprintf("client:\n  service:\n    - name: etcd_reload\n      dsn: %s\n      timeout: %d\n", dsn, timeout)
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	writeAppConfig("127.0.0.1:23790", 1000)
+	assert.Nil(t, LoadConfig(path))
+
+	cli, err := getETCDClient("etcd_reload")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.1:23790"}, cli.Endpoints())
+
+	// update endpoints in place
+	writeAppConfig("127.0.0.1:23791,127.0.0.1:23792", 1000)
+	assert.Eventually(t, func() bool {
+		return reflect.DeepEqual(cli.Endpoints(), []string{"127.0.0.1:23791", "127.0.0.1:23792"})
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// replace when other config changed
+	writeAppConfig("127.0.0.1:23791,127.0.0.1:23792", 2000)
+	assert.Eventually(t, func() bool {
+		newCli, _ := getETCDClient("etcd_reload")
+		return newCli != cli
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// replace when load another config
+	path = filepath.Join(t.TempDir(), "app.yaml")
+	writeAppConfig("127.0.0.1:23791,127.0.0.1:23792", 3000)
+	assert.Nil(t, LoadConfig(path))
+
+	cliPoolRW.RLock()
+	assert.Equal(t, 3000, cliConfigs["etcd_reload"].Timeout)
+	cliPoolRW.RUnlock()
+}
+
+func TestReload_removeService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := "client:\n  service:\n    - name: etcd_removed\n      dsn: 127.0.0.1:23790\n      timeout: 3000\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	assert.Nil(t, LoadConfig(path))
+	assert.Equal(t, 3000, getClientConfig("etcd_removed").Timeout)
+
+	// the removed client falls back to the default config
+	assert.Nil(t, ioutil.WriteFile(path, []byte("client:\n  service: []\n"), 0644))
+	assert.Eventually(t, func() bool {
+		clientConfigRW.RLock()
+		defer clientConfigRW.RUnlock()
+		_, exist := clientConfigMap["etcd_removed"]
+		return !exist
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, defaultClientConfig("etcd_removed"), getClientConfig("etcd_removed"))
+
+	// the clients loaded from the other configures are kept
+	assert.Equal(t, "username", getClientConfig("etcd1").Username)
+}
+
+func Test_drainETCDClient(t *testing.T) {
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{"127.0.0.1:23790"}})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// the replaced client is closed after the delay
+	go drainETCDClient(cli, 10*time.Millisecond)
+	assert.Nil(t, cli.Ctx().Err())
+	assert.Eventually(t, func() bool {
+		return cli.Ctx().Err() != nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -6,7 +6,7 @@ require (
 	github.com/agiledragon/gomonkey v2.0.2+incompatible
 	github.com/golang/mock v1.6.0
 	github.com/stretchr/testify v1.8.0
-	github.com/wwwangxc/gopkg/config v0.1.0
+	github.com/wwwangxc/gopkg/config v0.2.0
 	go.etcd.io/etcd/api/v3 v3.5.4
 	go.etcd.io/etcd/client/pkg/v3 v3.5.4
 	go.etcd.io/etcd/client/v3 v3.5.4
@@ -20,6 +20,8 @@ require (
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/hashicorp/hcl v1.0.0 // indirect
+	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/spf13/cast v1.4.1 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
@@ -31,5 +33,9 @@ require (
 	google.golang.org/genproto v0.0.0-20210602131652-f16073e35f0c // indirect
 	google.golang.org/grpc v1.41.0 // indirect
 	google.golang.org/protobuf v1.26.0 // indirect
+	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// keep until config v0.2.0 is tagged and published, see README.md#development
+replace github.com/wwwangxc/gopkg/config => ../config
@@ -1,5 +1,11 @@
 package etcd
 
+import (
+	"time"
+
+	"github.com/wwwangxc/gopkg/config/unmarshaler"
+)
+
 // ClientOption etcd client proxy option
 type ClientOption func(*clientConfig)
 
@@ -47,3 +53,31 @@ func WithCACertPath(caCertPath string) ClientOption {
 		cc.CACertPath = caCertPath
 	}
 }
+
+// ConfigSourceOption etcd config source option
+type ConfigSourceOption func(*configSourceImpl)
+
+// WithConfigSourceUnmarshaler set unmarshaler of config source
+//
+// default yaml
+func WithConfigSourceUnmarshaler(name string) ConfigSourceOption {
+	return func(c *configSourceImpl) {
+		c.unmarshaler = unmarshaler.Get(name)
+	}
+}
+
+// WithConfigSourcePrefix read all keys under the key prefix as a key/value tree
+func WithConfigSourcePrefix() ConfigSourceOption {
+	return func(c *configSourceImpl) {
+		c.prefix = true
+	}
+}
+
+// WithConfigSourceTimeout set read timeout of config source
+//
+// default 1000 millisecond
+func WithConfigSourceTimeout(timeout time.Duration) ConfigSourceOption {
+	return func(c *configSourceImpl) {
+		c.timeout = timeout
+	}
+}
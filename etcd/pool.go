@@ -1,14 +1,23 @@
 package etcd
 
 import (
+	"reflect"
 	"sync"
+	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/wwwangxc/gopkg/etcd/log"
 )
 
+// drainDelay the replaced client will be closed after the delay
+const drainDelay = 10 * time.Second
+
 var (
-	cliPool   = map[string]*clientv3.Client{}
-	cliPoolRW sync.RWMutex
+	cliPool    = map[string]*clientv3.Client{}
+	cliConfigs = map[string]clientConfig{}
+	cliOptions = map[string][]ClientOption{}
+	cliPoolRW  sync.RWMutex
 )
 
 func getETCDClient(name string, opts ...ClientOption) (*clientv3.Client, error) {
@@ -24,7 +33,23 @@ func getETCDClient(name string, opts ...ClientOption) (*clientv3.Client, error)
 		opt(&cfg)
 	}
 
-	return newETCDClient(cfg)
+	cli, err := newETCDClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	setClientOptions(name, opts)
+	return cli, nil
+}
+
+// setClientOptions keep the options of client, which will be applied on reload
+func setClientOptions(name string, opts []ClientOption) {
+	cliPoolRW.Lock()
+	defer cliPoolRW.Unlock()
+
+	if _, exist := cliOptions[name]; !exist {
+		cliOptions[name] = opts
+	}
 }
 
 func newETCDClient(cfg clientConfig) (*clientv3.Client, error) {
@@ -47,5 +72,69 @@ func newETCDClient(cfg clientConfig) (*clientv3.Client, error) {
 	}
 
 	cliPool[cfg.Name] = cli
+	cliConfigs[cfg.Name] = cfg
 	return cli, nil
 }
+
+// reloadETCDClients apply the changed config to the clients
+//
+// The endpoints will be updated in place when only endpoints changed.
+// Otherwise the client will be replaced, and the replaced one will be
+// closed after drainDelay, so the in-flight requests will not be
+// interrupted. The watches, leases and lock sessions created by the
+// replaced client should be re-created after it closed.
+func reloadETCDClients() {
+	cliPoolRW.Lock()
+	var replaced []*clientv3.Client
+	for name, cli := range cliPool {
+		cfg := getClientConfig(name)
+		for _, opt := range cliOptions[name] {
+			opt(&cfg)
+		}
+
+		old := cliConfigs[name]
+		if reflect.DeepEqual(cfg, old) {
+			continue
+		}
+
+		if onlyEndpointsChanged(old, cfg) {
+			cli.SetEndpoints(cfg.Endpoints...)
+			cliConfigs[name] = cfg
+			continue
+		}
+
+		clientConfig, err := cfg.clientConfig()
+		if err != nil {
+			log.Errorf("client config invalid, keep the last client. name:%s error:%v", name, err)
+			continue
+		}
+
+		newCli, err := clientv3.New(*clientConfig)
+		if err != nil {
+			log.Errorf("client renew fail, keep the last client. name:%s error:%v", name, err)
+			continue
+		}
+
+		cliPool[name] = newCli
+		cliConfigs[name] = cfg
+		replaced = append(replaced, cli)
+	}
+	cliPoolRW.Unlock()
+
+	for _, cli := range replaced {
+		go drainETCDClient(cli, drainDelay)
+	}
+}
+
+func onlyEndpointsChanged(old, new clientConfig) bool {
+	old.DSN, old.Endpoints = new.DSN, new.Endpoints
+	return reflect.DeepEqual(old, new)
+}
+
+// drainETCDClient close the client after the delay
+func drainETCDClient(cli *clientv3.Client, delay time.Duration) {
+	time.Sleep(delay)
+	if err := cli.Close(); err != nil {
+		log.Errorf("close replaced client fail. error:%v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package httpx
 
 import (
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -8,9 +9,22 @@ import (
 	"resty.dev/v3"
 )
 
+const (
+	// drainDelay the replaced client will be closed after its timeout, and
+	// no shorter than the delay, so the in-flight requests will not be
+	// interrupted
+	drainDelay = time.Second
+
+	// drainTimeout the replaced client without timeout will be closed after
+	// the timeout
+	drainTimeout = 30 * time.Second
+)
+
 var (
-	cliPoolRW sync.RWMutex
-	cliPool   = map[string]*resty.Client{}
+	cliPoolRW  sync.RWMutex
+	cliPool    = map[string]*resty.Client{}
+	cliConfigs = map[string]clientConfig{}
+	cliOptions = map[string][]ClientOption{}
 )
 
 func getOrCreateClient(name string, opts ...ClientOption) *resty.Client {
@@ -35,12 +49,21 @@ func getOrCreateClient(name string, opts ...ClientOption) *resty.Client {
 	}
 
 	cliPool[name] = cli
+	cliConfigs[name] = getClientConfig(name)
+	cliOptions[name] = opts
 	return cli
 }
 
 func newClient(name string, opts ...ClientOption) *resty.Client {
-	config := getClientConfig(name)
+	cli, err := buildClient(getClientConfig(name), opts...)
+	if err != nil {
+		panic(err)
+	}
 
+	return cli
+}
+
+func buildClient(config clientConfig, opts ...ClientOption) (*resty.Client, error) {
 	cli := resty.NewWithClient(config.toHTTPClient()).
 		SetHeaders(config.Header).
 		SetTimeout(time.Duration(config.Timeout) * time.Millisecond)
@@ -48,8 +71,9 @@ func newClient(name string, opts ...ClientOption) *resty.Client {
 	if config.DSN != "" {
 		rr, err := resty.NewRoundRobin(strings.Split(config.DSN, ",")...)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
+
 		cli = cli.SetLoadBalancer(rr)
 	}
 
@@ -57,5 +81,61 @@ func newClient(name string, opts ...ClientOption) *resty.Client {
 		opt(cli)
 	}
 
-	return cli
+	return cli, nil
+}
+
+// reloadClients replace the clients whose config changed
+//
+// The new requests will be sent by the new client, and the replaced client
+// will be closed after its timeout, so the in-flight requests will not be
+// interrupted.
+func reloadClients() {
+	cliPoolRW.Lock()
+	var replaced []*resty.Client
+	for name, cli := range cliPool {
+		config := getClientConfig(name)
+		if reflect.DeepEqual(config, cliConfigs[name]) {
+			continue
+		}
+
+		newCli, err := buildClient(config, cliOptions[name]...)
+		if err != nil {
+			logErrorf("client rebuild fail, keep the last client. name:%s error:%v", name, err)
+			continue
+		}
+
+		cliPool[name] = newCli
+		cliConfigs[name] = config
+		replaced = append(replaced, cli)
+	}
+	cliPoolRW.Unlock()
+
+	for _, cli := range replaced {
+		go drainClient(cli, clientDrainDelay(cli))
+	}
+}
+
+// clientDrainDelay returns the delay to close the replaced client, which is
+// the timeout of client and no shorter than drainDelay
+func clientDrainDelay(cli *resty.Client) time.Duration {
+	timeout := cli.Timeout()
+	if timeout <= 0 {
+		return drainTimeout
+	}
+
+	if timeout < drainDelay {
+		return drainDelay
+	}
+
+	return timeout
+}
+
+// drainClient close the client and its idle connections after the delay
+func drainClient(cli *resty.Client, delay time.Duration) {
+	time.Sleep(delay)
+	if err := cli.Close(); err != nil {
+		logErrorf("close replaced client fail. error:%v", err)
+	}
+
+	cli.Client().CloseIdleConnections()
 }
@@ -14,6 +14,11 @@ import (
 var (
 	clientConfigRW  sync.RWMutex
 	clientConfigMap = map[string]clientConfig{}
+
+	// clientConfigSources the configure each client config loaded from
+	clientConfigSources = map[string]config.Configure{}
+
+	watchedConfigures sync.Map
 )
 
 func init() {
@@ -21,39 +26,80 @@ func init() {
 }
 
 // LoadConfig load config from file
+//
+// The clients already created will be reloaded when their config changed.
 func LoadConfig(path string) error {
 	return initAppConfig(path)
 }
 
+// LoadConfigure load config from configure
+//
+// The clients already created will be reloaded when their config changed.
+//
+// Use it to load config by a config.Loader, or from any config source. e.g.
+//
+//	configure, err := config.NewLoader(config.WithSearchPaths("./", "./conf")).Load("app.yaml")
+//	err = httpx.LoadConfigure(configure)
+func LoadConfigure(configure config.Configure) error {
+	return initAppConfigure(configure)
+}
+
 func initAppConfig(path string) error {
 	_, err := os.Stat(path)
 	if err != nil {
 		return err
 	}
 
-	c, err := loadAppConfig(path)
+	configure, err := config.Load(path)
 	if err != nil {
 		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
-	c.registerClientConfig()
-	return nil
+	return initAppConfigure(configure)
 }
 
-func loadAppConfig(path string) (*appConfig, error) {
-	configure, err := config.Load(path)
+func initAppConfigure(configure config.Configure) error {
+	c, err := loadAppConfig(configure)
 	if err != nil {
-		return &appConfig{}, nil
+		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
+	c.registerClientConfig(configure)
+	reloadClients()
+	watchAppConfig(configure)
+	return nil
+}
+
+func loadAppConfig(configure config.Configure) (*appConfig, error) {
 	c := &appConfig{}
-	if err = configure.Unmarshal(c); err != nil {
+	if err := configure.Unmarshal(c); err != nil {
 		return nil, fmt.Errorf("config unmarshal fail. error:%v", err)
 	}
 
 	return c, nil
 }
 
+// watchAppConfig apply the client config to the clients when it changed
+//
+// The clients whose config changed will be replaced without restart, see
+// reloadClients.
+func watchAppConfig(configure config.Configure) {
+	if _, watched := watchedConfigures.LoadOrStore(configure, struct{}{}); watched {
+		return
+	}
+
+	configure.OnChange("client", func(_, _ any) {
+		c, err := loadAppConfig(configure)
+		if err != nil {
+			logErrorf("config reload fail. error:%v", err)
+			return
+		}
+
+		c.registerClientConfig(configure)
+		reloadClients()
+	})
+}
+
 type appConfig struct {
 	Client struct {
 		HTTPCfg httpConfig     `yaml:"http"`
@@ -61,9 +107,10 @@ type appConfig struct {
 	} `yaml:"client"`
 }
 
-func (a *appConfig) registerClientConfig() {
+func (a *appConfig) registerClientConfig(configure config.Configure) {
 	httpConfigDefault := defaultHTTPConfig()
 
+	configs := make([]clientConfig, 0, len(a.Client.Service))
 	for _, v := range a.Client.Service {
 		if len(v.httpConfig.Header) == 0 {
 			v.httpConfig.Header = a.Client.HTTPCfg.Header
@@ -77,8 +124,10 @@ func (a *appConfig) registerClientConfig() {
 			v.httpConfig.TransportCfg = httpConfigDefault.TransportCfg
 		}
 
-		registerClientConfig(v)
+		configs = append(configs, v)
 	}
+
+	registerClientConfigs(configure, configs)
 }
 
 type clientConfig struct {
@@ -175,10 +224,29 @@ func defaultHTTPTransportConfig() httpTransportConfig {
 	}
 }
 
-func registerClientConfig(c clientConfig) {
+// registerClientConfigs replace the client configs loaded from configure
+//
+// The clients removed from configure fall back to the default config, and
+// the ones loaded from the other configures are kept.
+func registerClientConfigs(configure config.Configure, configs []clientConfig) {
 	clientConfigRW.Lock()
 	defer clientConfigRW.Unlock()
-	clientConfigMap[c.Name] = c
+
+	names := make(map[string]struct{}, len(configs))
+	for _, c := range configs {
+		clientConfigMap[c.Name] = c
+		clientConfigSources[c.Name] = configure
+		names[c.Name] = struct{}{}
+	}
+
+	for name, source := range clientConfigSources {
+		if _, exist := names[name]; exist || source != configure {
+			continue
+		}
+
+		delete(clientConfigMap, name)
+		delete(clientConfigSources, name)
+	}
 }
 
 func getClientConfig(name string) clientConfig {
@@ -1,11 +1,19 @@
 package httpx
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	c "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
+	"resty.dev/v3"
+
+	"github.com/wwwangxc/gopkg/config"
 )
 
 func TestInit(t *testing.T) {
@@ -131,3 +139,135 @@ func TestInit(t *testing.T) {
 		})
 	})
 }
+
+func TestReload(t *testing.T) {
+	t.Cleanup(func() {
+		cliPoolRW.Lock()
+		if cli, ok := cliPool["http_reload"]; ok {
+			cli.Close()
+		}
+		delete(cliPool, "http_reload")
+		delete(cliConfigs, "http_reload")
+		delete(cliOptions, "http_reload")
+		cliPoolRW.Unlock()
+
+		clientConfigRW.Lock()
+		delete(clientConfigMap, "http_reload")
+		clientConfigRW.Unlock()
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Version")))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeAppConfig := func(version string) {
+		content := fmt.Sprintf("client:\n  service:\n    - name: http_reload\n      dsn: %s\n      timeout: 1000\n      header:\n        X-Version: %s\n", server.URL, version)
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	version := func() string {
+		rsp, err := getOrCreateClient("http_reload").R().Get("/")
+		if err != nil {
+			return ""
+		}
+		return rsp.String()
+	}
+
+	c.Convey("Reload app config", t, func() {
+		writeAppConfig("v1")
+		assert.Nil(t, LoadConfig(path))
+
+		cli := getOrCreateClient("http_reload")
+		assert.Equal(t, "v1", version())
+
+		c.Convey("Replace client when config changed", func() {
+			writeAppConfig("v2")
+			assert.Eventually(t, func() bool {
+				return version() == "v2"
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.True(t, cli != getOrCreateClient("http_reload"))
+
+			// replace when load another config
+			path = filepath.Join(t.TempDir(), "app.yaml")
+			writeAppConfig("v3")
+			assert.Nil(t, LoadConfig(path))
+			assert.Equal(t, "v3", version())
+		})
+	})
+}
+
+func TestReload_removeService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := "client:\n  service:\n    - name: http_removed\n      dsn: http://127.0.0.1:8080\n      timeout: 1000\n"
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	assert.Nil(t, LoadConfig(path))
+	assert.Equal(t, int64(1000), getClientConfig("http_removed").Timeout)
+
+	// the removed client falls back to the default config
+	assert.Nil(t, os.WriteFile(path, []byte("client:\n  service: []\n"), 0644))
+	assert.Eventually(t, func() bool {
+		clientConfigRW.RLock()
+		defer clientConfigRW.RUnlock()
+		_, exist := clientConfigMap["http_removed"]
+		return !exist
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, defaultClientConfig("http_removed"), getClientConfig("http_removed"))
+
+	// the clients loaded from the other configures are kept
+	_, exist := clientConfigMap["http1"]
+	assert.True(t, exist)
+}
+
+func Test_clientDrainDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{
+			name:    "timeout",
+			timeout: 3 * time.Second,
+			want:    3 * time.Second,
+		},
+		{
+			name:    "shorter than drain delay",
+			timeout: 100 * time.Millisecond,
+			want:    drainDelay,
+		},
+		{
+			name:    "no timeout",
+			timeout: 0,
+			want:    drainTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := resty.New().SetTimeout(tt.timeout)
+			defer cli.Close()
+
+			assert.Equal(t, tt.want, clientDrainDelay(cli))
+		})
+	}
+}
+
+func TestLoadConfigure(t *testing.T) {
+	c.Convey("Load config from configure", t, func() {
+		path := filepath.Join(t.TempDir(), "app.yaml")
+		content := "client:\n  service:\n    - name: http_configure\n      dsn: https://httpbin.org\n      timeout: 2000\n"
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+
+		loader := config.NewLoader()
+		defer loader.Close()
+
+		configure, err := loader.Load(path)
+		assert.Nil(t, err)
+		assert.Nil(t, LoadConfigure(configure))
+
+		cfg, ok := clientConfigMap["http_configure"]
+		assert.True(t, ok)
+		assert.Equal(t, int64(2000), cfg.Timeout)
+	})
+}
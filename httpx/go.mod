@@ -6,7 +6,7 @@ require (
 	github.com/agiledragon/gomonkey/v2 v2.14.0
 	github.com/smartystreets/goconvey v1.8.1
 	github.com/stretchr/testify v1.11.1
-	github.com/wwwangxc/gopkg/config v0.1.1
+	github.com/wwwangxc/gopkg/config v0.2.0
 	github.com/wwwangxc/wheel v0.0.9
 	go.uber.org/mock v0.6.0
 	resty.dev/v3 v3.0.0-beta.6
@@ -17,11 +17,17 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
 	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/smarty/assertions v1.15.0 // indirect
 	github.com/spf13/cast v1.4.1 // indirect
 	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
+	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// keep until config v0.2.0 is tagged and published, see README.md#development
+replace github.com/wwwangxc/gopkg/config => ../config
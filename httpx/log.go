@@ -0,0 +1,20 @@
+package httpx
+
+import (
+	"fmt"
+	"log"
+)
+
+const (
+	packageName = "gopkg/httpx"
+
+	logStatusError = "[ERROR]"
+)
+
+func logErrorf(format string, args ...any) {
+	logf(logStatusError, format, args...)
+}
+
+func logf(logStatus, format string, args ...any) {
+	log.Printf("%s %s %s", packageName, logStatus, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,53 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+
+	"resty.dev/v3"
+)
+
+// RateLimiter limits the requests by key
+//
+// The redis.RateLimiterProxy of github.com/wwwangxc/gopkg/redis implements
+// it, so the quota of an upstream host is shared across the fleet.
+type RateLimiter interface {
+	// Wait until a request of the key allowed or the context canceled
+	Wait(ctx context.Context, key string) error
+}
+
+// RateLimitMiddleware returns the request middleware which waits for the
+// limiter per upstream host, the host is the key of limiter.
+//
+// It must be placed after [resty.PrepareRequestMiddleware], which resolves
+// the upstream host. Each retry is limited too.
+//
+//	httpx.C.WithRequestMiddlewares(
+//		resty.PrepareRequestMiddleware,
+//		httpx.RateLimitMiddleware(limiter),
+//	)
+func RateLimitMiddleware(limiter RateLimiter) resty.RequestMiddleware {
+	return func(_ *resty.Client, r *resty.Request) error {
+		if limiter == nil {
+			return nil
+		}
+
+		if r.RawRequest == nil {
+			return errors.New("httpx: rate limit middleware must be placed after resty.PrepareRequestMiddleware")
+		}
+
+		return limiter.Wait(r.Context(), r.RawRequest.URL.Host)
+	}
+}
+
+// WithRateLimiter limit the requests per upstream host by the limiter
+//
+// It overrides the request middlewares, use [C.WithRequestMiddlewares] with
+// [RateLimitMiddleware] to combine with the others.
+func (*clientOption) WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(c *resty.Client) {
+		if c != nil {
+			c.SetRequestMiddlewares(resty.PrepareRequestMiddleware, RateLimitMiddleware(limiter))
+		}
+	}
+}
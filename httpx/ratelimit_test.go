@@ -0,0 +1,80 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"resty.dev/v3"
+)
+
+type fakeRateLimiter struct {
+	keys []string
+	err  error
+}
+
+func (f *fakeRateLimiter) Wait(_ context.Context, key string) error {
+	f.keys = append(f.keys, key)
+	return f.err
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	assert.Nil(t, err)
+
+	limitErr := errors.New("rate limited")
+	tests := []struct {
+		name     string
+		limiter  *fakeRateLimiter
+		opt      func(limiter RateLimiter) ClientOption
+		wantKeys []string
+		wantErr  error
+	}{
+		{
+			name:     "allowed",
+			limiter:  &fakeRateLimiter{},
+			opt:      C.WithRateLimiter,
+			wantKeys: []string{u.Host},
+		},
+		{
+			name:     "limited",
+			limiter:  &fakeRateLimiter{err: limitErr},
+			opt:      C.WithRateLimiter,
+			wantKeys: []string{u.Host},
+			wantErr:  limitErr,
+		},
+		{
+			name:    "before prepare",
+			limiter: &fakeRateLimiter{},
+			opt: func(limiter RateLimiter) ClientOption {
+				return C.WithRequestMiddlewares(RateLimitMiddleware(limiter), resty.PrepareRequestMiddleware)
+			},
+			wantErr: errors.New("httpx: rate limit middleware must be placed after resty.PrepareRequestMiddleware"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := resty.New()
+			defer cli.Close()
+
+			tt.opt(tt.limiter)(cli)
+			_, err := cli.R().Get(srv.URL + "/v1/users")
+			if tt.wantErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			}
+
+			assert.Equal(t, tt.wantKeys, tt.limiter.keys)
+		})
+	}
+}
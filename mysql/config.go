@@ -11,6 +11,11 @@ import (
 var (
 	serviceConfigMap = map[string]serviceConfig{}
 	serviceConfigRW  sync.RWMutex
+
+	// serviceConfigSources the configure each service config loaded from
+	serviceConfigSources = map[string]config.Configure{}
+
+	watchedConfigures sync.Map
 )
 
 func init() {
@@ -22,6 +27,16 @@ func LoadConfig(path string) error {
 	return initAppConfig(path)
 }
 
+// LoadConfigure load config from configure
+//
+// Use it to load config by a config.Loader, or from any config source. e.g.
+//
+//	configure, err := config.NewLoader(config.WithSearchPaths("./", "./conf")).Load("app.yaml")
+//	err = mysql.LoadConfigure(configure)
+func LoadConfigure(configure config.Configure) error {
+	return initAppConfigure(configure)
+}
+
 type appConfig struct {
 	Client struct {
 		MySQLConfig mysqlConfig     `yaml:"mysql"`
@@ -73,36 +88,77 @@ func initAppConfig(path string) error {
 		return err
 	}
 
-	c, err := loadAppConfig(path)
+	configure, err := config.Load(path)
 	if err != nil {
 		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
-	for _, v := range c.getServiceConfigs() {
-		registerServiceConfig(v)
-	}
-
-	return nil
+	return initAppConfigure(configure)
 }
 
-func loadAppConfig(path string) (*appConfig, error) {
-	configure, err := config.Load(path)
+func initAppConfigure(configure config.Configure) error {
+	c, err := loadAppConfig(configure)
 	if err != nil {
-		return &appConfig{}, nil
+		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
+	registerServiceConfigs(configure, c.getServiceConfigs())
+	watchAppConfig(configure)
+	return nil
+}
+
+func loadAppConfig(configure config.Configure) (*appConfig, error) {
 	c := &appConfig{}
-	if err = configure.Unmarshal(c); err != nil {
+	if err := configure.Unmarshal(c); err != nil {
 		return nil, fmt.Errorf("config unmarshal fail. error:%v", err)
 	}
 
 	return c, nil
 }
 
-func registerServiceConfig(c serviceConfig) {
+// watchAppConfig apply the client config to the dbs when it changed
+//
+// The dbs will be resized or replaced without restart, see reloadDBs.
+func watchAppConfig(configure config.Configure) {
+	if _, watched := watchedConfigures.LoadOrStore(configure, struct{}{}); watched {
+		return
+	}
+
+	configure.OnChange("client", func(_, _ interface{}) {
+		c, err := loadAppConfig(configure)
+		if err != nil {
+			logErrorf("config reload fail. error:%v", err)
+			return
+		}
+
+		registerServiceConfigs(configure, c.getServiceConfigs())
+		reloadDBs()
+	})
+}
+
+// registerServiceConfigs replace the service configs loaded from configure
+//
+// The services removed from configure fall back to the default config, and
+// the ones loaded from the other configures are kept.
+func registerServiceConfigs(configure config.Configure, configs []serviceConfig) {
 	serviceConfigRW.Lock()
 	defer serviceConfigRW.Unlock()
-	serviceConfigMap[c.Name] = c
+
+	names := make(map[string]struct{}, len(configs))
+	for _, c := range configs {
+		serviceConfigMap[c.Name] = c
+		serviceConfigSources[c.Name] = configure
+		names[c.Name] = struct{}{}
+	}
+
+	for name, source := range serviceConfigSources {
+		if _, exist := names[name]; exist || source != configure {
+			continue
+		}
+
+		delete(serviceConfigMap, name)
+		delete(serviceConfigSources, name)
+	}
 }
 
 func getServiceConfig(name string) serviceConfig {
@@ -1,7 +1,11 @@
 package mysql
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +27,69 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, 222, cli2.MaxOpen)
 	assert.Equal(t, 333, cli2.MaxIdleTime)
 }
+
+func TestReload(t *testing.T) {
+	drainDelay = 10 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeAppConfig := func(dsn string, maxOpen int) {
+		content := fmt.Sprintf("client:\n  service:\n    - name: mysql_reload\n      dsn: %s\n      max_open: %d\n", dsn, maxOpen)
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	writeAppConfig("root:root@tcp(127.0.0.1:3306)/db1", 10)
+	assert.Nil(t, LoadConfig(path))
+
+	db, err := getDB("mysql_reload", WithMaxIdle(5))
+	assert.Nil(t, err)
+	assert.Equal(t, 10, db.Stats().MaxOpenConnections)
+
+	// resize in place
+	writeAppConfig("root:root@tcp(127.0.0.1:3306)/db1", 20)
+	assert.Eventually(t, func() bool {
+		return db.Stats().MaxOpenConnections == 20
+	}, 2*time.Second, 10*time.Millisecond)
+
+	newDB, err := getDB("mysql_reload")
+	assert.Nil(t, err)
+	assert.True(t, db == newDB)
+
+	// replace when dsn changed
+	writeAppConfig("root:root@tcp(127.0.0.1:3306)/db2", 20)
+	assert.Eventually(t, func() bool {
+		newDB, _ = getDB("mysql_reload")
+		return db != newDB
+	}, 2*time.Second, 10*time.Millisecond)
+
+	dbRW.RLock()
+	assert.Equal(t, "root:root@tcp(127.0.0.1:3306)/db2", dbConfigs["mysql_reload"].DSN)
+	assert.Equal(t, 5, dbConfigs["mysql_reload"].MaxIdle)
+	dbRW.RUnlock()
+
+	// the replaced db is closed
+	assert.Eventually(t, func() bool {
+		return db.Ping() != nil && db.Ping().Error() == "sql: database is closed"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReload_removeService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := "client:\n  service:\n    - name: mysql_removed\n      dsn: root:root@tcp(127.0.0.1:3306)/db1\n      max_open: 10\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	assert.Nil(t, LoadConfig(path))
+	assert.Equal(t, 10, getServiceConfig("mysql_removed").MaxOpen)
+
+	// the removed service falls back to the default config
+	assert.Nil(t, ioutil.WriteFile(path, []byte("client:\n  service: []\n"), 0644))
+	assert.Eventually(t, func() bool {
+		serviceConfigRW.RLock()
+		defer serviceConfigRW.RUnlock()
+		_, exist := serviceConfigMap["mysql_removed"]
+		return !exist
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "", getServiceConfig("mysql_removed").DSN)
+
+	// the services loaded from the other configures are kept
+	assert.Equal(t, 11, getServiceConfig("client1").MaxIdle)
+}
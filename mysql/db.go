@@ -8,8 +8,14 @@ import (
 )
 
 var (
-	dbs  = map[string]*sql.DB{}
-	dbRW sync.RWMutex
+	dbs       = map[string]*sql.DB{}
+	dbConfigs = map[string]serviceConfig{}
+	dbOptions = map[string][]Option{}
+	dbRW      sync.RWMutex
+
+	// drainDelay the replaced db will be closed after the delay, the
+	// queries started before closing will not be interrupted
+	drainDelay = time.Second
 )
 
 func getDB(name string, opts ...Option) (*sql.DB, error) {
@@ -25,7 +31,23 @@ func getDB(name string, opts ...Option) (*sql.DB, error) {
 		opt(&cfg)
 	}
 
-	return newDB(&cfg)
+	db, err := newDB(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	setDBOptions(name, opts)
+	return db, nil
+}
+
+// setDBOptions keep the options of db, which will be applied on reload
+func setDBOptions(name string, opts []Option) {
+	dbRW.Lock()
+	defer dbRW.Unlock()
+
+	if _, exist := dbOptions[name]; !exist {
+		dbOptions[name] = opts
+	}
 }
 
 func newDB(cfg *serviceConfig) (*sql.DB, error) {
@@ -42,6 +64,14 @@ func newDB(cfg *serviceConfig) (*sql.DB, error) {
 		return nil, fmt.Errorf("mysql open fail. error:%v", err)
 	}
 
+	setDBPool(db, cfg)
+
+	dbs[cfg.Name] = db
+	dbConfigs[cfg.Name] = *cfg
+	return db, nil
+}
+
+func setDBPool(db *sql.DB, cfg *serviceConfig) {
 	if cfg.MaxIdle > 0 {
 		db.SetMaxIdleConns(cfg.MaxIdle)
 	}
@@ -53,7 +83,57 @@ func newDB(cfg *serviceConfig) (*sql.DB, error) {
 	if cfg.MaxIdleTime > 0 {
 		db.SetConnMaxIdleTime(time.Duration(cfg.MaxIdleTime) * time.Millisecond)
 	}
+}
 
-	dbs[cfg.Name] = db
-	return db, nil
+// reloadDBs apply the changed config to the dbs
+//
+// The pool of db will be resized in place. When dsn changed, the db will be
+// replaced and the replaced one will be closed after drainDelay, so the
+// in-flight queries will not be interrupted.
+func reloadDBs() {
+	dbRW.Lock()
+	var replaced []*sql.DB
+	for name, db := range dbs {
+		cfg := getServiceConfig(name)
+		for _, opt := range dbOptions[name] {
+			opt(&cfg)
+		}
+
+		old := dbConfigs[name]
+		if cfg == old {
+			continue
+		}
+
+		if cfg.DSN == old.DSN {
+			setDBPool(db, &cfg)
+			dbConfigs[name] = cfg
+			continue
+		}
+
+		newDB, err := sql.Open("mysql", cfg.DSN)
+		if err != nil {
+			logErrorf("mysql reopen fail, keep the last db. name:%s error:%v", name, err)
+			continue
+		}
+
+		setDBPool(newDB, &cfg)
+		dbs[name] = newDB
+		dbConfigs[name] = cfg
+		replaced = append(replaced, db)
+	}
+	dbRW.Unlock()
+
+	for _, db := range replaced {
+		go drainDB(db)
+	}
+}
+
+// drainDB close the db after drainDelay
+//
+// Close waits for all queries that have started to finish.
+func drainDB(db *sql.DB) {
+	time.Sleep(drainDelay)
+	if err := db.Close(); err != nil {
+		logErrorf("close replaced db fail. error:%v", err)
+	}
 }
@@ -1,9 +1,15 @@
 package orm
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestInit(t *testing.T) {
@@ -52,3 +58,96 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, 22, cfg.MaxOpen)
 	assert.Equal(t, 33, cfg.MaxIdleTime)
 }
+
+func TestReload(t *testing.T) {
+	drainDelay = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	writeAppConfig := func(dsn string, maxOpen int, driver ...string) {
+		d := "sqlite"
+		if len(driver) > 0 {
+			d = driver[0]
+		}
+		content := fmt.Sprintf("client:\n  service:\n    - name: orm_reload\n      driver: %s\n      dsn: %s\n      max_open: %d\n", d, dsn, maxOpen)
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	type record struct {
+		Name string
+	}
+
+	dsn1, dsn2 := filepath.Join(dir, "db1.sqlite"), filepath.Join(dir, "db2.sqlite")
+	for _, dsn := range []string{dsn1, dsn2} {
+		db, err := gorm.Open(sqlite.Open(dsn))
+		assert.Nil(t, err)
+		assert.Nil(t, db.AutoMigrate(&record{}))
+		assert.Nil(t, db.Create(&record{Name: filepath.Base(dsn)}).Error)
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}
+
+	writeAppConfig(dsn1, 10)
+	assert.Nil(t, LoadConfig(path))
+
+	db, err := NewGORM("orm_reload")
+	assert.Nil(t, err)
+
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	assert.Equal(t, 10, sqlDB.Stats().MaxOpenConnections)
+
+	// resize in place
+	writeAppConfig(dsn1, 20)
+	assert.Eventually(t, func() bool {
+		return sqlDB.Stats().MaxOpenConnections == 20
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// swap the sql.DB when dsn changed, the *gorm.DB held keeps working
+	writeAppConfig(dsn2, 20)
+	assert.Eventually(t, func() bool {
+		r := &record{}
+		return db.First(r).Error == nil && r.Name == "db2.sqlite"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// the replaced sql.DB is closed
+	assert.Eventually(t, func() bool {
+		err := sqlDB.Ping()
+		return err != nil && err.Error() == "sql: database is closed"
+	}, time.Second, 10*time.Millisecond)
+
+	// keep the last db when driver changed
+	writeAppConfig(dsn1, 20, "mysql")
+	assert.Eventually(t, func() bool {
+		return getServiceConfig("orm_reload").Driver == "mysql"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	reloadGORMDBs()
+	gormDBMapRW.RLock()
+	assert.Equal(t, "sqlite", gormConfigs["orm_reload"].Driver)
+	assert.Equal(t, dsn2, gormConfigs["orm_reload"].DSN)
+	gormDBMapRW.RUnlock()
+
+	r := &record{}
+	assert.Nil(t, db.First(r).Error)
+	assert.Equal(t, "db2.sqlite", r.Name)
+}
+
+func TestReload_removeService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := "client:\n  service:\n    - name: orm_removed\n      driver: sqlite\n      dsn: db.sqlite\n      max_open: 10\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	assert.Nil(t, LoadConfig(path))
+	assert.Equal(t, "sqlite", getServiceConfig("orm_removed").Driver)
+
+	// the removed service falls back to the default config
+	assert.Nil(t, ioutil.WriteFile(path, []byte("client:\n  service: []\n"), 0644))
+	assert.Eventually(t, func() bool {
+		serviceConfigRW.RLock()
+		defer serviceConfigRW.RUnlock()
+		_, exist := serviceConfigMap["orm_removed"]
+		return !exist
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "mysql", getServiceConfig("orm_removed").Driver)
+}
@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// connPool swappable gorm conn pool
+//
+// The *gorm.DB returned by NewGORM is held by the caller, so the underlying
+// *sql.DB is swapped in place when config changed.
+type connPool struct {
+	db atomic.Value
+}
+
+// wrapConnPool replace the conn pool of db with the swappable conn pool
+//
+// Return nil when the conn pool is not *sql.DB, e.g. PrepareStmt enabled.
+func wrapConnPool(db *gorm.DB) *connPool {
+	if db.Config == nil || db.Statement == nil {
+		return nil
+	}
+
+	sqlDB, ok := db.ConnPool.(*sql.DB)
+	if !ok {
+		return nil
+	}
+
+	pool := &connPool{}
+	pool.db.Store(sqlDB)
+
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+	return pool
+}
+
+func (c *connPool) get() *sql.DB {
+	return c.db.Load().(*sql.DB)
+}
+
+// swap swap the sql.DB and return the replaced one
+//
+// Should be called with gormDBMapRW locked.
+func (c *connPool) swap(db *sql.DB) *sql.DB {
+	old := c.get()
+	c.db.Store(db)
+	return old
+}
+
+// PrepareContext ...
+func (c *connPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.get().PrepareContext(ctx, query)
+}
+
+// ExecContext ...
+func (c *connPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.get().ExecContext(ctx, query, args...)
+}
+
+// QueryContext ...
+func (c *connPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.get().QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext ...
+func (c *connPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.get().QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx ...
+func (c *connPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.get().BeginTx(ctx, opts)
+}
+
+// GetDBConn return the current sql.DB, used by gorm.DB.DB()
+func (c *connPool) GetDBConn() (*sql.DB, error) {
+	return c.get(), nil
+}
+
+// Ping ...
+func (c *connPool) Ping() error {
+	return c.get().Ping()
+}
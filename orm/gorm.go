@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"database/sql"
 	"fmt"
 	"sync"
 	"time"
@@ -11,8 +12,15 @@ import (
 )
 
 var (
-	gormDBMap   = map[string]*gorm.DB{}
-	gormDBMapRW sync.RWMutex
+	gormDBMap     = map[string]*gorm.DB{}
+	gormConnPools = map[string]*connPool{}
+	gormConfigs   = map[string]serviceConfig{}
+	gormOptions   = map[string][]GORMOption{}
+	gormDBMapRW   sync.RWMutex
+
+	// drainDelay the replaced sql.DB will be closed after the delay, the
+	// queries started before closing will not be interrupted
+	drainDelay = time.Second
 )
 
 func getGORMDB(name string, opts ...GORMOption) (*gorm.DB, error) {
@@ -28,7 +36,23 @@ func getGORMDB(name string, opts ...GORMOption) (*gorm.DB, error) {
 		opt(&cfg)
 	}
 
-	return newGORMDB(&cfg)
+	db, err := newGORMDB(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	setGORMOptions(name, opts)
+	return db, nil
+}
+
+// setGORMOptions keep the options of db, which will be applied on reload
+func setGORMOptions(name string, opts []GORMOption) {
+	gormDBMapRW.Lock()
+	defer gormDBMapRW.Unlock()
+
+	if _, exist := gormOptions[name]; !exist {
+		gormOptions[name] = opts
+	}
 }
 
 func newGORMDB(cfg *serviceConfig) (*gorm.DB, error) {
@@ -40,9 +64,23 @@ func newGORMDB(cfg *serviceConfig) (*gorm.DB, error) {
 		return db, nil
 	}
 
+	db, sqlDB, err := openGORMDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	setSQLDBPool(sqlDB, cfg)
+
+	gormDBMap[cfg.Name] = db
+	gormConnPools[cfg.Name] = wrapConnPool(db)
+	gormConfigs[cfg.Name] = *cfg
+	return db, nil
+}
+
+func openGORMDB(cfg *serviceConfig) (*gorm.DB, *sql.DB, error) {
 	d, exist := driver.Get(cfg.Driver)
 	if !exist {
-		return nil, fmt.Errorf("invalid driver:%s", cfg.Driver)
+		return nil, nil, fmt.Errorf("invalid driver:%s", cfg.Driver)
 	}
 
 	var opts []gorm.Option
@@ -52,14 +90,18 @@ func newGORMDB(cfg *serviceConfig) (*gorm.DB, error) {
 
 	db, err := gorm.Open(d.Open(cfg.DSN), opts...)
 	if err != nil {
-		return nil, fmt.Errorf("gorm open fail. error:%v", err)
+		return nil, nil, fmt.Errorf("gorm open fail. error:%v", err)
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("get sql.DB fail. error:%v", err)
+		return nil, nil, fmt.Errorf("get sql.DB fail. error:%v", err)
 	}
 
+	return db, sqlDB, nil
+}
+
+func setSQLDBPool(sqlDB *sql.DB, cfg *serviceConfig) {
 	if cfg.MaxIdle > 0 {
 		sqlDB.SetMaxIdleConns(cfg.MaxIdle)
 	}
@@ -71,7 +113,78 @@ func newGORMDB(cfg *serviceConfig) (*gorm.DB, error) {
 	if cfg.MaxIdleTime > 0 {
 		sqlDB.SetConnMaxIdleTime(time.Duration(cfg.MaxIdleTime) * time.Millisecond)
 	}
+}
 
-	gormDBMap[cfg.Name] = db
-	return db, nil
+// reloadGORMDBs apply the changed config to the dbs
+//
+// The pool of db will be resized in place. When dsn changed, the underlying
+// sql.DB will be swapped, and the replaced one will be closed after
+// drainDelay, so the *gorm.DB held by caller keeps working and the in-flight
+// queries will not be interrupted.
+//
+// The changes of dsn are ignored when PrepareStmt enabled, the prepared
+// statements are bound to the connections of the last sql.DB. The changes
+// of driver are always ignored, the dialector of the held *gorm.DB builds
+// the statements of the last driver. Restart to apply them.
+func reloadGORMDBs() {
+	gormDBMapRW.Lock()
+	var replaced []*sql.DB
+	for name, db := range gormDBMap {
+		cfg := getServiceConfig(name)
+		for _, opt := range gormOptions[name] {
+			opt(&cfg)
+		}
+
+		old := gormConfigs[name]
+		if cfg == old {
+			continue
+		}
+
+		if cfg.DSN == old.DSN && cfg.Driver == old.Driver {
+			if sqlDB, err := db.DB(); err == nil {
+				setSQLDBPool(sqlDB, &cfg)
+			}
+			gormConfigs[name] = cfg
+			continue
+		}
+
+		if cfg.Driver != old.Driver {
+			logErrorf("driver changed, keep the last db until restart. name:%s", name)
+			continue
+		}
+
+		pool := gormConnPools[name]
+		if pool == nil {
+			logErrorf("db not support swapping when PrepareStmt enabled, keep the last db until restart. name:%s", name)
+			continue
+		}
+
+		// only the sql.DB of the new *gorm.DB is used. The dialector of the
+		// held *gorm.DB keeps the last dsn, which is only used on open, and
+		// the settings detected on open, e.g. the server version.
+		_, sqlDB, err := openGORMDB(&cfg)
+		if err != nil {
+			logErrorf("gorm reopen fail, keep the last db. name:%s error:%v", name, err)
+			continue
+		}
+
+		setSQLDBPool(sqlDB, &cfg)
+		replaced = append(replaced, pool.swap(sqlDB))
+		gormConfigs[name] = cfg
+	}
+	gormDBMapRW.Unlock()
+
+	for _, sqlDB := range replaced {
+		go drainSQLDB(sqlDB)
+	}
+}
+
+// drainSQLDB close the sql.DB after drainDelay
+//
+// Close waits for all queries that have started to finish.
+func drainSQLDB(sqlDB *sql.DB) {
+	time.Sleep(drainDelay)
+	if err := sqlDB.Close(); err != nil {
+		logErrorf("close replaced db fail. error:%v", err)
+	}
 }
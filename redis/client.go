@@ -24,11 +24,41 @@ type ClientProxy interface {
 	// getting an underlying connection, then the connection Err, Do, Send, Flush and Receive methods return that error.
 	Conn() redigo.Conn
 
+	// Pipeline sends the commands queued by fn in one round trip.
+	// The error of each command is returned in its reply, and the error
+	// returned means fn or the connection failed.
+	Pipeline(ctx context.Context, fn func(p Pipeliner) error) ([]Reply, error)
+
+	// TxPipeline sends the commands queued by fn in a MULTI/EXEC transaction.
+	TxPipeline(ctx context.Context, fn func(p Pipeliner) error) ([]Reply, error)
+
+	// Watch runs the optimistic transaction on keys.
+	// The keys are watched before fn called, and the commands queued by fn
+	// are executed in a MULTI/EXEC transaction. The transaction is retried
+	// when the watched keys changed, and ErrTxAborted returned after all
+	// retries aborted.
+	Watch(ctx context.Context, keys []string, fn func(tx Tx) error, opts ...WatchOption) ([]Reply, error)
+
 	// Locker gets a distributed lock provider
 	Locker() LockerProxy
 
 	// Fetcher gets an object fetcher
 	Fetcher() FetcherProxy
+
+	// Subscriber gets a pub/sub subscriber
+	Subscriber() SubscriberProxy
+
+	// Stream gets a stream producer and consumer group worker
+	Stream() StreamProxy
+
+	// RWMutex gets a distributed reader/writer lock provider
+	RWMutex() RWMutexProxy
+
+	// Semaphore gets a distributed counting semaphore provider
+	Semaphore() SemaphoreProxy
+
+	// RateLimiter gets a distributed rate limiter with the limit rule
+	RateLimiter(limit RateLimit) RateLimiterProxy
 }
 
 type clientProxyImpl struct {
@@ -51,7 +81,14 @@ func NewClientProxy(name string, opts ...ClientOption) ClientProxy {
 // DialReadTimeout() timeout return err can be checked by strings.Contains(e.Error(), "io/timeout").
 // ctx timeout return err context.DeadlineExceeded.
 // ctx canceled return err context.Canceled.
+//
+// The read-only commands are sent to replicas when configured, unless the
+// ctx is returned by WithReadPrimary.
 func (c *clientProxyImpl) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if pool, ok := c.getPool().(*replicaPool); ok && pool.readable(ctx, cmd) {
+		return pool.do(ctx, cmd, args...)
+	}
+
 	conn := c.Conn()
 	defer func() {
 		if err := conn.Close(); err != nil {
@@ -81,6 +118,31 @@ func (c *clientProxyImpl) Fetcher() FetcherProxy {
 	return NewFetcherProxy(c.name, c.opts...)
 }
 
-func (c *clientProxyImpl) getPool() *redigo.Pool {
+// Subscriber gets a pub/sub subscriber
+func (c *clientProxyImpl) Subscriber() SubscriberProxy {
+	return NewSubscriberProxy(c.name, c.opts...)
+}
+
+// Stream gets a stream producer and consumer group worker
+func (c *clientProxyImpl) Stream() StreamProxy {
+	return NewStreamProxy(c.name, c.opts...)
+}
+
+// RWMutex gets a distributed reader/writer lock provider
+func (c *clientProxyImpl) RWMutex() RWMutexProxy {
+	return NewRWMutexProxy(c.name, c.opts...)
+}
+
+// Semaphore gets a distributed counting semaphore provider
+func (c *clientProxyImpl) Semaphore() SemaphoreProxy {
+	return NewSemaphoreProxy(c.name, c.opts...)
+}
+
+// RateLimiter gets a distributed rate limiter with the limit rule
+func (c *clientProxyImpl) RateLimiter(limit RateLimit) RateLimiterProxy {
+	return NewRateLimiterProxy(c.name, limit, c.opts...)
+}
+
+func (c *clientProxyImpl) getPool() connPool {
 	return getRedisPool(c.name, c.opts...)
 }
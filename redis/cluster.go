@@ -0,0 +1,690 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+const clusterSlots = 16384
+
+var (
+	// clusterMaxRedirects the max MOVED/ASK redirects of a command
+	clusterMaxRedirects = 5
+
+	// clusterRefreshInterval the min interval to refresh the slots of the
+	// pools built after
+	clusterRefreshInterval = 100 * time.Millisecond
+
+	errClusterNodeNotFound = errors.New("redis: cluster node not found")
+)
+
+// clusterNoKeyCommands the commands without key, will be sent to any node
+var clusterNoKeyCommands = map[string]bool{
+	"ASKING": true, "AUTH": true, "BGREWRITEAOF": true, "BGSAVE": true, "CLIENT": true,
+	"CLUSTER": true, "COMMAND": true, "CONFIG": true, "DBSIZE": true, "DEBUG": true,
+	"DISCARD": true, "ECHO": true, "EXEC": true, "FLUSHALL": true, "FLUSHDB": true,
+	"FUNCTION": true, "HELLO": true, "INFO": true, "KEYS": true, "LASTSAVE": true,
+	"LATENCY": true, "MONITOR": true, "MULTI": true, "PING": true, "PUBSUB": true,
+	"QUIT": true, "RANDOMKEY": true, "READONLY": true, "READWRITE": true, "ROLE": true,
+	"SAVE": true, "SCAN": true, "SCRIPT": true, "SELECT": true, "SHUTDOWN": true,
+	"SLOWLOG": true, "SWAPDB": true, "TIME": true, "UNWATCH": true, "WAIT": true,
+}
+
+// clusterPool the pools of the cluster nodes
+//
+// The slots are loaded from the seed nodes in dsn by CLUSTER SLOTS, and the
+// command is sent to the master of the slot of its key. The slots are
+// updated on MOVED redirection, and refreshed on the dial error.
+type clusterPool struct {
+	cfg   serviceConfig
+	seeds []string
+
+	rw     sync.RWMutex
+	slots  []string
+	nodes  map[string]*redigo.Pool
+	closed bool
+
+	refreshMu       sync.Mutex
+	refreshedAt     time.Time
+	refreshInterval time.Duration
+	refreshing      sync.WaitGroup // the background refreshes
+}
+
+func newClusterPool(cfg *serviceConfig) *clusterPool {
+	return &clusterPool{
+		cfg:             *cfg,
+		seeds:           dsnHosts(cfg.DSN),
+		nodes:           map[string]*redigo.Pool{},
+		refreshInterval: clusterRefreshInterval,
+	}
+}
+
+// Get gets a connection routing the commands by key
+func (p *clusterPool) Get() redigo.Conn {
+	return &clusterConn{
+		pool: p,
+	}
+}
+
+// ActiveCount returns the number of connections of all nodes
+func (p *clusterPool) ActiveCount() int {
+	p.rw.RLock()
+	defer p.rw.RUnlock()
+
+	count := 0
+	for _, v := range p.nodes {
+		count += v.ActiveCount()
+	}
+
+	return count
+}
+
+// IdleCount returns the number of idle connections of all nodes
+func (p *clusterPool) IdleCount() int {
+	p.rw.RLock()
+	defer p.rw.RUnlock()
+
+	count := 0
+	for _, v := range p.nodes {
+		count += v.IdleCount()
+	}
+
+	return count
+}
+
+// Close close the pools of all nodes, and wait for the background
+// refreshes
+func (p *clusterPool) Close() error {
+	p.rw.Lock()
+	p.closed = true
+
+	var err error
+	for _, v := range p.nodes {
+		if e := v.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	p.rw.Unlock()
+
+	p.refreshing.Wait()
+	return err
+}
+
+// nodePool get or new the pool of node
+func (p *clusterPool) nodePool(addr string) *redigo.Pool {
+	p.rw.RLock()
+	pool, exist := p.nodes[addr]
+	p.rw.RUnlock()
+	if exist {
+		return pool
+	}
+
+	p.rw.Lock()
+	defer p.rw.Unlock()
+
+	if pool, exist = p.nodes[addr]; exist {
+		return pool
+	}
+
+	pool = newStandalonePool(&p.cfg, replaceHost(p.cfg.DSN, addr))
+	p.nodes[addr] = pool
+	if p.closed {
+		pool.Close()
+	}
+
+	return pool
+}
+
+// addrOfKey return the master address of the slot of key, or any node
+// address when key is empty
+func (p *clusterPool) addrOfKey(key string, hasKey bool) (string, error) {
+	if !hasKey {
+		return p.anyAddr()
+	}
+
+	slot := keySlot(key)
+	if addr := p.slotAddr(slot); addr != "" {
+		return addr, nil
+	}
+
+	if err := p.refresh(); err != nil {
+		return "", err
+	}
+
+	if addr := p.slotAddr(slot); addr != "" {
+		return addr, nil
+	}
+
+	return p.anyAddr()
+}
+
+func (p *clusterPool) slotAddr(slot int) string {
+	p.rw.RLock()
+	defer p.rw.RUnlock()
+
+	if p.slots == nil {
+		return ""
+	}
+
+	return p.slots[slot]
+}
+
+// anyAddr return any known node address, or the seed node address
+func (p *clusterPool) anyAddr() (string, error) {
+	p.rw.RLock()
+	for addr := range p.nodes {
+		p.rw.RUnlock()
+		return addr, nil
+	}
+	p.rw.RUnlock()
+
+	if len(p.seeds) == 0 {
+		return "", errClusterNodeNotFound
+	}
+
+	return p.seeds[0], nil
+}
+
+// refresh load the slots from the known nodes and the seed nodes
+//
+// Refresh at most once in refreshInterval.
+func (p *clusterPool) refresh() error {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	if time.Since(p.refreshedAt) < p.refreshInterval {
+		return nil
+	}
+
+	p.rw.RLock()
+	addrs := make([]string, 0, len(p.nodes)+len(p.seeds))
+	for addr := range p.nodes {
+		addrs = append(addrs, addr)
+	}
+	p.rw.RUnlock()
+
+	var errs []string
+	for _, addr := range append(addrs, p.seeds...) {
+		slots, err := p.loadSlots(addr)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("node %s: %v", addr, err))
+			continue
+		}
+
+		p.rw.Lock()
+		p.slots = slots
+		p.rw.Unlock()
+
+		p.refreshedAt = time.Now()
+		return nil
+	}
+
+	if len(errs) == 0 {
+		return errClusterNodeNotFound
+	}
+
+	return fmt.Errorf("redis: load cluster slots fail. error:%s", strings.Join(errs, "; "))
+}
+
+func (p *clusterPool) loadSlots(addr string) ([]string, error) {
+	conn := p.nodePool(addr).Get()
+	defer conn.Close()
+
+	reply, err := redigo.Values(conn.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClusterSlots(reply, addr)
+}
+
+// parseClusterSlots parse the reply of CLUSTER SLOTS into the master
+// address of each slot
+func parseClusterSlots(reply []interface{}, addr string) ([]string, error) {
+	host, _, _ := net.SplitHostPort(addr)
+	slots := make([]string, clusterSlots)
+	for _, v := range reply {
+		item, err := redigo.Values(v, nil)
+		if err != nil || len(item) < 3 {
+			return nil, fmt.Errorf("invalid cluster slots reply %v", v)
+		}
+
+		start, err := redigo.Int(item[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := redigo.Int(item[1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		master, err := redigo.Values(item[2], nil)
+		if err != nil || len(master) < 2 {
+			return nil, fmt.Errorf("invalid cluster node %v", item[2])
+		}
+
+		ip, _ := redigo.String(master[0], nil)
+		if ip == "" {
+			ip = host
+		}
+
+		port, err := redigo.Int(master[1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if start < 0 || end >= clusterSlots || start > end {
+			return nil, fmt.Errorf("invalid slot range %d-%d", start, end)
+		}
+
+		nodeAddr := net.JoinHostPort(ip, strconv.Itoa(port))
+		for slot := start; slot <= end; slot++ {
+			slots[slot] = nodeAddr
+		}
+	}
+
+	return slots, nil
+}
+
+// moved update the slot to the moved address and refresh the slots in
+// background until closed
+func (p *clusterPool) moved(slot int, addr string) {
+	p.rw.Lock()
+	defer p.rw.Unlock()
+
+	if p.slots == nil {
+		p.slots = make([]string, clusterSlots)
+	}
+	p.slots[slot] = addr
+
+	if p.closed {
+		return
+	}
+
+	// added with the lock held, so Close waits for it
+	p.refreshing.Add(1)
+	go func() {
+		defer p.refreshing.Done()
+		if err := p.refresh(); err != nil {
+			logErrorf("refresh cluster slots fail. error:%v", err)
+		}
+	}()
+}
+
+// do send the command to the node of key, and follow the MOVED and ASK
+// redirection
+//
+// On the dial error, the node may be down or failed over, the slots are
+// refreshed and the command is retried once on the node of key. The command
+// is not retried on the IO error, it may have been applied by the server.
+func (p *clusterPool) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	key, hasKey := commandKey(cmd, args)
+	addr, err := p.addrOfKey(key, hasKey)
+	if err != nil {
+		return nil, err
+	}
+
+	asking, retried := false, false
+	for i := 0; i <= clusterMaxRedirects; i++ {
+		reply, err := p.doOn(ctx, addr, asking, cmd, args...)
+		kind, slot, target := parseRedirect(err)
+		switch {
+		case kind == "MOVED":
+			p.moved(slot, target)
+			addr, asking = target, false
+		case kind == "ASK":
+			addr, asking = target, true
+		case !retried && isDialError(err):
+			logErrorf("redis cluster node %s fail, refresh slots and retry. error:%v", addr, err)
+			if e := p.refresh(); e != nil {
+				logErrorf("refresh cluster slots fail. error:%v", e)
+			}
+
+			if addr, err = p.addrOfKey(key, hasKey); err != nil {
+				return nil, err
+			}
+			asking, retried = false, true
+		default:
+			return reply, err
+		}
+	}
+
+	return nil, fmt.Errorf("redis: too many cluster redirects. cmd:%s key:%s", cmd, key)
+}
+
+func (p *clusterPool) doOn(ctx context.Context, addr string, asking bool, cmd string, args ...interface{}) (interface{}, error) {
+	conn := p.nodePool(addr).Get()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logErrorf("connect close fail. error:%v", err)
+		}
+	}()
+
+	// nothing sent when the dial fail
+	if err := conn.Err(); err != nil {
+		return nil, &clusterDialError{err: err}
+	}
+
+	if asking {
+		if _, err := redigo.DoContext(conn, ctx, "ASKING"); err != nil {
+			return nil, err
+		}
+	}
+
+	return redigo.DoContext(conn, ctx, cmd, args...)
+}
+
+// clusterDialError the connection to node fail, the command is not sent
+type clusterDialError struct {
+	err error
+}
+
+func (e *clusterDialError) Error() string {
+	return e.err.Error()
+}
+
+func (e *clusterDialError) Unwrap() error {
+	return e.err
+}
+
+// isDialError returns whether the command failed before sent, but not
+// because the pool exhausted
+func isDialError(err error) bool {
+	var e *clusterDialError
+	return errors.As(err, &e) && !errors.Is(err, redigo.ErrPoolExhausted)
+}
+
+// parseRedirect parse the MOVED or ASK error, e.g. MOVED 3999 127.0.0.1:6381
+func parseRedirect(err error) (string, int, string) {
+	var e redigo.Error
+	if !errors.As(err, &e) {
+		return "", 0, ""
+	}
+
+	fields := strings.Fields(string(e))
+	if len(fields) != 3 || (fields[0] != "MOVED" && fields[0] != "ASK") {
+		return "", 0, ""
+	}
+
+	slot, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, ""
+	}
+
+	return fields[0], slot, fields[2]
+}
+
+// clusterConn the connection of cluster
+//
+// Do routes each command to the node of its key. The pipeline (Send, Flush
+// and Receive), WATCH and MULTI are pinned to the node of the first keyed
+// command until the connection closed, so all their keys must be in the
+// same slot, e.g. with the same hash tag {user:1}. MULTI is buffered and
+// replied OK until the first keyed command, so the transaction is sent to
+// the node of its keys.
+type clusterConn struct {
+	pool    *clusterPool
+	conn    redigo.Conn
+	pending []clusterCommand
+	err     error
+}
+
+type clusterCommand struct {
+	name string
+	args []interface{}
+}
+
+// Close close the pinned connection
+func (c *clusterConn) Close() error {
+	if c.err == nil {
+		c.err = errors.New("redigo: closed")
+	}
+
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
+
+// Err returns a non-nil value when the connection is not usable
+func (c *clusterConn) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if c.conn != nil {
+		return c.conn.Err()
+	}
+
+	return nil
+}
+
+// Do sends a command to the node of key and returns the received reply
+func (c *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return c.DoContext(context.Background(), cmd, args...)
+}
+
+// DoContext sends a command to the node of key and returns the received reply
+func (c *clusterConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if c.deferMulti(cmd, args) {
+		return "OK", nil
+	}
+
+	name := strings.ToUpper(cmd)
+	if c.conn != nil || len(c.pending) > 0 || name == "" || name == "WATCH" {
+		key, hasKey := commandKey(cmd, args)
+		if err := c.pin(key, hasKey); err != nil {
+			return nil, err
+		}
+
+		return redigo.DoContext(c.conn, ctx, cmd, args...)
+	}
+
+	return c.pool.do(ctx, cmd, args...)
+}
+
+// DoWithTimeout sends a command on the pinned connection with timeout
+func (c *clusterConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	if c.err == nil && c.deferMulti(cmd, args) {
+		return "OK", nil
+	}
+
+	key, hasKey := commandKey(cmd, args)
+	if err := c.pin(key, hasKey); err != nil {
+		return nil, err
+	}
+
+	return redigo.DoWithTimeout(c.conn, timeout, cmd, args...)
+}
+
+// Send writes the command to the pinned connection
+//
+// The commands without key are buffered until the first keyed command or
+// Flush.
+func (c *clusterConn) Send(cmd string, args ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if c.conn != nil {
+		return c.conn.Send(cmd, args...)
+	}
+
+	key, hasKey := commandKey(cmd, args)
+	if !hasKey {
+		c.pending = append(c.pending, clusterCommand{name: cmd, args: args})
+		return nil
+	}
+
+	if err := c.pin(key, hasKey); err != nil {
+		return err
+	}
+
+	return c.conn.Send(cmd, args...)
+}
+
+// Flush flushes the output buffer to the pinned connection
+func (c *clusterConn) Flush() error {
+	if err := c.pin("", false); err != nil {
+		return err
+	}
+
+	return c.conn.Flush()
+}
+
+// Receive receives a single reply from the pinned connection
+func (c *clusterConn) Receive() (interface{}, error) {
+	if err := c.pin("", false); err != nil {
+		return nil, err
+	}
+
+	return c.conn.Receive()
+}
+
+// ReceiveContext receives a single reply from the pinned connection
+func (c *clusterConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	if err := c.pin("", false); err != nil {
+		return nil, err
+	}
+
+	return redigo.ReceiveContext(c.conn, ctx)
+}
+
+// ReceiveWithTimeout receives a single reply from the pinned connection
+func (c *clusterConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	if err := c.pin("", false); err != nil {
+		return nil, err
+	}
+
+	return redigo.ReceiveWithTimeout(c.conn, timeout)
+}
+
+// deferMulti buffer the MULTI before pinned, it will be sent with the first
+// keyed command of the transaction
+func (c *clusterConn) deferMulti(cmd string, args []interface{}) bool {
+	if c.conn != nil || !strings.EqualFold(cmd, "MULTI") {
+		return false
+	}
+
+	c.pending = append(c.pending, clusterCommand{name: cmd, args: args})
+	return true
+}
+
+// pin pin the connection to the node of key, and send the buffered commands
+func (c *clusterConn) pin(key string, hasKey bool) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if c.conn != nil {
+		return nil
+	}
+
+	addr, err := c.pool.addrOfKey(key, hasKey)
+	if err != nil {
+		return err
+	}
+
+	c.conn = c.pool.nodePool(addr).Get()
+	pending := c.pending
+	c.pending = nil
+	for _, v := range pending {
+		if err := c.conn.Send(v.name, v.args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commandKey return the first key of command
+func commandKey(cmd string, args []interface{}) (string, bool) {
+	name := strings.ToUpper(cmd)
+	if name == "" || clusterNoKeyCommands[name] {
+		return "", false
+	}
+
+	index := 0
+	switch name {
+	case "EVAL", "EVALSHA", "EVAL_RO", "EVALSHA_RO", "FCALL", "FCALL_RO":
+		if len(args) < 3 {
+			return "", false
+		}
+
+		numKeys, err := strconv.Atoi(argString(args[1]))
+		if err != nil || numKeys < 1 {
+			return "", false
+		}
+		index = 2
+	case "BITOP", "OBJECT", "XGROUP", "XINFO", "MEMORY":
+		index = 1
+	case "XREAD", "XREADGROUP":
+		index = -1
+		for i, v := range args {
+			if strings.EqualFold(argString(v), "STREAMS") {
+				index = i + 1
+				break
+			}
+		}
+	}
+
+	if index < 0 || index >= len(args) {
+		return "", false
+	}
+
+	return argString(args[index]), true
+}
+
+func argString(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// keySlot return the hash slot of key, only the hash tag is hashed if
+// the key contains {...}
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key) % clusterSlots)
+}
+
+// crc16 CRC16-CCITT (XMODEM) used by redis cluster
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,236 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_keySlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"123456789", 12739},
+		{"foo", 12182},
+		{"{user1000}.following", keySlot("user1000")},
+		{"foo{}{bar}", keySlot("foo{}{bar}")},
+		{"foo{bar}{zap}", keySlot("bar")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, keySlot(tt.key))
+		})
+	}
+
+	assert.NotEqual(t, keySlot("foo{}{bar}"), keySlot("bar"))
+}
+
+func Test_commandKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		args    []interface{}
+		want    string
+		wantKey bool
+	}{
+		{"get", "get", []interface{}{"k1"}, "k1", true},
+		{"bytes key", "SET", []interface{}{[]byte("k1"), "v"}, "k1", true},
+		{"no args", "GET", nil, "", false},
+		{"ping", "PING", nil, "", false},
+		{"eval with key", "EVAL", []interface{}{"script", 1, "k1", "a"}, "k1", true},
+		{"eval without key", "EVALSHA", []interface{}{"sha", 0}, "", false},
+		{"bitop", "BITOP", []interface{}{"AND", "dest", "k1"}, "dest", true},
+		{"xreadgroup", "XREADGROUP", []interface{}{"GROUP", "g", "c", "COUNT", 1, "STREAMS", "s1", ">"}, "s1", true},
+		{"xread without streams", "XREAD", []interface{}{"COUNT", 1}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotKey := commandKey(tt.cmd, tt.args)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantKey, gotKey)
+		})
+	}
+}
+
+func Test_parseRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind string
+		wantSlot int
+		wantAddr string
+	}{
+		{"nil", nil, "", 0, ""},
+		{"not redis error", fmt.Errorf("MOVED 1 127.0.0.1:6380"), "", 0, ""},
+		{"moved", redigo.Error("MOVED 3999 127.0.0.1:6381"), "MOVED", 3999, "127.0.0.1:6381"},
+		{"ask", redigo.Error("ASK 3999 127.0.0.1:6381"), "ASK", 3999, "127.0.0.1:6381"},
+		{"other", redigo.Error("ERR unknown command"), "", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, slot, addr := parseRedirect(tt.err)
+			assert.Equal(t, tt.wantKind, kind)
+			assert.Equal(t, tt.wantSlot, slot)
+			assert.Equal(t, tt.wantAddr, addr)
+		})
+	}
+}
+
+func Test_parseClusterSlots(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{int64(0), int64(8191), []interface{}{[]byte("10.0.0.1"), int64(6379), []byte("id1")}},
+		[]interface{}{int64(8192), int64(16383), []interface{}{[]byte(""), int64(6380), []byte("id2")}},
+	}
+
+	slots, err := parseClusterSlots(reply, "10.0.0.9:6379")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1:6379", slots[0])
+	assert.Equal(t, "10.0.0.1:6379", slots[8191])
+	assert.Equal(t, "10.0.0.9:6380", slots[16383])
+
+	_, err = parseClusterSlots([]interface{}{[]interface{}{int64(0), int64(clusterSlots), []interface{}{"", int64(1)}}}, "")
+	assert.NotNil(t, err)
+}
+
+func Test_clusterPool(t *testing.T) {
+	conns := map[string]*redigomock.Conn{
+		"127.0.0.1:7000": redigomock.NewConn(),
+		"127.0.0.1:7001": redigomock.NewConn(),
+	}
+	slotsReply := []interface{}{
+		[]interface{}{int64(0), int64(clusterSlots - 1), []interface{}{[]byte("127.0.0.1"), int64(7000)}},
+	}
+	conns["127.0.0.1:7000"].Command("CLUSTER", "SLOTS").Expect(slotsReply)
+	conns["127.0.0.1:7001"].Command("CLUSTER", "SLOTS").Expect(slotsReply)
+	conns["127.0.0.1:7000"].Command("GET", "moved").ExpectError(redigo.Error(fmt.Sprintf("MOVED %d 127.0.0.1:7001", keySlot("moved"))))
+	conns["127.0.0.1:7001"].Command("GET", "moved").Expect([]byte("v1"))
+	conns["127.0.0.1:7000"].Command("GET", "ask").ExpectError(redigo.Error(fmt.Sprintf("ASK %d 127.0.0.1:7001", keySlot("ask"))))
+	conns["127.0.0.1:7001"].Command("ASKING").Expect("OK")
+	conns["127.0.0.1:7001"].Command("GET", "ask").Expect([]byte("v2"))
+	conns["127.0.0.1:7000"].Command("MULTI").Expect("OK")
+	conns["127.0.0.1:7000"].Command("DISCARD").Expect("OK")
+	conns["127.0.0.1:7000"].Command("SET", "{k}1", "v").Expect("OK")
+	conns["127.0.0.1:7000"].Command("SET", "{k}2", "v").Expect("OK")
+	multi := conns["127.0.0.1:7001"].Command("MULTI").Expect("OK")
+	conns["127.0.0.1:7001"].Command("SET", "{moved}1", "v").Expect("QUEUED")
+	conns["127.0.0.1:7001"].Command("EXEC").Expect([]interface{}{"OK"})
+
+	patches := gomonkey.ApplyFunc(redigo.DialURL,
+		func(rawurl string, _ ...redigo.DialOption) (redigo.Conn, error) {
+			u, _ := url.Parse(rawurl)
+			if c, ok := conns[u.Host]; ok {
+				return c, nil
+			}
+			return nil, fmt.Errorf("dial %s fail", u.Host)
+		})
+	defer patches.Reset()
+
+	p := newClusterPool(&serviceConfig{
+		Name: "cluster",
+		DSN:  "redis://127.0.0.1:7009,127.0.0.1:7000/0",
+		Mode: ModeCluster,
+	})
+	defer p.Close()
+
+	// the slots are loaded once, and kept the moved address
+	p.refreshInterval = time.Hour
+
+	c := p.Get()
+	defer c.Close()
+
+	got, err := redigo.String(c.Do("GET", "moved"))
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", got)
+	assert.Equal(t, "127.0.0.1:7001", p.slotAddr(keySlot("moved")), "moved should update slots")
+
+	got, err = redigo.String(c.Do("GET", "ask"))
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", got)
+	assert.Equal(t, "127.0.0.1:7000", p.slotAddr(keySlot("ask")), "ask should not update slots")
+
+	// the pipeline is pinned to the node of the first key
+	pc := p.Get()
+	assert.Nil(t, pc.Send("MULTI"))
+	assert.Nil(t, pc.Send("SET", "{k}1", "v"))
+	assert.Nil(t, pc.Send("SET", "{k}2", "v"))
+	assert.Nil(t, pc.Flush())
+	assert.Nil(t, pc.Close())
+	assert.NotNil(t, pc.Err())
+
+	// the transaction is sent to the node of its first key
+	tc := p.Get()
+	defer tc.Close()
+
+	reply, err := tc.Do("MULTI")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", reply)
+	assert.Equal(t, 0, conns["127.0.0.1:7001"].Stats(multi))
+
+	reply, err = tc.Do("SET", "{moved}1", "v")
+	assert.Nil(t, err)
+	assert.Equal(t, "QUEUED", reply)
+	assert.Equal(t, 1, conns["127.0.0.1:7001"].Stats(multi))
+
+	reply, err = tc.Do("EXEC")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"OK"}, reply)
+}
+
+func Test_clusterPool_failover(t *testing.T) {
+	// 127.0.0.1:7000 is down, and its slots are failed over to 127.0.0.1:7001
+	conn := redigomock.NewConn()
+	conn.Command("CLUSTER", "SLOTS").Expect([]interface{}{
+		[]interface{}{int64(0), int64(clusterSlots - 1), []interface{}{[]byte("127.0.0.1"), int64(7000)}},
+	}).Expect([]interface{}{
+		[]interface{}{int64(0), int64(clusterSlots - 1), []interface{}{[]byte("127.0.0.1"), int64(7001)}},
+	})
+	conn.Command("GET", "k1").Expect([]byte("v1"))
+	errReply := conn.Command("GET", "k2").ExpectError(redigo.Error("ERR fail"))
+	ioErr := conn.Command("INCR", "k3").ExpectError(errors.New("i/o timeout"))
+
+	patches := gomonkey.ApplyFunc(redigo.DialURL,
+		func(rawurl string, _ ...redigo.DialOption) (redigo.Conn, error) {
+			u, _ := url.Parse(rawurl)
+			if u.Host == "127.0.0.1:7001" {
+				return conn, nil
+			}
+			return nil, fmt.Errorf("dial %s fail", u.Host)
+		})
+	defer patches.Reset()
+
+	p := newClusterPool(&serviceConfig{
+		Name: "cluster",
+		DSN:  "redis://127.0.0.1:7001/0",
+		Mode: ModeCluster,
+	})
+	defer p.Close()
+
+	p.refreshInterval = 0
+
+	c := p.Get()
+	defer c.Close()
+
+	// refreshed and retried on dial error
+	got, err := redigo.String(c.Do("GET", "k1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", got)
+	assert.Equal(t, "127.0.0.1:7001", p.slotAddr(keySlot("k1")))
+
+	// the error reply is not retried
+	_, err = c.Do("GET", "k2")
+	assert.Equal(t, redigo.Error("ERR fail"), err)
+	assert.Equal(t, 1, conn.Stats(errReply))
+
+	// the IO error is not retried, the command may have been applied
+	_, err = c.Do("INCR", "k3")
+	assert.EqualError(t, err, "i/o timeout")
+	assert.Equal(t, 1, conn.Stats(ioErr))
+}
@@ -11,6 +11,11 @@ import (
 var (
 	serviceConfigMap = map[string]serviceConfig{}
 	serviceConfigRW  sync.RWMutex
+
+	// serviceConfigSources the configure each service config loaded from
+	serviceConfigSources = map[string]config.Configure{}
+
+	watchedConfigures sync.Map
 )
 
 func init() {
@@ -18,10 +23,24 @@ func init() {
 }
 
 // LoadConfig load config from file
+//
+// The clients already created will be reloaded when their config changed.
 func LoadConfig(path string) error {
 	return initAppConfig(path)
 }
 
+// LoadConfigure load config from configure
+//
+// The clients already created will be reloaded when their config changed.
+//
+// Use it to load config by a config.Loader, or from any config source. e.g.
+//
+//	configure, err := config.NewLoader(config.WithSearchPaths("./", "./conf")).Load("app.yaml")
+//	err = redis.LoadConfigure(configure)
+func LoadConfigure(configure config.Configure) error {
+	return initAppConfigure(configure)
+}
+
 type appConfig struct {
 	Client struct {
 		RedisCfg redisConfig     `yaml:"redis"`
@@ -73,49 +92,131 @@ type redisConfig struct {
 	Wait            bool `yaml:"wait"`
 }
 
+// the topology modes of service
+const (
+	// ModeStandalone single redis server, the default mode
+	ModeStandalone = "standalone"
+
+	// ModeSentinel redis sentinel, the master is discovered from the
+	// sentinels in dsn, e.g. redis://:password@127.0.0.1:26379,127.0.0.2:26379/0
+	ModeSentinel = "sentinel"
+
+	// ModeCluster redis cluster, the slots are discovered from the seed
+	// nodes in dsn, e.g. redis://:password@127.0.0.1:7000,127.0.0.2:7000
+	ModeCluster = "cluster"
+)
+
+// the replica selectors of service
+const (
+	// ReplicaRoundRobin select the replicas in turn, the default selector
+	ReplicaRoundRobin = "round_robin"
+
+	// ReplicaLatency select the replica with the lowest average latency
+	ReplicaLatency = "latency"
+)
+
 type serviceConfig struct {
 	Name string `yaml:"name"`
 	DSN  string `yaml:"dsn"`
 
+	// Mode standalone, sentinel or cluster
+	Mode string `yaml:"mode"`
+
+	// MasterName the master name monitored by sentinels
+	MasterName string `yaml:"master_name"`
+
+	// SentinelPassword the password of sentinels, the password in dsn is
+	// used by the master
+	SentinelPassword string `yaml:"sentinel_password"`
+
+	// Replicas the dsn of replicas, the read-only commands are sent to them
+	Replicas []string `yaml:"replicas"`
+
+	// ReplicaSelector round_robin or latency
+	ReplicaSelector string `yaml:"replica_selector"`
+
 	redisConfig `yaml:",inline"`
 }
 
 func initAppConfig(path string) error {
-	c, err := loadAppConfig(path)
+	_, err := os.Stat(path)
 	if err != nil {
-		return fmt.Errorf("config load fail. error:%v", err)
+		return err
 	}
 
-	for _, v := range c.getServiceConfigs() {
-		registerServiceConfig(v)
+	configure, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
-	return nil
+	return initAppConfigure(configure)
 }
 
-func loadAppConfig(path string) (*appConfig, error) {
-	_, err := os.Stat(path)
+func initAppConfigure(configure config.Configure) error {
+	c, err := loadAppConfig(configure)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("config load fail. error:%v", err)
 	}
 
-	configure, err := config.Load(path)
-	if err != nil {
-		return &appConfig{}, nil
-	}
+	registerServiceConfigs(configure, c.getServiceConfigs())
+	reloadRedisPools()
+	watchAppConfig(configure)
+	return nil
+}
 
+func loadAppConfig(configure config.Configure) (*appConfig, error) {
 	c := &appConfig{}
-	if err = configure.Unmarshal(c); err != nil {
+	if err := configure.Unmarshal(c); err != nil {
 		return nil, fmt.Errorf("config unmarshal fail. error:%v", err)
 	}
 
 	return c, nil
 }
 
-func registerServiceConfig(c serviceConfig) {
+// watchAppConfig apply the client config to the pools when it changed
+//
+// The pools whose config changed will be replaced without restart, see
+// reloadRedisPools.
+func watchAppConfig(configure config.Configure) {
+	if _, watched := watchedConfigures.LoadOrStore(configure, struct{}{}); watched {
+		return
+	}
+
+	configure.OnChange("client", func(_, _ interface{}) {
+		c, err := loadAppConfig(configure)
+		if err != nil {
+			logErrorf("config reload fail. error:%v", err)
+			return
+		}
+
+		registerServiceConfigs(configure, c.getServiceConfigs())
+		reloadRedisPools()
+	})
+}
+
+// registerServiceConfigs replace the service configs loaded from configure
+//
+// The services removed from configure fall back to the default config, and
+// the ones loaded from the other configures are kept.
+func registerServiceConfigs(configure config.Configure, configs []serviceConfig) {
 	serviceConfigRW.Lock()
 	defer serviceConfigRW.Unlock()
-	serviceConfigMap[c.Name] = c
+
+	names := make(map[string]struct{}, len(configs))
+	for _, c := range configs {
+		serviceConfigMap[c.Name] = c
+		serviceConfigSources[c.Name] = configure
+		names[c.Name] = struct{}{}
+	}
+
+	for name, source := range serviceConfigSources {
+		if _, exist := names[name]; exist || source != configure {
+			continue
+		}
+
+		delete(serviceConfigMap, name)
+		delete(serviceConfigSources, name)
+	}
 }
 
 func getServiceConfig(name string) serviceConfig {
@@ -1,9 +1,17 @@
 package redis
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	redigo "github.com/gomodule/redigo/redis"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/wwwangxc/gopkg/config"
 )
 
 func TestInit(t *testing.T) {
@@ -27,3 +35,121 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, 200000, r2.IdleTimeout)
 	assert.Equal(t, 2000, r2.Timeout)
 }
+
+func TestReload(t *testing.T) {
+	t.Cleanup(func() {
+		poolsRW.Lock()
+		if pool, ok := pools["redis_reload"]; ok {
+			pool.Close()
+		}
+		delete(pools, "redis_reload")
+		delete(poolConfigs, "redis_reload")
+		delete(poolOptions, "redis_reload")
+		poolsRW.Unlock()
+
+		serviceConfigRW.Lock()
+		delete(serviceConfigMap, "redis_reload")
+		serviceConfigRW.Unlock()
+	})
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	writeAppConfig := func(maxIdle int) {
+		content := fmt.Sprintf("client:\n  service:\n    - name: redis_reload\n      dsn: redis://127.0.0.1:6379/1\n      max_idle: %d\n", maxIdle)
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	writeAppConfig(10)
+	assert.Nil(t, LoadConfig(path))
+
+	pool := getRedisPool("redis_reload", WithClientTimeout(3000))
+	assert.Equal(t, 10, pool.(*redigo.Pool).MaxIdle)
+
+	writeAppConfig(20)
+	assert.Eventually(t, func() bool {
+		return getRedisPool("redis_reload") != pool
+	}, 2*time.Second, 10*time.Millisecond)
+
+	newPool := getRedisPool("redis_reload")
+	assert.Equal(t, 20, newPool.(*redigo.Pool).MaxIdle)
+
+	// the client options are kept
+	poolsRW.RLock()
+	assert.Equal(t, 3000, poolConfigs["redis_reload"].Timeout)
+	poolsRW.RUnlock()
+
+	// the replaced pool is closed after drained
+	assert.Eventually(t, func() bool {
+		err := pool.Get().Err()
+		return err != nil && err.Error() == "redigo: get on closed pool"
+	}, 3*drainDelay, 10*time.Millisecond)
+
+	// the pool is kept when config not changed
+	reloadRedisPools()
+	assert.True(t, getRedisPool("redis_reload") == newPool)
+
+	// the pool is replaced when load another config
+	path = filepath.Join(t.TempDir(), "app.yaml")
+	writeAppConfig(30)
+	assert.Nil(t, LoadConfig(path))
+	assert.Equal(t, 30, getRedisPool("redis_reload").(*redigo.Pool).MaxIdle)
+}
+
+func TestReload_removeService(t *testing.T) {
+	t.Cleanup(func() {
+		poolsRW.Lock()
+		if pool, ok := pools["redis_removed"]; ok {
+			pool.Close()
+		}
+		delete(pools, "redis_removed")
+		delete(poolConfigs, "redis_removed")
+		delete(poolOptions, "redis_removed")
+		poolsRW.Unlock()
+
+		serviceConfigRW.Lock()
+		delete(serviceConfigMap, "redis_removed")
+		serviceConfigRW.Unlock()
+	})
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	content := "client:\n  service:\n    - name: redis_removed\n      dsn: redis://127.0.0.1:6379/1\n      max_idle: 10\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	assert.Nil(t, LoadConfig(path))
+
+	pool := getRedisPool("redis_removed")
+	assert.Equal(t, 10, pool.(*redigo.Pool).MaxIdle)
+
+	// the removed service falls back to the default config
+	assert.Nil(t, ioutil.WriteFile(path, []byte("client:\n  service: []\n"), 0644))
+	assert.Eventually(t, func() bool {
+		return getRedisPool("redis_removed") != pool
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 2048, getRedisPool("redis_removed").(*redigo.Pool).MaxIdle)
+
+	serviceConfigRW.RLock()
+	_, exist := serviceConfigSources["redis_removed"]
+	serviceConfigRW.RUnlock()
+	assert.False(t, exist)
+
+	// the services loaded from the other configures are kept
+	assert.Equal(t, "redis_1", getServiceConfig("redis_1").Name)
+	assert.Equal(t, 20, getServiceConfig("redis_1").MaxIdle)
+}
+
+func TestLoadConfigure(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "conf"), 0755))
+	content := "client:\n  service:\n    - name: redis_configure\n      dsn: redis://127.0.0.1:6379/3\n      timeout: 3000\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "conf", "app.yaml"), []byte(content), 0644))
+
+	loader := config.NewLoader(config.WithBaseDir(dir), config.WithSearchPaths("./", "./conf"))
+	defer loader.Close()
+
+	configure, err := loader.Load("app.yaml")
+	assert.Nil(t, err)
+	assert.Nil(t, LoadConfigure(configure))
+
+	cfg := getServiceConfig("redis_configure")
+	assert.Equal(t, "redis://127.0.0.1:6379/3", cfg.DSN)
+	assert.Equal(t, 3000, cfg.Timeout)
+}
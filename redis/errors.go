@@ -15,8 +15,20 @@ var (
 	// ErrNotOwnerOfLock not the owner of the key
 	ErrNotOwnerOfLock = errors.New("not the owner of the lock")
 
+	// ErrLockLost lock lost by expired or owned by others
+	ErrLockLost = errors.New("lock lost")
+
+	// ErrLockReleased lock released
+	ErrLockReleased = errors.New("lock released")
+
 	// ErrKeyNotExist key not exist
 	ErrKeyNotExist = errors.New("key not exist")
+
+	// ErrTxAborted transaction aborted by the watched keys changed
+	ErrTxAborted = errors.New("transaction aborted")
+
+	// ErrRateLimited request rate limited
+	ErrRateLimited = errors.New("rate limited")
 )
 
 // IsTimeout is timeout error
@@ -39,7 +51,27 @@ func IsErrNotOwnerOfLock(err error) bool {
 	return errors.Is(err, ErrNotOwnerOfLock)
 }
 
+// IsLockLost is lock lost error
+func IsLockLost(err error) bool {
+	return errors.Is(err, ErrLockLost)
+}
+
+// IsLockReleased is lock released error
+func IsLockReleased(err error) bool {
+	return errors.Is(err, ErrLockReleased)
+}
+
 // IsKeyNotExist is key not exist error
 func IsKeyNotExist(err error) bool {
 	return errors.Is(err, ErrKeyNotExist)
 }
+
+// IsTxAborted is transaction aborted error
+func IsTxAborted(err error) bool {
+	return errors.Is(err, ErrTxAborted)
+}
+
+// IsRateLimited is request rate limited error
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
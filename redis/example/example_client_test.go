@@ -41,11 +41,11 @@ func ExampleClientProxy_Locker() {
 
 	// try lock
 	// not block the current goroutine.
-	// return uuid when the lock is acquired
+	// return the lock handle when the lock is acquired
 	// return error when lock fail or lock not acquired
 	// support reentrant unlock
 	// support automatically renewal
-	uuid, err := l.TryLock(context.Background(), "locker_key",
+	lock, err := l.TryLock(context.Background(), "locker_key",
 		redis.WithLockExpire(1000*time.Millisecond),
 		redis.WithLockHeartbeat(500*time.Millisecond))
 
@@ -66,15 +66,27 @@ func ExampleClientProxy_Locker() {
 		// return ErrLockNotExist if the key does not exist
 		// return ErrNotOwnerOfKey if the uuid invalid
 		// support reentrant unlock
-		if err := l.Unlock(context.Background(), "locker_key", uuid); err != nil {
+		if err := lock.Release(context.Background()); err != nil {
 			fmt.Printf("unlock fail. error: %v\n", err)
 		}
 	}()
 
+	// the fencing token increases on each acquisition of the key,
+	// the downstream should reject the writes with a lower token
+	fmt.Printf("fencing token: %d\n", lock.Token())
+
+	// Done closed when the lock released, or lost by renewal fail or owned by others
+	select {
+	case <-lock.Done():
+		fmt.Printf("lock lost. error: %v\n", lock.Err())
+		return
+	default:
+	}
+
 	// reentrant lock when uuid not empty
 	// will block the current goroutine until lock is acquired when not reentrant lock
-	_, err = l.Lock(context.Background(), "locker_key",
-		redis.WithLockUUID(uuid),
+	inner, err := l.Lock(context.Background(), "locker_key",
+		redis.WithLockUUID(lock.UUID()),
 		redis.WithLockExpire(1000*time.Millisecond),
 		redis.WithLockHeartbeat(500*time.Millisecond))
 
@@ -82,6 +94,9 @@ func ExampleClientProxy_Locker() {
 		fmt.Printf("lock fail. error: %v\n", err)
 		return
 	}
+
+	// reentrant unlock
+	defer inner.Release(context.Background())
 }
 
 func ExampleClientProxy_Fetcher() {
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"time"
 
 	redigo "github.com/gomodule/redigo/redis"
 	"github.com/wwwangxc/gopkg/singleflight"
@@ -17,6 +18,19 @@ type FetcherProxy interface {
 	//
 	// Use json decode
 	Fetch(ctx context.Context, key string, dest interface{}, opts ...FetchOption) error
+
+	// Set marshal the value into the key with expire.
+	//
+	// Use json encode.
+	// The local caches of all processes will drop the key when LocalCache
+	// option not empty.
+	Set(ctx context.Context, key string, value interface{}, expire time.Duration, opts ...FetchOption) error
+
+	// Delete the key.
+	//
+	// The local caches of all processes will drop the key when LocalCache
+	// option not empty.
+	Delete(ctx context.Context, key string, opts ...FetchOption) error
 }
 
 type fetcherImpl struct {
@@ -37,55 +51,163 @@ func NewFetcherProxy(name string, opts ...ClientOption) FetcherProxy {
 // Use json decode
 func (f *fetcherImpl) Fetch(ctx context.Context, key string, dest interface{}, opts ...FetchOption) error {
 	options := newFetchOptions(opts...)
+	cache := options.LocalCache
+	if cache != nil && !cache.ready(f.name, f.opts) {
+		cache = nil
+	}
+
+	var version localVersion
+	if cache != nil {
+		if entry, ok := cache.get(key); ok {
+			if entry.notExist {
+				return ErrKeyNotExist
+			}
+
+			return options.Unmarshal(entry.data, dest)
+		}
+
+		version = cache.currentVersion(key)
+	}
+
+	data, err := f.loadShared(ctx, key, options)
+	if cache != nil {
+		switch {
+		case err == nil:
+			cache.set(key, data, version)
+		case IsKeyNotExist(err):
+			cache.setNotExist(key, version)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return options.Unmarshal(data, dest)
+}
+
+// Set marshal the value into the key with expire.
+func (f *fetcherImpl) Set(ctx context.Context, key string, value interface{}, expire time.Duration,
+	opts ...FetchOption) error {
+	options := newFetchOptions(opts...)
+	data, err := options.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	pool := f.getPool()
+	if _, err = doContext(ctx, pool, "SET", key, data, "PX", expire.Milliseconds()); err != nil {
+		return err
+	}
+
+	f.invalidate(ctx, pool, key, options)
+	return nil
+}
+
+// Delete the key.
+func (f *fetcherImpl) Delete(ctx context.Context, key string, opts ...FetchOption) error {
+	options := newFetchOptions(opts...)
+	pool := f.getPool()
+	if _, err := doContext(ctx, pool, "DEL", key); err != nil {
+		return err
+	}
+
+	f.invalidate(ctx, pool, key, options)
+	return nil
+}
+
+// loadShared load the data, the concurrent loads of the key are shared
+// when the singleflight option not empty
+func (f *fetcherImpl) loadShared(ctx context.Context, key string, options *FetchOptions) ([]byte, error) {
 	if options.ExpireSingleflight == 0 {
-		return f.fetch(ctx, key, dest, options)
+		return f.load(ctx, key, options)
 	}
 
-	_, err := singleflight.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
-		return nil, f.fetch(ctx, key, dest, options)
+	data, err := singleflight.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return f.load(ctx, key, options)
 	}, singleflight.WithExpiresIn(options.ExpireSingleflight))
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	b, _ := data.([]byte)
+	return b, nil
 }
 
-func (f *fetcherImpl) fetch(ctx context.Context, key string, dest interface{}, options *FetchOptions) error {
-	conn := f.getConn()
-	defer func() {
-		if err := conn.Close(); err != nil {
-			logErrorf("conn close fail. error:%v", err)
-		}
-	}()
-
-	data, err := Bytes(redigo.DoContext(conn, ctx, "GET", key))
+func (f *fetcherImpl) load(ctx context.Context, key string, options *FetchOptions) ([]byte, error) {
+	// read from replicas when configured, and always write to the primary
+	pool := f.getPool()
+	data, err := Bytes(doContext(ctx, pool, "GET", key))
 	if err != nil && !errors.Is(redigo.ErrNil, err) {
-		return err
+		return nil, err
 	}
 
 	if errors.Is(redigo.ErrNil, err) {
 
 		if options.Callback == nil {
-			return ErrKeyNotExist
+			return nil, ErrKeyNotExist
 		}
 
 		val, err := options.Callback()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		data, err = options.Marshal(val)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		_, err = redigo.DoContext(conn, ctx, "PSETEX", key, options.Expire.Milliseconds(), data)
-		if err != nil {
-			return err
+		return f.fill(ctx, pool, key, data, options)
+	}
+
+	return data, nil
+}
+
+// fill set the data loaded by callback when the key not exist, returns
+// the newer value set by the others after the key read
+func (f *fetcherImpl) fill(ctx context.Context, pool connPool, key string, data []byte,
+	options *FetchOptions) ([]byte, error) {
+	_, err := String(doContext(ctx, pool, "SET", key, data, "PX", options.Expire.Milliseconds(), "NX"))
+	if errors.Is(redigo.ErrNil, err) {
+		// the replicas may not have the newer value yet
+		newer, err := Bytes(doContext(WithReadPrimary(ctx), pool, "GET", key))
+		if errors.Is(redigo.ErrNil, err) {
+			return data, nil
 		}
+
+		return newer, err
 	}
 
-	return options.Unmarshal(data, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	// the others may cache ErrKeyNotExist
+	if options.LocalCache != nil {
+		if _, err = doContext(ctx, pool, "PUBLISH", options.LocalCache.options.Channel, key); err != nil {
+			logErrorf("fetcher:%s invalidate fail. error:%v", key, err)
+		}
+	}
 
+	return data, nil
 }
-func (f *fetcherImpl) getConn() redigo.Conn {
-	return getRedisPool(f.name, f.opts...).Get()
+
+// invalidate drop the key of local caches of all processes
+//
+// The publish failure is logged but not returned, since the key has been
+// written, and the other processes keep the stale value until the TTL.
+func (f *fetcherImpl) invalidate(ctx context.Context, pool connPool, key string, options *FetchOptions) {
+	if options.LocalCache == nil {
+		return
+	}
+
+	options.LocalCache.invalidate(key)
+	if _, err := doContext(ctx, pool, "PUBLISH", options.LocalCache.options.Channel, key); err != nil {
+		logErrorf("fetcher:%s invalidate fail. error:%v", key, err)
+	}
+}
+
+func (f *fetcherImpl) getPool() connPool {
+	return getRedisPool(f.name, f.opts...)
 }
@@ -9,13 +9,14 @@ import (
 
 	"github.com/agiledragon/gomonkey"
 	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
 )
 
-func Test_fetcherImpl_fetch(t *testing.T) {
+func Test_fetcherImpl_load(t *testing.T) {
 	type args struct {
 		ctx  context.Context
 		key  string
-		dest interface{}
 		opts []FetchOption
 	}
 	tests := []struct {
@@ -65,9 +66,6 @@ func Test_fetcherImpl_fetch(t *testing.T) {
 		{
 			name:    "normal process",
 			wantErr: false,
-			args: args{
-				dest: &map[string]string{},
-			},
 		},
 	}
 	for _, tt := range tests {
@@ -87,9 +85,195 @@ func Test_fetcherImpl_fetch(t *testing.T) {
 			f := &fetcherImpl{
 				name: "client_name",
 			}
-			if err := f.fetch(tt.args.ctx, tt.args.key, tt.args.dest, newFetchOptions(tt.args.opts...)); (err != nil) != tt.wantErr {
-				t.Errorf("fetcherImpl.Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			if _, err := f.load(tt.args.ctx, tt.args.key, newFetchOptions(tt.args.opts...)); (err != nil) != tt.wantErr {
+				t.Errorf("fetcherImpl.load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_fetcherImpl_fill(t *testing.T) {
+	tests := []struct {
+		name        string
+		setReply    interface{}
+		setErr      error
+		getReply    interface{}
+		want        []byte
+		wantErr     bool
+		wantPublish int
+	}{
+		{
+			name:    "set fail",
+			setErr:  fmt.Errorf("ERR fail"),
+			wantErr: true,
+		},
+		{
+			name:        "filled",
+			setReply:    "OK",
+			want:        []byte(`"v1"`),
+			wantPublish: 1,
+		},
+		{
+			name:     "newer value exist",
+			getReply: []byte(`"v2"`),
+			want:     []byte(`"v2"`),
+		},
+		{
+			name: "newer value expired",
+			want: []byte(`"v1"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			set := conn.Command("SET", "k1", []byte(`"v1"`), "PX", int64(1000), "NX")
+			if tt.setErr != nil {
+				set.ExpectError(tt.setErr)
+			} else {
+				set.Expect(tt.setReply)
+			}
+			conn.Command("GET", "k1").Expect(tt.getReply)
+			publish := conn.Command("PUBLISH", "gopkg:fetcher:invalidate", "k1").Expect(int64(1))
+
+			cache := newSubscribedLocalCache()
+			defer cache.Close()
+
+			f := &fetcherImpl{name: "client_name"}
+			options := newFetchOptions(WithFetchCallback(nil, time.Second), WithFetchLocalCache(cache))
+			got, err := f.fill(context.Background(), &mockPool{conn: conn}, "k1", []byte(`"v1"`), options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fetcherImpl.fill() error = %v, wantErr %v", err, tt.wantErr)
+				return
 			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPublish, conn.Stats(publish))
 		})
 	}
 }
+
+func Test_fetcherImpl_Fetch_LocalCache(t *testing.T) {
+	conn := redigomock.NewConn()
+	get := conn.Command("GET", "k1").Expect([]byte(`{"a":"1"}`))
+	notExist := conn.Command("GET", "k2").Expect(nil)
+
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return &mockPool{conn: conn}
+		})
+	defer patches.Reset()
+
+	cache := newSubscribedLocalCache(WithLocalCacheNegativeTTL(time.Second))
+	defer cache.Close()
+
+	f := NewFetcherProxy("client_name")
+	for i := 0; i < 3; i++ {
+		dest := map[string]string{}
+		assert.Nil(t, f.Fetch(context.Background(), "k1", &dest, WithFetchLocalCache(cache)))
+		assert.Equal(t, map[string]string{"a": "1"}, dest)
+
+		assert.Equal(t, ErrKeyNotExist, f.Fetch(context.Background(), "k2", &dest, WithFetchLocalCache(cache)))
+	}
+
+	assert.Equal(t, 1, conn.Stats(get))
+	assert.Equal(t, 1, conn.Stats(notExist))
+
+	// dropped by the invalidation of the others
+	cache.invalidate("k1")
+	dest := map[string]string{}
+	assert.Nil(t, f.Fetch(context.Background(), "k1", &dest, WithFetchLocalCache(cache)))
+	assert.Equal(t, 2, conn.Stats(get))
+}
+
+func Test_fetcherImpl_Set(t *testing.T) {
+	tests := []struct {
+		name        string
+		cache       bool
+		setErr      error
+		publishErr  error
+		wantErr     bool
+		wantPublish int
+	}{
+		{
+			name:    "set fail",
+			cache:   true,
+			setErr:  fmt.Errorf("ERR fail"),
+			wantErr: true,
+		},
+		{
+			name: "without local cache",
+		},
+		{
+			name:        "with local cache",
+			cache:       true,
+			wantPublish: 1,
+		},
+		{
+			name:        "publish fail",
+			cache:       true,
+			publishErr:  fmt.Errorf("ERR fail"),
+			wantPublish: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			set := conn.Command("SET", "k1", []byte(`{"a":"1"}`), "PX", int64(1000))
+			if tt.setErr != nil {
+				set.ExpectError(tt.setErr)
+			} else {
+				set.Expect("OK")
+			}
+			publish := conn.Command("PUBLISH", "gopkg:fetcher:invalidate", "k1")
+			if tt.publishErr != nil {
+				publish.ExpectError(tt.publishErr)
+			} else {
+				publish.Expect(int64(1))
+			}
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			var opts []FetchOption
+			cache := newSubscribedLocalCache()
+			defer cache.Close()
+			cache.set("k1", []byte(`{"a":"0"}`), cache.currentVersion("k1"))
+			if tt.cache {
+				opts = append(opts, WithFetchLocalCache(cache))
+			}
+
+			err := NewFetcherProxy("client_name").Set(context.Background(), "k1", map[string]string{"a": "1"},
+				time.Second, opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fetcherImpl.Set() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			_, cached := cache.get("k1")
+			assert.Equal(t, tt.wantPublish == 0, cached)
+			assert.Equal(t, tt.wantPublish, conn.Stats(publish))
+		})
+	}
+}
+
+func Test_fetcherImpl_Delete(t *testing.T) {
+	conn := redigomock.NewConn()
+	del := conn.Command("DEL", "k1").Expect(int64(1))
+	publish := conn.Command("PUBLISH", "channel", "k1").Expect(int64(1))
+
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return &mockPool{conn: conn}
+		})
+	defer patches.Reset()
+
+	cache := newSubscribedLocalCache(WithLocalCacheChannel("channel"))
+	defer cache.Close()
+
+	assert.Nil(t, NewFetcherProxy("client_name").Delete(context.Background(), "k1", WithFetchLocalCache(cache)))
+	assert.Equal(t, 1, conn.Stats(del))
+	assert.Equal(t, 1, conn.Stats(publish))
+}
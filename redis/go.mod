@@ -9,7 +9,7 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/rafaeljusto/redigomock/v3 v3.1.1
 	github.com/stretchr/testify v1.8.0
-	github.com/wwwangxc/gopkg/config v0.1.0
+	github.com/wwwangxc/gopkg/config v0.2.0
 	github.com/wwwangxc/gopkg/singleflight v0.1.0
 )
 
@@ -17,9 +17,15 @@ require (
 	github.com/BurntSushi/toml v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
+	github.com/hashicorp/hcl v1.0.0 // indirect
+	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/spf13/cast v1.4.1 // indirect
 	golang.org/x/sync v0.6.0 // indirect
 	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
+	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// keep until config v0.2.0 is tagged and published, see README.md#development
+replace github.com/wwwangxc/gopkg/config => ../config
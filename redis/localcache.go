@@ -0,0 +1,402 @@
+package redis
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LocalCachePolicy the eviction policy of local cache
+type LocalCachePolicy int
+
+const (
+	// LocalCacheLRU evict the least recently used key
+	LocalCacheLRU LocalCachePolicy = iota
+
+	// LocalCacheLFU evict the least frequently used key, and the least
+	// recently used one of the same frequency
+	LocalCacheLFU
+)
+
+// LocalCache the in-process cache tier in front of redis for FetcherProxy
+//
+// It subscribes the invalidation channel of the redis client on the first
+// use, and the cached values are served only after subscribed. The cache
+// is purged when the subscription lost, so that the missed invalidations
+// do not keep the stale values.
+type LocalCache struct {
+	options *LocalCacheOptions
+	ctx     context.Context
+	cancel  context.CancelFunc
+	once    sync.Once
+
+	mu         sync.Mutex
+	store      localStore
+	subscribed bool
+
+	// epoch is increased by each purge, and the version of the hash bucket
+	// of key is increased by each invalidation of the keys in it. The value
+	// loaded before the purge or the invalidation will not be cached.
+	epoch    uint64
+	versions [localCacheVersionBuckets]uint64
+}
+
+// localCacheVersionBuckets the number of the hash buckets of key versions,
+// the invalidation of a key only affects the keys in the same bucket
+const localCacheVersionBuckets = 256
+
+// localVersion the version of key before loading from redis
+type localVersion struct {
+	epoch uint64
+	key   uint64
+}
+
+// NewLocalCache new in-process cache tier, use it with WithFetchLocalCache
+//
+// It should be created once and shared, the subscription is kept until
+// Close called.
+func NewLocalCache(opts ...LocalCacheOption) *LocalCache {
+	options := newLocalCacheOptions(opts...)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var store localStore
+	switch options.Policy {
+	case LocalCacheLFU:
+		store = newLFUStore(options.Size)
+	default:
+		store = newLRUStore(options.Size)
+	}
+
+	return &LocalCache{
+		options: options,
+		ctx:     ctx,
+		cancel:  cancel,
+		store:   store,
+	}
+}
+
+// Close unsubscribe the invalidation channel and purge the cache
+func (c *LocalCache) Close() {
+	c.cancel()
+	c.setSubscribed(false)
+}
+
+// ready start the subscription on the client at the first time, and
+// returns whether the cache can be served
+func (c *LocalCache) ready(name string, opts []ClientOption) bool {
+	c.once.Do(func() {
+		go c.subscribe(name, opts)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subscribed
+}
+
+// subscribe subscribe the invalidation channel until closed, the first
+// subscription is retried with backoff
+func (c *LocalCache) subscribe(name string, opts []ClientOption) {
+	s := &subscriberImpl{
+		name: name,
+		opts: opts,
+	}
+
+	options := newSubscribeOptions()
+	sub := s.newSubscription(false, []string{c.options.Channel}, options, func(msg *Message) {
+		c.invalidate(string(msg.Data))
+	})
+	sub.notify = c.setSubscribed
+
+	backoff := options.MinBackoff
+	for {
+		psc, err := sub.subscribe(c.ctx)
+		if err == nil {
+			c.setSubscribed(true)
+			sub.run(c.ctx, psc)
+			return
+		}
+
+		logErrorf("local cache subscribe %s fail, retry after %v. error:%v", c.options.Channel, backoff, err)
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > options.MaxBackoff {
+			backoff = options.MaxBackoff
+		}
+	}
+}
+
+// setSubscribed purge the cache when the subscription changed
+func (c *LocalCache) setSubscribed(subscribed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	c.store.purge()
+	c.subscribed = subscribed && c.ctx.Err() == nil
+}
+
+// get returns the cached entry of the key, the expired one is removed
+func (c *LocalCache) get(key string) (*localEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.store.get(key)
+	if !ok {
+		return nil, false
+	}
+
+	if !time.Now().Before(entry.expireAt) {
+		c.store.del(key)
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// currentVersion returns the version of key before loading from redis
+func (c *LocalCache) currentVersion(key string) localVersion {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.versionOf(key)
+}
+
+// versionOf returns the version of key, the caller must hold the lock
+func (c *LocalCache) versionOf(key string) localVersion {
+	return localVersion{
+		epoch: c.epoch,
+		key:   c.versions[versionBucket(key)],
+	}
+}
+
+// versionBucket returns the hash bucket of key by FNV-1a
+func versionBucket(key string) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+
+	return int(h % localCacheVersionBuckets)
+}
+
+// set cache the value loaded at the version
+func (c *LocalCache) set(key string, data []byte, version localVersion) {
+	c.put(key, &localEntry{
+		data:     data,
+		expireAt: time.Now().Add(c.options.TTL),
+	}, version)
+}
+
+// setNotExist cache ErrKeyNotExist of the key loaded at the version
+func (c *LocalCache) setNotExist(key string, version localVersion) {
+	if c.options.NegativeTTL <= 0 {
+		return
+	}
+
+	c.put(key, &localEntry{
+		notExist: true,
+		expireAt: time.Now().Add(c.options.NegativeTTL),
+	}, version)
+}
+
+func (c *LocalCache) put(key string, entry *localEntry, version localVersion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.versionOf(key) != version || !c.subscribed {
+		return
+	}
+
+	c.store.set(key, entry)
+}
+
+// invalidate drop the key
+func (c *LocalCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versions[versionBucket(key)]++
+	c.store.del(key)
+}
+
+// localEntry the cached value, or ErrKeyNotExist when notExist
+type localEntry struct {
+	data     []byte
+	notExist bool
+	expireAt time.Time
+}
+
+// localStore the bounded key-value store with eviction policy
+type localStore interface {
+	get(key string) (*localEntry, bool)
+	set(key string, entry *localEntry)
+	del(key string)
+	purge()
+}
+
+type lruItem struct {
+	key   string
+	entry *localEntry
+}
+
+type lruStore struct {
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+func newLRUStore(size int) *lruStore {
+	return &lruStore{
+		size:  size,
+		items: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+func (s *lruStore) get(key string) (*localEntry, bool) {
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.order.MoveToFront(elem)
+	return elem.Value.(*lruItem).entry, true
+}
+
+func (s *lruStore) set(key string, entry *localEntry) {
+	if s.size <= 0 {
+		return
+	}
+
+	if elem, ok := s.items[key]; ok {
+		elem.Value.(*lruItem).entry = entry
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	if s.order.Len() >= s.size {
+		s.del(s.order.Back().Value.(*lruItem).key)
+	}
+
+	s.items[key] = s.order.PushFront(&lruItem{key: key, entry: entry})
+}
+
+func (s *lruStore) del(key string) {
+	if elem, ok := s.items[key]; ok {
+		s.order.Remove(elem)
+		delete(s.items, key)
+	}
+}
+
+func (s *lruStore) purge() {
+	s.items = map[string]*list.Element{}
+	s.order.Init()
+}
+
+type lfuItem struct {
+	key    string
+	entry  *localEntry
+	bucket *list.Element
+}
+
+type lfuBucket struct {
+	freq  int
+	items *list.List
+}
+
+// lfuStore the O(1) LFU store, the buckets are in ascending frequency and
+// the items of a bucket are in most recently used order
+type lfuStore struct {
+	size    int
+	items   map[string]*list.Element
+	buckets *list.List
+}
+
+func newLFUStore(size int) *lfuStore {
+	return &lfuStore{
+		size:    size,
+		items:   map[string]*list.Element{},
+		buckets: list.New(),
+	}
+}
+
+func (s *lfuStore) get(key string) (*localEntry, bool) {
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.touch(key, elem)
+	return elem.Value.(*lfuItem).entry, true
+}
+
+func (s *lfuStore) set(key string, entry *localEntry) {
+	if s.size <= 0 {
+		return
+	}
+
+	if elem, ok := s.items[key]; ok {
+		elem.Value.(*lfuItem).entry = entry
+		s.touch(key, elem)
+		return
+	}
+
+	if len(s.items) >= s.size {
+		items := s.buckets.Front().Value.(*lfuBucket).items
+		s.del(items.Back().Value.(*lfuItem).key)
+	}
+
+	front := s.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).freq != 1 {
+		front = s.buckets.PushFront(&lfuBucket{freq: 1, items: list.New()})
+	}
+
+	item := &lfuItem{key: key, entry: entry, bucket: front}
+	s.items[key] = front.Value.(*lfuBucket).items.PushFront(item)
+}
+
+// touch move the item to the bucket of the next frequency
+func (s *lfuStore) touch(key string, elem *list.Element) {
+	item := elem.Value.(*lfuItem)
+	cur := item.bucket
+	bucket := cur.Value.(*lfuBucket)
+
+	next := cur.Next()
+	if next == nil || next.Value.(*lfuBucket).freq != bucket.freq+1 {
+		next = s.buckets.InsertAfter(&lfuBucket{freq: bucket.freq + 1, items: list.New()}, cur)
+	}
+
+	bucket.items.Remove(elem)
+	if bucket.items.Len() == 0 {
+		s.buckets.Remove(cur)
+	}
+
+	item.bucket = next
+	s.items[key] = next.Value.(*lfuBucket).items.PushFront(item)
+}
+
+func (s *lfuStore) del(key string) {
+	elem, ok := s.items[key]
+	if !ok {
+		return
+	}
+
+	item := elem.Value.(*lfuItem)
+	bucket := item.bucket.Value.(*lfuBucket)
+	bucket.items.Remove(elem)
+	if bucket.items.Len() == 0 {
+		s.buckets.Remove(item.bucket)
+	}
+
+	delete(s.items, key)
+}
+
+func (s *lfuStore) purge() {
+	s.items = map[string]*list.Element{}
+	s.buckets.Init()
+}
@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSubscribedLocalCache returns the local cache served without the
+// invalidation subscription
+func newSubscribedLocalCache(opts ...LocalCacheOption) *LocalCache {
+	c := NewLocalCache(opts...)
+	c.once.Do(func() {})
+	c.subscribed = true
+	return c
+}
+
+func Test_lruStore(t *testing.T) {
+	s := newLRUStore(2)
+	s.set("k1", &localEntry{data: []byte("v1")})
+	s.set("k2", &localEntry{data: []byte("v2")})
+
+	// k2 is the least recently used after k1 read
+	_, ok := s.get("k1")
+	assert.True(t, ok)
+
+	s.set("k3", &localEntry{data: []byte("v3")})
+	_, ok = s.get("k2")
+	assert.False(t, ok)
+
+	for _, key := range []string{"k1", "k3"} {
+		_, ok = s.get(key)
+		assert.True(t, ok, key)
+	}
+
+	s.del("k1")
+	_, ok = s.get("k1")
+	assert.False(t, ok)
+
+	s.purge()
+	_, ok = s.get("k3")
+	assert.False(t, ok)
+}
+
+func Test_lfuStore(t *testing.T) {
+	s := newLFUStore(2)
+	s.set("k1", &localEntry{data: []byte("v1")})
+	s.set("k2", &localEntry{data: []byte("v2")})
+
+	// k2 is the least frequently used after k1 read twice
+	s.get("k1")
+	s.get("k1")
+	s.get("k2")
+
+	s.set("k3", &localEntry{data: []byte("v3")})
+	_, ok := s.get("k2")
+	assert.False(t, ok)
+
+	// k3 is evicted as the least frequently used
+	s.set("k4", &localEntry{data: []byte("v4")})
+	_, ok = s.get("k3")
+	assert.False(t, ok)
+
+	entry, ok := s.get("k1")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v1"), entry.data)
+
+	s.del("k1")
+	s.del("k4")
+	assert.Equal(t, 0, s.buckets.Len())
+	assert.Empty(t, s.items)
+}
+
+func TestLocalCache(t *testing.T) {
+	c := newSubscribedLocalCache(WithLocalCacheTTL(50*time.Millisecond),
+		WithLocalCacheNegativeTTL(time.Second))
+	defer c.Close()
+
+	c.set("k1", []byte("v1"), c.currentVersion("k1"))
+	version := c.currentVersion("k2")
+	c.setNotExist("k2", version)
+
+	entry, ok := c.get("k1")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v1"), entry.data)
+
+	entry, ok = c.get("k2")
+	assert.True(t, ok)
+	assert.True(t, entry.notExist)
+
+	// loaded before the invalidation
+	c.invalidate("k2")
+	c.setNotExist("k2", version)
+	_, ok = c.get("k2")
+	assert.False(t, ok)
+
+	// not affected by the invalidation of the key in other bucket
+	k3 := "k3"
+	for versionBucket(k3) == versionBucket("k2") {
+		k3 += "_"
+	}
+	version = c.currentVersion(k3)
+	c.invalidate("k2")
+	c.set(k3, []byte("v3"), version)
+	_, ok = c.get(k3)
+	assert.True(t, ok)
+
+	// expired
+	time.Sleep(60 * time.Millisecond)
+	_, ok = c.get("k1")
+	assert.False(t, ok)
+
+	// purged and not served when the subscription lost
+	c.set("k1", []byte("v1"), c.currentVersion("k1"))
+	c.setSubscribed(false)
+	_, ok = c.get("k1")
+	assert.False(t, ok)
+	assert.False(t, c.ready("client_name", nil))
+}
+
+func TestLocalCache_subscribe(t *testing.T) {
+	pool := &mockPool{
+		conns: []redigo.Conn{
+			newSubscribedConn("SUBSCRIBE", "channel", "k1"),
+		},
+	}
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return pool
+		})
+	defer patches.Reset()
+
+	c := NewLocalCache(WithLocalCacheChannel("channel"))
+	defer c.Close()
+
+	// not served until subscribed
+	assert.False(t, c.ready("client_name", nil))
+
+	// purged on subscribed and lost, and invalidated by k1
+	assert.Eventually(t, func() bool {
+		return c.currentVersion("k1") == localVersion{epoch: 2, key: 1}
+	}, time.Second, time.Millisecond)
+	assert.False(t, c.ready("client_name", nil))
+}
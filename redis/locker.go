@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	redigo "github.com/gomodule/redigo/redis"
@@ -23,22 +25,22 @@ type LockerProxy interface {
 	// renewal until unlocked.
 	LockAndCall(ctx context.Context, key string, f func() error, opts ...LockOption) error
 
-	// TryLock try get lock, if lock acquired will return lock uuid.
+	// TryLock try get lock, if lock acquired will return the lock handle.
 	//
 	// Not block the current goroutine.
 	// Return ErrLockNotAcquired when lock not acquired.
 	// Will reentrant lock when UUID option not empty.
-	// If Heartbeat option not empty and not a reentrant lock, will automatically
-	// renewal until unlocked.
-	TryLock(ctx context.Context, key string, opts ...LockOption) (uuid string, err error)
+	// If Heartbeat option not empty, will automatically renewal until released
+	// or lost.
+	TryLock(ctx context.Context, key string, opts ...LockOption) (Lock, error)
 
 	// Lock try get lock until the context canceled or the lock acquired
 	//
 	// Will block the current goroutine.
 	// Will reentrant lock when UUID option not empty.
-	// If Heartbeat option not empty and not a reentrant lock, will automatically
-	// renewal until unlocked.
-	Lock(ctx context.Context, key string, opts ...LockOption) (uuid string, err error)
+	// If Heartbeat option not empty, will automatically renewal until released
+	// or lost.
+	Lock(ctx context.Context, key string, opts ...LockOption) (Lock, error)
 
 	// Unlock
 	//
@@ -48,6 +50,44 @@ type LockerProxy interface {
 	Unlock(ctx context.Context, key, uuid string) error
 }
 
+// Lock the handle of acquired distributed lock
+type Lock interface {
+
+	// Key returns the key of lock
+	Key() string
+
+	// UUID returns the uuid of lock, lock with WithLockUUID(UUID()) to
+	// reenter it.
+	UUID() string
+
+	// Token returns the fencing token, which increases on each acquisition
+	// of the key and stays the same on reentry. The downstream should reject
+	// the writes with a token lower than it has seen.
+	Token() int64
+
+	// Done returns a channel that's closed when the lock released or lost.
+	//
+	// The lock is lost when the renewal fail until the lock expired, or the
+	// lock is owned by others. Without Heartbeat option, the lock is lost
+	// when expired.
+	Done() <-chan struct{}
+
+	// Err returns nil if Done is not yet closed, ErrLockReleased if released
+	// and ErrLockLost if lost.
+	Err() error
+
+	// Refresh extends the lock expire.
+	//
+	// Return ErrLockLost when not the owner of lock anymore.
+	Refresh(ctx context.Context) error
+
+	// Release unlock the lock and close Done.
+	//
+	// Return ErrLockNotExist if the key does not exist.
+	// Return ErrNotOwnerOfKey if the uuid invalid.
+	Release(ctx context.Context) error
+}
+
 type lockerImpl struct {
 	name string
 	opts []ClientOption
@@ -75,14 +115,14 @@ func (l *lockerImpl) LockAndCall(ctx context.Context, key string, f func() error
 		return nil
 	}
 
-	uuid, err := l.Lock(ctx, key, opts...)
+	lock, err := l.Lock(ctx, key, opts...)
 	if err != nil {
 		err = fmt.Errorf("lock fail case %v", err)
 		return err
 	}
 
 	defer func() {
-		if err := l.Unlock(ctx, key, uuid); err != nil {
+		if err := lock.Release(ctx); err != nil {
 			logErrorf("lock:%s unlock fail: %v", key, err)
 		}
 	}()
@@ -90,17 +130,70 @@ func (l *lockerImpl) LockAndCall(ctx context.Context, key string, f func() error
 	return f()
 }
 
-// TryLock try get lock, if lock acquired will return lock uuid.
+// TryLock try get lock, if lock acquired will return the lock handle.
 //
 // Not block the current goroutine.
 // Return ErrLockNotAcquired when lock not acquired.
 // Will reentrant lock when UUID option not empty.
-// If Heartbeat option not empty and not a reentrant lock, will automatically
-// renewal until unlocked.
-func (l *lockerImpl) TryLock(ctx context.Context, key string, opts ...LockOption) (string, error) {
-	k := fmt.Sprintf("%s.lock", strings.TrimSuffix(key, ".lock"))
+// If Heartbeat option not empty, will automatically renewal until released
+// or lost.
+func (l *lockerImpl) TryLock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
 	options := newLockOptions(opts...)
-	script := redigo.NewScript(1, luaScriptLock)
+
+	// the deadline counts from before sent, as the lock may expire earlier
+	// than the reply received
+	start := time.Now()
+	token, err := l.acquire(ctx, key, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLock(key, options, token, start.Add(options.Expire),
+		func(ctx context.Context) (time.Time, error) {
+			start := time.Now()
+			owned, err := l.refresh(ctx, key, options.UUID, options.Expire, token)
+			if err != nil || !owned {
+				return time.Time{}, err
+			}
+
+			return start.Add(options.Expire), nil
+		},
+		func(ctx context.Context) error {
+			return l.Unlock(ctx, key, options.UUID)
+		}), nil
+}
+
+// Lock try get lock until the context canceled or the lock acquired
+//
+// Will block the current goroutine.
+// Will reentrant lock when UUID option not empty.
+// If Heartbeat option not empty, will automatically renewal until released
+// or lost.
+//
+// The waiter subscribes the release notification published by Unlock and
+// retries right away when notified, and polls with the jittered Retry
+// interval in case the lock expired or the notification lost.
+func (l *lockerImpl) Lock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
+	return waitLock(ctx, l.name, l.opts, lockKey(key), newLockOptions(opts...).Retry, func() (Lock, error) {
+		return l.TryLock(ctx, key, opts...)
+	})
+}
+
+// Unlock
+//
+// Return ErrLockNotExist if the key does not exist.
+// Return ErrNotOwnerOfKey if the uuid invalid.
+// Support reentrant unlock.
+func (l *lockerImpl) Unlock(ctx context.Context, key, uuid string) error {
+	return unlock(ctx, getRedisPool(l.name, l.opts...), lockKey(key), uuid)
+}
+
+// acquire run the lock script and returns the fencing token
+//
+// Return ErrLockNotAcquired when lock not acquired.
+func (l *lockerImpl) acquire(ctx context.Context, key string, options *LockOptions) (int64, error) {
+	k := lockKey(key)
+	script := redigo.NewScript(2, luaScriptLock)
 
 	conn := l.getConn()
 	defer func() {
@@ -109,68 +202,273 @@ func (l *lockerImpl) TryLock(ctx context.Context, key string, opts ...LockOption
 		}
 	}()
 
-	lockCount, err := Int(script.DoContext(ctx, conn, k, options.UUID, options.Expire.Milliseconds()))
+	ret, err := Int64s(script.DoContext(ctx, conn, k, lockFenceKey(k), options.UUID, options.Expire.Milliseconds(),
+		lockFenceExpire.Milliseconds()))
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	if lockCount == 0 {
-		return "", ErrLockNotAcquired
+	if len(ret) != 2 {
+		return 0, fmt.Errorf("invalid lock reply %v", ret)
 	}
 
-	if lockCount == 1 && options.Heartbeat > 0 {
-		go l.sendLockHeartbeat(key, options.Expire, options.Heartbeat)
+	if ret[0] == 0 {
+		return 0, ErrLockNotAcquired
 	}
 
-	return options.UUID, nil
+	return ret[1], nil
 }
 
-// Lock try get lock until the context canceled or the lock acquired
+// refresh extends the lock expire, returns false when not the owner
+func (l *lockerImpl) refresh(ctx context.Context, key, uuid string, expire time.Duration, token int64) (bool, error) {
+	return refreshLock(ctx, getRedisPool(l.name, l.opts...), lockKey(key), uuid, expire, token)
+}
+
+// waitLock call try until the lock acquired or the context canceled
 //
-// Will block the current goroutine.
-// Will reentrant lock when UUID option not empty.
-// If Heartbeat option not empty and not a reentrant lock, will automatically
-// renewal until unlocked.
-func (l *lockerImpl) Lock(ctx context.Context, key string, opts ...LockOption) (string, error) {
-	options := newLockOptions(opts...)
+// The waiter watches the channel published on release by the subscription
+// shared in process, and retries right away when notified, and polls with
+// the jittered retry interval in case the lock expired or the notification
+// lost. Only polls when channel is empty.
+func waitLock(ctx context.Context, name string, opts []ClientOption, channel string, retry time.Duration,
+	try func() (Lock, error)) (Lock, error) {
+	lock, err := try()
+	if !IsLockNotAcquired(err) {
+		return lock, err
+	}
+
+	// the nil channel never ready when polling only
+	var released <-chan struct{}
+	if channel != "" {
+		var stop func()
+		released, stop = getReleaseWatcher(name, opts).watch(channel)
+		defer stop()
+	}
+
 	for {
+		// the lock may be released before subscribed, so try first
+		lock, err := try()
+		if !IsLockNotAcquired(err) {
+			return lock, err
+		}
+
+		timer := time.NewTimer(jitter(retry))
 		select {
 		case <-ctx.Done():
-			return "", ErrTimeout
+			timer.Stop()
+			return nil, ErrTimeout
+		case <-released:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// jitter returns a random duration in [d/2, d*3/2)
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+func (l *lockerImpl) getConn() redigo.Conn {
+	return getRedisPool(l.name, l.opts...).Get()
+}
+
+type lockImpl struct {
+	key       string
+	uuid      string
+	token     int64
+	heartbeat time.Duration
+
+	// refresh extends the lock expire and returns the new deadline, the
+	// zero deadline means not the owner
+	refresh func(ctx context.Context) (time.Time, error)
+	release func(ctx context.Context) error
+
+	mu       sync.Mutex
+	deadline time.Time
+	err      error
+	done     chan struct{}
+
+	releaseOnce sync.Once
+	releaseErr  error
+}
+
+// newLock new the lock handle valid until deadline and start the watchdog
+func newLock(key string, options *LockOptions, token int64, deadline time.Time,
+	refresh func(ctx context.Context) (time.Time, error), release func(ctx context.Context) error) *lockImpl {
+	m := &lockImpl{
+		key:       key,
+		uuid:      options.UUID,
+		token:     token,
+		heartbeat: options.Heartbeat,
+		refresh:   refresh,
+		release:   release,
+		deadline:  deadline,
+		done:      make(chan struct{}),
+	}
+
+	go m.watch()
+	return m
+}
+
+// Key returns the key of lock
+func (m *lockImpl) Key() string {
+	return m.key
+}
+
+// UUID returns the uuid of lock
+func (m *lockImpl) UUID() string {
+	return m.uuid
+}
+
+// Token returns the fencing token
+func (m *lockImpl) Token() int64 {
+	return m.token
+}
 
-		default:
-			uuid, err := l.TryLock(ctx, key, opts...)
-			if err != nil {
-				if IsLockNotAcquired(err) {
-					time.Sleep(options.Retry)
-					continue
-				}
+// Done returns a channel that's closed when the lock released or lost
+func (m *lockImpl) Done() <-chan struct{} {
+	return m.done
+}
+
+// Err returns the reason of Done closed
+func (m *lockImpl) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.err
+}
+
+// Refresh extends the lock expire
+func (m *lockImpl) Refresh(ctx context.Context) error {
+	if err := m.Err(); err != nil {
+		return err
+	}
+
+	deadline, err := m.refresh(ctx)
+	if err != nil {
+		return err
+	}
 
-				return "", err
+	if deadline.IsZero() {
+		m.close(ErrLockLost)
+		return ErrLockLost
+	}
+
+	m.mu.Lock()
+	m.deadline = deadline
+	m.mu.Unlock()
+	return nil
+}
+
+// Release unlock the lock and close Done
+func (m *lockImpl) Release(ctx context.Context) error {
+	m.releaseOnce.Do(func() {
+		m.releaseErr = m.release(ctx)
+		m.close(ErrLockReleased)
+	})
+
+	return m.releaseErr
+}
+
+// watch renewal the lock every heartbeat, and close Done when the lock
+// expired without renewal
+func (m *lockImpl) watch() {
+	var heartbeat <-chan time.Time
+	if m.heartbeat > 0 {
+		ticker := time.NewTicker(m.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	timer := time.NewTimer(m.remaining())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+
+		case <-timer.C:
+			// the deadline may be extended by Refresh
+			if d := m.remaining(); d > 0 {
+				timer.Reset(d)
+				continue
 			}
 
-			return uuid, nil
+			logErrorf("lock:%s lost, expired without renewal", m.key)
+			m.close(ErrLockLost)
+			return
+
+		case <-heartbeat:
+			ctx, cancel := context.WithTimeout(context.Background(), m.heartbeat)
+			err := m.Refresh(ctx)
+			cancel()
+
+			if err != nil && !IsLockLost(err) && !IsLockReleased(err) {
+				logErrorf("lock:%s renewal fail, retry in next heartbeat. error:%v", m.key, err)
+			}
 		}
 	}
 }
 
-// Unlock
+func (m *lockImpl) remaining() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return time.Until(m.deadline)
+}
+
+func (m *lockImpl) close(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return
+	}
+
+	m.err = err
+	close(m.done)
+}
+
+// lockKey returns the redis key of lock
+func lockKey(key string) string {
+	return fmt.Sprintf("%s.lock", strings.TrimSuffix(key, ".lock"))
+}
+
+// lockFenceExpire the expire of fencing token counter, refreshed on each
+// acquire
 //
-// Return ErrLockNotExist if the key does not exist.
-// Return ErrNotOwnerOfKey if the uuid invalid.
-// Support reentrant unlock.
-func (l *lockerImpl) Unlock(ctx context.Context, key, uuid string) error {
-	k := fmt.Sprintf("%s.lock", strings.TrimSuffix(key, ".lock"))
-	script := redigo.NewScript(1, luaScriptUnlock)
+// The counter restarts from the server time in microseconds after expired,
+// which is greater than the tokens issued before, as the counter can not be
+// increased more than once per microsecond on average. So the token is still
+// monotonic after the counter expired.
+const lockFenceExpire = 7 * 24 * time.Hour
+
+// lockFenceKey returns the key of fencing token counter, which is in the
+// same cluster slot with the lock key
+func lockFenceKey(k string) string {
+	return hashTagKey(k, ".fence")
+}
 
-	conn := l.getConn()
-	defer func() {
-		if err := conn.Close(); err != nil {
-			logErrorf("connect close fail. error:%v", err)
+// hashTagKey returns k with suffix in the same cluster slot with k
+func hashTagKey(k, suffix string) string {
+	if start := strings.IndexByte(k, '{'); start >= 0 {
+		if end := strings.IndexByte(k[start+1:], '}'); end > 0 {
+			return k + suffix
 		}
-	}()
+	}
 
-	ret, err := Int(script.DoContext(ctx, conn, k, uuid))
+	return "{" + k + "}" + suffix
+}
+
+// unlock run the unlock script on the lock key k
+func unlock(ctx context.Context, pool connPool, k, uuid string) error {
+	ret, err := Int(evalScript(ctx, pool, redigo.NewScript(1, luaScriptUnlock), k, uuid))
 	if err != nil {
 		return err
 	}
@@ -189,38 +487,55 @@ func (l *lockerImpl) Unlock(ctx context.Context, key, uuid string) error {
 	return errors.New("error unknown")
 }
 
-func (l *lockerImpl) sendLockHeartbeat(key string, expire, heartbeatInterval time.Duration) {
-	conn := l.getConn()
-	defer func() {
-		if err := conn.Close(); err != nil {
-			logErrorf("connect close fail. error:%v", err)
-		}
-	}()
+// refreshLock run the refresh script on the lock key k, returns false when
+// not the owner
+func refreshLock(ctx context.Context, pool connPool, k, uuid string, expire time.Duration, token int64) (bool, error) {
+	ret, err := Int(evalScript(ctx, pool, redigo.NewScript(1, luaScriptRefresh), k, uuid, expire.Milliseconds(), token))
+	if err != nil {
+		return false, err
+	}
 
-	ticker := time.NewTicker(heartbeatInterval)
-	defer ticker.Stop()
+	return ret != 0, nil
+}
 
-	for {
-		<-ticker.C
+// refreshHolder extends the expire of holder uuid in the sorted set
+// holders, returns the zero deadline when not a holder
+func refreshHolder(ctx context.Context, pool connPool, holders, uuid string, expire time.Duration) (time.Time, error) {
+	start := time.Now()
+	ret, err := Int(evalScript(ctx, pool, redigo.NewScript(1, luaScriptHolderRefresh), holders, uuid,
+		expire.Milliseconds()))
+	if err != nil || ret == 0 {
+		return time.Time{}, err
+	}
 
-		exist, err := Bool(conn.Do("EXISTS", key))
-		if err != nil {
-			logErrorf(err.Error())
-			return
-		}
+	return start.Add(expire), nil
+}
 
-		if !exist {
-			return
-		}
+// releaseHolder remove the holder uuid from the sorted set holders, and
+// publish the release on channel
+//
+// Return ErrLockNotExist if not a holder.
+func releaseHolder(ctx context.Context, pool connPool, holders, channel, uuid string) error {
+	ret, err := Int(evalScript(ctx, pool, redigo.NewScript(2, luaScriptHolderRelease), holders, channel, uuid))
+	if err != nil {
+		return err
+	}
 
-		_, err = Bool(conn.Do("PEXPIRE", key, expire.Milliseconds()))
-		if err != nil {
-			logErrorf(err.Error())
-			return
-		}
+	if ret == 0 {
+		return ErrLockNotExist
 	}
+
+	return nil
 }
 
-func (l *lockerImpl) getConn() redigo.Conn {
-	return getRedisPool(l.name, l.opts...).Get()
+// evalScript run the script on a connection of pool
+func evalScript(ctx context.Context, pool connPool, script *redigo.Script, keysAndArgs ...interface{}) (interface{}, error) {
+	conn := pool.Get()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logErrorf("connect close fail. error:%v", err)
+		}
+	}()
+
+	return script.DoContext(ctx, conn, keysAndArgs...)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/agiledragon/gomonkey"
 	redigo "github.com/gomodule/redigo/redis"
 	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_lockerImpl_TryLock(t *testing.T) {
@@ -19,57 +20,71 @@ func Test_lockerImpl_TryLock(t *testing.T) {
 		opts []LockOption
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    string
-		wantErr bool
-		intRet  int
-		intErr  error
+		name      string
+		args      args
+		wantToken int64
+		wantErr   bool
+		reply     interface{}
+		scriptErr error
 	}{
 		{
-			name:    "int fail",
+			name:      "script fail",
+			wantErr:   true,
+			scriptErr: fmt.Errorf(""),
+		},
+		{
+			name:    "invalid reply",
 			wantErr: true,
-			intErr:  fmt.Errorf(""),
+			reply:   []interface{}{int64(1)},
 		},
 		{
 			name:    "lock not acquired",
 			wantErr: true,
-			intRet:  0,
+			reply:   []interface{}{int64(0), int64(0)},
 		},
 		{
-			name:    "normal process",
-			wantErr: false,
-			intRet:  2,
+			name:      "normal process",
+			wantErr:   false,
+			reply:     []interface{}{int64(1), int64(7)},
+			wantToken: 7,
+			args: args{
+				key:  "k1",
+				opts: []LockOption{WithLockUUID("uuid")},
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var cli *clientProxyImpl
-			patches := gomonkey.ApplyMethod(reflect.TypeOf(cli), "Conn",
-				func(*clientProxyImpl) redigo.Conn {
-					return redigomock.NewConn()
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{}
 				})
 			defer patches.Reset()
 
 			var script *redigo.Script
 			patches.ApplyMethod(reflect.TypeOf(script), "DoContext",
 				func(*redigo.Script, context.Context, redigo.Conn, ...interface{}) (interface{}, error) {
-					return nil, nil
-				})
-
-			patches.ApplyFunc(Int,
-				func(interface{}, error) (int, error) {
-					return tt.intRet, tt.intErr
+					return tt.reply, tt.scriptErr
 				})
 
 			l := &lockerImpl{
 				name: "client_name",
 			}
-			_, err := l.TryLock(tt.args.ctx, tt.args.key, tt.args.opts...)
+			got, err := l.TryLock(tt.args.ctx, tt.args.key, tt.args.opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("lockerImpl.TryLock() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+
+			if err != nil {
+				return
+			}
+
+			// stop the watchdog
+			defer got.(*lockImpl).close(ErrLockReleased)
+			if got.Key() != "k1" || got.UUID() != "uuid" || got.Token() != tt.wantToken {
+				t.Errorf("lockerImpl.TryLock() = %v, %v, %v", got.Key(), got.UUID(), got.Token())
+			}
 		})
 	}
 }
@@ -83,7 +98,7 @@ func Test_lockerImpl_Lock(t *testing.T) {
 	tests := []struct {
 		name       string
 		args       args
-		want       string
+		want       Lock
 		wantErr    bool
 		tryLockErr error
 	}{
@@ -110,8 +125,8 @@ func Test_lockerImpl_Lock(t *testing.T) {
 			}
 
 			patches := gomonkey.ApplyMethod(reflect.TypeOf(l), "TryLock",
-				func(*lockerImpl, context.Context, string, ...LockOption) (string, error) {
-					return "", tt.tryLockErr
+				func(*lockerImpl, context.Context, string, ...LockOption) (Lock, error) {
+					return nil, tt.tryLockErr
 				})
 			defer patches.Reset()
 
@@ -130,6 +145,58 @@ func Test_lockerImpl_Lock(t *testing.T) {
 	}
 }
 
+func Test_lockerImpl_LockWait(t *testing.T) {
+	tests := []struct {
+		name string
+		conn redigo.Conn
+		opts []LockOption
+	}{
+		{
+			name: "release notified",
+			conn: newSubscribedConn("SUBSCRIBE", "k1.lock", "1"),
+			opts: []LockOption{WithLockRetry(time.Hour)},
+		},
+		{
+			name: "subscribe fail and polling",
+			conn: redigomock.NewConn(),
+			opts: []LockOption{WithLockRetry(time.Millisecond)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useReleaseWatcher(t, "client_name", tt.conn)
+			patches := gomonkey.NewPatches()
+			defer patches.Reset()
+
+			l := &lockerImpl{
+				name: "client_name",
+			}
+
+			// acquired in the third try
+			calls := 0
+			patches.ApplyMethod(reflect.TypeOf(l), "TryLock",
+				func(*lockerImpl, context.Context, string, ...LockOption) (Lock, error) {
+					if calls++; calls < 3 {
+						return nil, ErrLockNotAcquired
+					}
+					return &lockImpl{uuid: "uuid"}, nil
+				})
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			got, err := l.Lock(ctx, "k1", tt.opts...)
+			if err != nil {
+				t.Errorf("lockerImpl.Lock() error = %v", err)
+				return
+			}
+			if got.UUID() != "uuid" || calls != 3 {
+				t.Errorf("lockerImpl.Lock() = %v, calls %v", got.UUID(), calls)
+			}
+		})
+	}
+}
+
 func Test_lockerImpl_Unlock(t *testing.T) {
 	type args struct {
 		ctx  context.Context
@@ -272,8 +339,16 @@ func Test_lockerImpl_LockAndCall(t *testing.T) {
 			}
 
 			patches := gomonkey.ApplyMethod(reflect.TypeOf(l), "Lock",
-				func(*lockerImpl, context.Context, string, ...LockOption) (string, error) {
-					return "", tt.lockErr
+				func(*lockerImpl, context.Context, string, ...LockOption) (Lock, error) {
+					if tt.lockErr != nil {
+						return nil, tt.lockErr
+					}
+					return &lockImpl{
+						done: make(chan struct{}),
+						release: func(ctx context.Context) error {
+							return l.Unlock(ctx, "", "")
+						},
+					}, nil
 				})
 			defer patches.Reset()
 
@@ -288,3 +363,92 @@ func Test_lockerImpl_LockAndCall(t *testing.T) {
 		})
 	}
 }
+
+func Test_lockImpl_watch(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []LockOption
+		replies []interface{}
+		release bool
+		wantErr error
+	}{
+		{
+			name:    "expired without heartbeat",
+			opts:    []LockOption{WithLockExpire(10 * time.Millisecond)},
+			wantErr: ErrLockLost,
+		},
+		{
+			name:    "owned by others",
+			opts:    []LockOption{WithLockExpire(time.Hour), WithLockHeartbeat(time.Millisecond)},
+			replies: []interface{}{int64(1), int64(0)},
+			wantErr: ErrLockLost,
+		},
+		{
+			name:    "renewal fail until expired",
+			opts:    []LockOption{WithLockExpire(50 * time.Millisecond), WithLockHeartbeat(time.Millisecond)},
+			replies: []interface{}{redigo.Error("ERR fail")},
+			wantErr: ErrLockLost,
+		},
+		{
+			name:    "released",
+			opts:    []LockOption{WithLockExpire(time.Hour), WithLockHeartbeat(time.Millisecond)},
+			replies: []interface{}{int64(1)},
+			release: true,
+			wantErr: ErrLockReleased,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			cmd := conn.GenericCommand("EVALSHA").Expect([]interface{}{int64(1), int64(1)})
+			for _, v := range tt.replies {
+				if e, ok := v.(error); ok {
+					cmd.ExpectError(e)
+					continue
+				}
+				cmd.Expect(v)
+			}
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			l := &lockerImpl{
+				name: "client_name",
+			}
+			patches.ApplyMethod(reflect.TypeOf(l), "Unlock",
+				func(*lockerImpl, context.Context, string, string) error {
+					return nil
+				})
+
+			lock, err := l.TryLock(context.Background(), "k1", tt.opts...)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			if tt.release {
+				time.Sleep(10 * time.Millisecond)
+				assert.Nil(t, lock.Err())
+				assert.Nil(t, lock.Release(context.Background()))
+			}
+
+			select {
+			case <-lock.Done():
+			case <-time.After(time.Second):
+				t.Errorf("lockImpl.Done() not closed")
+				return
+			}
+
+			assert.Equal(t, tt.wantErr, lock.Err())
+			assert.Equal(t, tt.wantErr, lock.Refresh(context.Background()))
+		})
+	}
+}
+
+func Test_lockFenceKey(t *testing.T) {
+	assert.Equal(t, "{k1.lock}.fence", lockFenceKey("k1.lock"))
+	assert.Equal(t, "{user}.lock.fence", lockFenceKey("{user}.lock"))
+	assert.Equal(t, keySlot("k1.lock"), keySlot(lockFenceKey("k1.lock")))
+}
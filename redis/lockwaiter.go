@@ -0,0 +1,251 @@
+package redis
+
+import (
+	"sync"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+var (
+	releaseWatchers   = map[string]*releaseWatcher{}
+	releaseWatchersMu sync.Mutex
+)
+
+// getReleaseWatcher returns the release watcher shared by the lock waiters
+// of the client
+func getReleaseWatcher(name string, opts []ClientOption) *releaseWatcher {
+	releaseWatchersMu.Lock()
+	defer releaseWatchersMu.Unlock()
+
+	if w, ok := releaseWatchers[name]; ok {
+		return w
+	}
+
+	w := &releaseWatcher{
+		getConn: func() redigo.Conn {
+			return getRedisPool(name, opts...).Get()
+		},
+		options: defaultSubscribeOptions(),
+		waiters: map[string]map[chan struct{}]struct{}{},
+	}
+	releaseWatchers[name] = w
+	return w
+}
+
+// releaseWatcher the release notification subscription shared by all the
+// lock waiters of a client
+//
+// The channels of the waiting locks are subscribed on one connection, no
+// matter how many waiters, and unsubscribed when no waiter. The connection
+// is returned to the pool when no channel subscribed. The waiters of the
+// channel are woken up when it subscribed, and all the waiters are woken up
+// when the connection lost, the release may be missed in between.
+type releaseWatcher struct {
+	getConn func() redigo.Conn
+	options *SubscribeOptions
+
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+	psc     *redigo.PubSubConn // nil when not connected
+	running bool
+}
+
+// watch returns the channel notified when released, call the returned
+// function to stop watching
+func (w *releaseWatcher) watch(channel string) (<-chan struct{}, func()) {
+	notified := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	waiters, ok := w.waiters[channel]
+	if !ok {
+		waiters = map[chan struct{}]struct{}{}
+		w.waiters[channel] = waiters
+		w.send("SUBSCRIBE", channel)
+	}
+	waiters[notified] = struct{}{}
+
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+
+	return notified, func() {
+		w.unwatch(channel, notified)
+	}
+}
+
+func (w *releaseWatcher) unwatch(channel string, notified chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	waiters := w.waiters[channel]
+	delete(waiters, notified)
+	if len(waiters) == 0 {
+		delete(w.waiters, channel)
+		w.send("UNSUBSCRIBE", channel)
+	}
+}
+
+// send write the command when connected, the caller must hold the lock
+//
+// The writes are serialized by the lock, concurrent with the reads. The
+// waiters fallback to polling when the write fail.
+func (w *releaseWatcher) send(cmd string, args ...interface{}) {
+	if w.psc == nil {
+		return
+	}
+
+	err := w.psc.Conn.Send(cmd, args...)
+	if err == nil {
+		err = w.psc.Conn.Flush()
+	}
+
+	if err != nil {
+		logErrorf("lock release watcher %s %v fail. error:%v", cmd, args, err)
+	}
+}
+
+// run subscribe the channels of the waiters and receive the notifications
+// until no waiter, and resubscribe with backoff when the connection lost
+func (w *releaseWatcher) run() {
+	backoff := w.options.MinBackoff
+	for {
+		psc, err := w.subscribe()
+		if err == nil {
+			if psc == nil {
+				return
+			}
+
+			backoff = w.options.MinBackoff
+			err = w.receive(psc)
+			if e := psc.Close(); e != nil {
+				logErrorf("connect close fail. error:%v", e)
+			}
+
+			if err == nil {
+				return
+			}
+		}
+
+		w.mu.Lock()
+		w.psc = nil
+		for channel := range w.waiters {
+			w.notify(channel)
+		}
+		w.mu.Unlock()
+
+		logErrorf("lock release watcher lost, resubscribe after %v. error:%v", backoff, err)
+		time.Sleep(backoff)
+
+		if backoff *= 2; backoff > w.options.MaxBackoff {
+			backoff = w.options.MaxBackoff
+		}
+	}
+}
+
+// subscribe subscribe the channels of the waiters, returns nil when no
+// waiter and stopped
+func (w *releaseWatcher) subscribe() (*redigo.PubSubConn, error) {
+	if w.stop(nil) {
+		return nil, nil
+	}
+
+	// do not block the waiters when connecting
+	psc := &redigo.PubSubConn{Conn: w.getConn()}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.waiters) == 0 {
+		w.running = false
+		psc.Close()
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(w.waiters))
+	for channel := range w.waiters {
+		args = append(args, channel)
+	}
+
+	if err := psc.Subscribe(args...); err != nil {
+		psc.Close()
+		return nil, err
+	}
+
+	w.psc = psc
+	return psc, nil
+}
+
+// receive notify the waiters until the connection lost or stopped
+func (w *releaseWatcher) receive(psc *redigo.PubSubConn) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(w.options.Ping)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.mu.Lock()
+				w.send("PING", "")
+				w.mu.Unlock()
+			}
+		}
+	}()
+
+	for {
+		switch v := psc.ReceiveWithTimeout(2 * w.options.Ping).(type) {
+		case redigo.Message:
+			w.mu.Lock()
+			w.notify(v.Channel)
+			w.mu.Unlock()
+		case redigo.Subscription:
+			// the release before subscribed may be missed
+			if v.Kind == "subscribe" {
+				w.mu.Lock()
+				w.notify(v.Channel)
+				w.mu.Unlock()
+			}
+
+			if v.Count == 0 && w.stop(psc) {
+				return nil
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+// stop returns true and mark stopped when no waiter
+func (w *releaseWatcher) stop(psc *redigo.PubSubConn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.waiters) > 0 {
+		return false
+	}
+
+	if w.psc == psc {
+		w.psc = nil
+	}
+
+	w.running = false
+	return true
+}
+
+// notify wake up the waiters of the channel, the caller must hold the lock
+func (w *releaseWatcher) notify(channel string) {
+	for notified := range w.waiters[channel] {
+		select {
+		case notified <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePubSubConn replies the SUBSCRIBE and UNSUBSCRIBE commands, and the
+// messages published by publish
+type fakePubSubConn struct {
+	mu       sync.Mutex
+	channels map[string]bool
+	pending  [][]interface{}
+	replies  chan []interface{}
+	once     sync.Once
+	closed   chan struct{}
+}
+
+func newFakePubSubConn() *fakePubSubConn {
+	return &fakePubSubConn{
+		channels: map[string]bool{},
+		replies:  make(chan []interface{}, 100),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (c *fakePubSubConn) subscribed() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var channels []string
+	for k := range c.channels {
+		channels = append(channels, k)
+	}
+	return channels
+}
+
+func (c *fakePubSubConn) publish(channel string) {
+	c.replies <- []interface{}{[]byte("message"), []byte(channel), []byte("1")}
+}
+
+func (c *fakePubSubConn) Send(cmd string, args ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, v := range args {
+		channel := fmt.Sprint(v)
+		switch cmd {
+		case "SUBSCRIBE":
+			c.channels[channel] = true
+		case "UNSUBSCRIBE":
+			delete(c.channels, channel)
+		default:
+			continue
+		}
+
+		c.pending = append(c.pending, []interface{}{
+			[]byte(strings.ToLower(cmd)), []byte(channel), int64(len(c.channels)),
+		})
+	}
+	return nil
+}
+
+func (c *fakePubSubConn) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, v := range c.pending {
+		c.replies <- v
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *fakePubSubConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	select {
+	case v := <-c.replies:
+		return v, nil
+	case <-c.closed:
+		return nil, errors.New("closed")
+	case <-time.After(timeout):
+		return nil, errors.New("timeout")
+	}
+}
+
+// Receive not blocked, so the pool closes the connection on returned
+func (c *fakePubSubConn) Receive() (interface{}, error) {
+	return nil, errors.New("no reply")
+}
+
+func (c *fakePubSubConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakePubSubConn) Err() error { return nil }
+
+func (c *fakePubSubConn) Do(string, ...interface{}) (interface{}, error) { return nil, nil }
+
+func (c *fakePubSubConn) DoWithTimeout(time.Duration, string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// useReleaseWatcher replace the release watcher of the client with the one
+// subscribing on the connection
+func useReleaseWatcher(t *testing.T, name string, conn redigo.Conn) {
+	releaseWatchersMu.Lock()
+	defer releaseWatchersMu.Unlock()
+
+	releaseWatchers[name] = &releaseWatcher{
+		getConn: func() redigo.Conn { return conn },
+		options: defaultSubscribeOptions(),
+		waiters: map[string]map[chan struct{}]struct{}{},
+	}
+
+	t.Cleanup(func() {
+		releaseWatchersMu.Lock()
+		defer releaseWatchersMu.Unlock()
+		delete(releaseWatchers, name)
+	})
+}
+
+func Test_waitLock_shareSubscription(t *testing.T) {
+	conn := newFakePubSubConn()
+	pool := &redigo.Pool{
+		MaxActive: 2,
+		Dial: func() (redigo.Conn, error) {
+			return conn, nil
+		},
+	}
+	defer pool.Close()
+
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return pool
+		})
+	defer patches.Reset()
+
+	var mu sync.Mutex
+	released := map[string]bool{}
+	l := &lockerImpl{name: "shared_watcher"}
+	patches.ApplyMethod(reflect.TypeOf(l), "TryLock",
+		func(_ *lockerImpl, _ context.Context, key string, _ ...LockOption) (Lock, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if !released[key] {
+				return nil, ErrLockNotAcquired
+			}
+			return &lockImpl{key: key}, nil
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the waiters outnumber the max active of pool
+	waiters := 5
+	errs := make(chan error, waiters)
+	for i := 0; i < waiters; i++ {
+		key := fmt.Sprintf("k%d", i)
+		go func() {
+			_, err := l.Lock(ctx, key, WithLockRetry(time.Hour))
+			errs <- err
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(conn.subscribed()) == waiters
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, pool.ActiveCount())
+
+	for i := 0; i < waiters; i++ {
+		key := fmt.Sprintf("k%d", i)
+		mu.Lock()
+		released[key] = true
+		mu.Unlock()
+		conn.publish(lockKey(key))
+	}
+
+	for i := 0; i < waiters; i++ {
+		assert.Nil(t, <-errs)
+	}
+
+	// unsubscribed and returned to the pool when no waiter
+	assert.Eventually(t, func() bool {
+		return len(conn.subscribed()) == 0 && pool.ActiveCount() == pool.IdleCount()
+	}, time.Second, time.Millisecond)
+}
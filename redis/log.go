@@ -9,12 +9,17 @@ const (
 	packageName = "gopkg/redis"
 
 	logStatusError = "[ERROR]"
+	logStatusInfo  = "[INFO]"
 )
 
 func logErrorf(format string, args ...interface{}) {
 	logf(logStatusError, format, args...)
 }
 
+func logInfof(format string, args ...interface{}) {
+	logf(logStatusInfo, format, args...)
+}
+
 func logf(logStatus, format string, args ...interface{}) {
 	log.Printf("%s %s %s", packageName, logStatus, fmt.Sprintf(format, args...))
 }
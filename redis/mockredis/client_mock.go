@@ -69,6 +69,56 @@ func (mr *MockClientProxyMockRecorder) Conn() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Conn", reflect.TypeOf((*MockClientProxy)(nil).Conn))
 }
 
+// Pipeline mocks base method
+func (m *MockClientProxy) Pipeline(ctx context.Context, fn func(redis0.Pipeliner) error) ([]redis0.Reply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pipeline", ctx, fn)
+	ret0, _ := ret[0].([]redis0.Reply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pipeline indicates an expected call of Pipeline
+func (mr *MockClientProxyMockRecorder) Pipeline(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pipeline", reflect.TypeOf((*MockClientProxy)(nil).Pipeline), ctx, fn)
+}
+
+// TxPipeline mocks base method
+func (m *MockClientProxy) TxPipeline(ctx context.Context, fn func(redis0.Pipeliner) error) ([]redis0.Reply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxPipeline", ctx, fn)
+	ret0, _ := ret[0].([]redis0.Reply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxPipeline indicates an expected call of TxPipeline
+func (mr *MockClientProxyMockRecorder) TxPipeline(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxPipeline", reflect.TypeOf((*MockClientProxy)(nil).TxPipeline), ctx, fn)
+}
+
+// Watch mocks base method
+func (m *MockClientProxy) Watch(ctx context.Context, keys []string, fn func(redis0.Tx) error, opts ...redis0.WatchOption) ([]redis0.Reply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, keys, fn}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Watch", varargs...)
+	ret0, _ := ret[0].([]redis0.Reply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockClientProxyMockRecorder) Watch(ctx, keys, fn interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, keys, fn}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockClientProxy)(nil).Watch), varargs...)
+}
+
 // Locker mocks base method
 func (m *MockClientProxy) Locker() redis0.LockerProxy {
 	m.ctrl.T.Helper()
@@ -96,3 +146,73 @@ func (mr *MockClientProxyMockRecorder) Fetcher() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetcher", reflect.TypeOf((*MockClientProxy)(nil).Fetcher))
 }
+
+// Subscriber mocks base method
+func (m *MockClientProxy) Subscriber() redis0.SubscriberProxy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscriber")
+	ret0, _ := ret[0].(redis0.SubscriberProxy)
+	return ret0
+}
+
+// Subscriber indicates an expected call of Subscriber
+func (mr *MockClientProxyMockRecorder) Subscriber() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscriber", reflect.TypeOf((*MockClientProxy)(nil).Subscriber))
+}
+
+// Stream mocks base method
+func (m *MockClientProxy) Stream() redis0.StreamProxy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream")
+	ret0, _ := ret[0].(redis0.StreamProxy)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream
+func (mr *MockClientProxyMockRecorder) Stream() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockClientProxy)(nil).Stream))
+}
+
+// RWMutex mocks base method
+func (m *MockClientProxy) RWMutex() redis0.RWMutexProxy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RWMutex")
+	ret0, _ := ret[0].(redis0.RWMutexProxy)
+	return ret0
+}
+
+// RWMutex indicates an expected call of RWMutex
+func (mr *MockClientProxyMockRecorder) RWMutex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RWMutex", reflect.TypeOf((*MockClientProxy)(nil).RWMutex))
+}
+
+// Semaphore mocks base method
+func (m *MockClientProxy) Semaphore() redis0.SemaphoreProxy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Semaphore")
+	ret0, _ := ret[0].(redis0.SemaphoreProxy)
+	return ret0
+}
+
+// Semaphore indicates an expected call of Semaphore
+func (mr *MockClientProxyMockRecorder) Semaphore() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Semaphore", reflect.TypeOf((*MockClientProxy)(nil).Semaphore))
+}
+
+// RateLimiter mocks base method
+func (m *MockClientProxy) RateLimiter(limit redis0.RateLimit) redis0.RateLimiterProxy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimiter", limit)
+	ret0, _ := ret[0].(redis0.RateLimiterProxy)
+	return ret0
+}
+
+// RateLimiter indicates an expected call of RateLimiter
+func (mr *MockClientProxyMockRecorder) RateLimiter(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimiter", reflect.TypeOf((*MockClientProxy)(nil).RateLimiter), limit)
+}
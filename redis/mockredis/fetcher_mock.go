@@ -9,6 +9,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	redis "github.com/wwwangxc/gopkg/redis"
 	reflect "reflect"
+	time "time"
 )
 
 // MockFetcherProxy is a mock of FetcherProxy interface
@@ -52,3 +53,41 @@ func (mr *MockFetcherProxyMockRecorder) Fetch(ctx, key, dest interface{}, opts .
 	varargs := append([]interface{}{ctx, key, dest}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockFetcherProxy)(nil).Fetch), varargs...)
 }
+
+// Set mocks base method
+func (m *MockFetcherProxy) Set(ctx context.Context, key string, value interface{}, expire time.Duration, opts ...redis.FetchOption) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key, value, expire}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Set", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set
+func (mr *MockFetcherProxyMockRecorder) Set(ctx, key, value, expire interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key, value, expire}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockFetcherProxy)(nil).Set), varargs...)
+}
+
+// Delete mocks base method
+func (m *MockFetcherProxy) Delete(ctx context.Context, key string, opts ...redis.FetchOption) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockFetcherProxyMockRecorder) Delete(ctx, key interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFetcherProxy)(nil).Delete), varargs...)
+}
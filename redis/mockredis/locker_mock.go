@@ -54,14 +54,14 @@ func (mr *MockLockerProxyMockRecorder) LockAndCall(ctx, key, f interface{}, opts
 }
 
 // TryLock mocks base method
-func (m *MockLockerProxy) TryLock(ctx context.Context, key string, opts ...redis.LockOption) (string, error) {
+func (m *MockLockerProxy) TryLock(ctx context.Context, key string, opts ...redis.LockOption) (redis.Lock, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, key}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "TryLock", varargs...)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(redis.Lock)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -74,14 +74,14 @@ func (mr *MockLockerProxyMockRecorder) TryLock(ctx, key interface{}, opts ...int
 }
 
 // Lock mocks base method
-func (m *MockLockerProxy) Lock(ctx context.Context, key string, opts ...redis.LockOption) (string, error) {
+func (m *MockLockerProxy) Lock(ctx context.Context, key string, opts ...redis.LockOption) (redis.Lock, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, key}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "Lock", varargs...)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(redis.Lock)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -106,3 +106,124 @@ func (mr *MockLockerProxyMockRecorder) Unlock(ctx, key, uuid interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockLockerProxy)(nil).Unlock), ctx, key, uuid)
 }
+
+// MockLock is a mock of Lock interface
+type MockLock struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockMockRecorder
+}
+
+// MockLockMockRecorder is the mock recorder for MockLock
+type MockLockMockRecorder struct {
+	mock *MockLock
+}
+
+// NewMockLock creates a new mock instance
+func NewMockLock(ctrl *gomock.Controller) *MockLock {
+	mock := &MockLock{ctrl: ctrl}
+	mock.recorder = &MockLockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLock) EXPECT() *MockLockMockRecorder {
+	return m.recorder
+}
+
+// Key mocks base method
+func (m *MockLock) Key() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Key")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Key indicates an expected call of Key
+func (mr *MockLockMockRecorder) Key() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Key", reflect.TypeOf((*MockLock)(nil).Key))
+}
+
+// UUID mocks base method
+func (m *MockLock) UUID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UUID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// UUID indicates an expected call of UUID
+func (mr *MockLockMockRecorder) UUID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UUID", reflect.TypeOf((*MockLock)(nil).UUID))
+}
+
+// Token mocks base method
+func (m *MockLock) Token() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// Token indicates an expected call of Token
+func (mr *MockLockMockRecorder) Token() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockLock)(nil).Token))
+}
+
+// Done mocks base method
+func (m *MockLock) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done
+func (mr *MockLockMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockLock)(nil).Done))
+}
+
+// Err mocks base method
+func (m *MockLock) Err() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Err")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Err indicates an expected call of Err
+func (mr *MockLockMockRecorder) Err() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Err", reflect.TypeOf((*MockLock)(nil).Err))
+}
+
+// Refresh mocks base method
+func (m *MockLock) Refresh(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refresh indicates an expected call of Refresh
+func (mr *MockLockMockRecorder) Refresh(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockLock)(nil).Refresh), ctx)
+}
+
+// Release mocks base method
+func (m *MockLock) Release(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release
+func (mr *MockLockMockRecorder) Release(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockLock)(nil).Release), ctx)
+}
@@ -81,6 +81,52 @@ func WithClientWait(wait bool) ClientOption {
 	}
 }
 
+// WithClientMode set the topology mode
+//
+// ModeStandalone, ModeSentinel or ModeCluster.
+// Default ModeStandalone
+func WithClientMode(mode string) ClientOption {
+	return func(b *serviceConfig) {
+		b.Mode = mode
+	}
+}
+
+// WithClientMasterName set the master name monitored by sentinels
+//
+// Required in ModeSentinel.
+func WithClientMasterName(masterName string) ClientOption {
+	return func(b *serviceConfig) {
+		b.MasterName = masterName
+	}
+}
+
+// WithClientSentinelPassword set the password of sentinels
+func WithClientSentinelPassword(password string) ClientOption {
+	return func(b *serviceConfig) {
+		b.SentinelPassword = password
+	}
+}
+
+// WithClientReplicas set the dsn of replicas
+//
+// The read-only commands of Do and the reads of Fetcher are sent to the
+// replicas, use WithReadPrimary to read your writes.
+func WithClientReplicas(dsn ...string) ClientOption {
+	return func(b *serviceConfig) {
+		b.Replicas = dsn
+	}
+}
+
+// WithClientReplicaSelector set the replica selector
+//
+// ReplicaRoundRobin or ReplicaLatency.
+// Default ReplicaRoundRobin
+func WithClientReplicaSelector(selector string) ClientOption {
+	return func(b *serviceConfig) {
+		b.ReplicaSelector = selector
+	}
+}
+
 // LockOptions distributed lock options
 type LockOptions struct {
 	// UUID of the lock
@@ -93,10 +139,13 @@ type LockOptions struct {
 
 	// Heartbeat indicates the time interval for automatically renewal.
 	// Heartbeat = 0 means the lock will not automatically renewal when it expires.
+	// The renewal fail is retried in next heartbeat until the lock expired.
 	// Default 0
 	Heartbeat time.Duration
 
-	// Retry indicates the time interval for retrying the acquire lock.
+	// Retry indicates the time interval for retrying the acquire lock,
+	// jittered between 0.5x and 1.5x. The waiter retries right away when the
+	// lock released.
 	// Default 1000 millisecond
 	Retry time.Duration
 }
@@ -144,6 +193,8 @@ func WithLockExpire(expire time.Duration) LockOption {
 //
 // Heartbeat indicates the time interval for automatically renewal.
 // Heartbeat = 0 means the lock will not automatically renewal when it expires.
+// The renewal fail is retried in next heartbeat, and Lock.Done closed when
+// the lock expired or owned by others.
 // default 0
 func WithLockHeartbeat(heartbeat time.Duration) LockOption {
 	return func(options *LockOptions) {
@@ -152,7 +203,9 @@ func WithLockHeartbeat(heartbeat time.Duration) LockOption {
 }
 
 // WithLockRetry set retry
-// Retry indicates the time interval for retrying the acquire lock.
+// Retry indicates the time interval for retrying the acquire lock, jittered
+// between 0.5x and 1.5x. The waiter retries right away when the lock released,
+// so the interval only matters when the lock expired or the notification lost.
 // Default 1000 millisecond
 func WithLockRetry(retry time.Duration) LockOption {
 	return func(options *LockOptions) {
@@ -167,6 +220,7 @@ type FetchOptions struct {
 	Callback           func() (interface{}, error)
 	Marshal            func(v interface{}) ([]byte, error)
 	Unmarshal          func(data []byte, dest interface{}) error
+	LocalCache         *LocalCache
 }
 
 func newFetchOptions(opts ...FetchOption) *FetchOptions {
@@ -227,3 +281,357 @@ func WithSingleflight(expire time.Duration) FetchOption {
 		options.ExpireSingleflight = expire
 	}
 }
+
+// WithFetchLocalCache use the in-process cache tier in front of redis
+//
+// The local caches of all processes are invalidated over pub/sub when the
+// key written by the callback, Set or Delete. A LocalCache should be used
+// with the same redis client.
+// Default not use.
+func WithFetchLocalCache(cache *LocalCache) FetchOption {
+	return func(options *FetchOptions) {
+		options.LocalCache = cache
+	}
+}
+
+// LocalCacheOptions in-process cache options
+type LocalCacheOptions struct {
+	// Policy the eviction policy when the cache is full
+	Policy LocalCachePolicy
+
+	// Size the max number of keys cached
+	Size int
+
+	// TTL the expire of the cached value
+	TTL time.Duration
+
+	// NegativeTTL the expire of the cached ErrKeyNotExist, zero means not
+	// cache
+	NegativeTTL time.Duration
+
+	// Channel the pub/sub channel of invalidation
+	Channel string
+}
+
+func newLocalCacheOptions(opts ...LocalCacheOption) *LocalCacheOptions {
+	options := defaultLocalCacheOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+func defaultLocalCacheOptions() *LocalCacheOptions {
+	return &LocalCacheOptions{
+		Policy:  LocalCacheLRU,
+		Size:    10000,
+		TTL:     time.Second,
+		Channel: "gopkg:fetcher:invalidate",
+	}
+}
+
+// LocalCacheOption in-process cache option
+type LocalCacheOption func(*LocalCacheOptions)
+
+// WithLocalCachePolicy set the eviction policy
+//
+// Default LRU
+func WithLocalCachePolicy(policy LocalCachePolicy) LocalCacheOption {
+	return func(options *LocalCacheOptions) {
+		options.Policy = policy
+	}
+}
+
+// WithLocalCacheSize set the max number of keys cached
+//
+// Default 10000
+func WithLocalCacheSize(size int) LocalCacheOption {
+	return func(options *LocalCacheOptions) {
+		options.Size = size
+	}
+}
+
+// WithLocalCacheTTL set the expire of the cached value
+//
+// Keep it short, the value may be stale in the TTL when an invalidation
+// missed, e.g. written by the others without publish.
+// Default 1 second
+func WithLocalCacheTTL(ttl time.Duration) LocalCacheOption {
+	return func(options *LocalCacheOptions) {
+		options.TTL = ttl
+	}
+}
+
+// WithLocalCacheNegativeTTL set the expire of the cached ErrKeyNotExist
+//
+// Default 0, not cache
+func WithLocalCacheNegativeTTL(ttl time.Duration) LocalCacheOption {
+	return func(options *LocalCacheOptions) {
+		options.NegativeTTL = ttl
+	}
+}
+
+// WithLocalCacheChannel set the pub/sub channel of invalidation
+//
+// Default gopkg:fetcher:invalidate
+func WithLocalCacheChannel(channel string) LocalCacheOption {
+	return func(options *LocalCacheOptions) {
+		options.Channel = channel
+	}
+}
+
+// WatchOptions optimistic transaction options
+type WatchOptions struct {
+	// Retries the max retries when the transaction aborted
+	Retries int
+}
+
+func newWatchOptions(opts ...WatchOption) *WatchOptions {
+	options := defaultWatchOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+func defaultWatchOptions() *WatchOptions {
+	return &WatchOptions{
+		Retries: 3,
+	}
+}
+
+// WatchOption optimistic transaction option
+type WatchOption func(*WatchOptions)
+
+// WithWatchRetries set max retries
+//
+// The transaction is retried when the watched keys changed by others.
+// Default 3
+func WithWatchRetries(retries int) WatchOption {
+	return func(options *WatchOptions) {
+		options.Retries = retries
+	}
+}
+
+// SubscribeOptions pub/sub subscribe options
+type SubscribeOptions struct {
+	// Ping the interval to ping the subscribed connection, the dead
+	// connection will be detected in twice the interval
+	Ping time.Duration
+
+	// MinBackoff the first backoff to resubscribe after the connection lost
+	MinBackoff time.Duration
+
+	// MaxBackoff the max backoff to resubscribe, the backoff doubles on
+	// each failure
+	MaxBackoff time.Duration
+
+	// BufferSize the buffer size of the message channel
+	BufferSize int
+}
+
+func newSubscribeOptions(opts ...SubscribeOption) *SubscribeOptions {
+	options := defaultSubscribeOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+func defaultSubscribeOptions() *SubscribeOptions {
+	return &SubscribeOptions{
+		Ping:       10 * time.Second,
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		BufferSize: 100,
+	}
+}
+
+// SubscribeOption pub/sub subscribe option
+type SubscribeOption func(*SubscribeOptions)
+
+// WithSubscribePing set ping interval
+//
+// The dead connection will be detected in twice the interval.
+// Default 10 second
+func WithSubscribePing(ping time.Duration) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Ping = ping
+	}
+}
+
+// WithSubscribeBackoff set the backoff to resubscribe
+//
+// The backoff starts from min and doubles on each failure up to max.
+// Default 100 millisecond to 5 second
+func WithSubscribeBackoff(min, max time.Duration) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.MinBackoff = min
+		options.MaxBackoff = max
+	}
+}
+
+// WithSubscribeBufferSize set the buffer size of message channel
+//
+// Default 100
+func WithSubscribeBufferSize(size int) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.BufferSize = size
+	}
+}
+
+// StreamAddOptions stream add options
+type StreamAddOptions struct {
+	// MaxLen the max length of stream trimmed by XADD, 0 means no trimming
+	MaxLen int64
+
+	// Approx trim the stream with ~, more efficient but the length may
+	// exceed MaxLen slightly
+	Approx bool
+}
+
+func newStreamAddOptions(opts ...StreamAddOption) *StreamAddOptions {
+	options := &StreamAddOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// StreamAddOption stream add option
+type StreamAddOption func(*StreamAddOptions)
+
+// WithStreamMaxLen set the max length of stream
+//
+// The stream is trimmed to maxLen by XADD, approx indicates MAXLEN ~.
+// Default no trimming
+func WithStreamMaxLen(maxLen int64, approx bool) StreamAddOption {
+	return func(options *StreamAddOptions) {
+		options.MaxLen = maxLen
+		options.Approx = approx
+	}
+}
+
+// ConsumeOptions stream consume options
+type ConsumeOptions struct {
+	// StartID the start id of the group created, "0" consumes the whole
+	// stream and "$" consumes the new messages only
+	StartID string
+
+	// Concurrency the max number of running handlers
+	Concurrency int
+
+	// Block the block time of XREADGROUP
+	Block time.Duration
+
+	// ClaimIdle the idle time of pending message to be claimed, 0 means
+	// never claim
+	ClaimIdle time.Duration
+
+	// ClaimInterval the interval to scan the pending messages
+	ClaimInterval time.Duration
+
+	// MaxDeliveries the max deliveries of message, the claimed message
+	// exceeded it is moved to DeadLetterStream. 0 means no limit
+	MaxDeliveries int64
+
+	// DeadLetterStream the dead-letter stream, default <stream>:dead
+	DeadLetterStream string
+
+	// Retry the interval to retry after the connection fail
+	Retry time.Duration
+}
+
+func newConsumeOptions(opts ...ConsumeOption) *ConsumeOptions {
+	options := defaultConsumeOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+func defaultConsumeOptions() *ConsumeOptions {
+	return &ConsumeOptions{
+		StartID:       "0",
+		Concurrency:   10,
+		Block:         2 * time.Second,
+		ClaimIdle:     time.Minute,
+		ClaimInterval: 30 * time.Second,
+		MaxDeliveries: 10,
+		Retry:         time.Second,
+	}
+}
+
+// ConsumeOption stream consume option
+type ConsumeOption func(*ConsumeOptions)
+
+// WithConsumeStartID set the start id of the group created
+//
+// Only used when the group not exist.
+// Default "0"
+func WithConsumeStartID(id string) ConsumeOption {
+	return func(options *ConsumeOptions) {
+		options.StartID = id
+	}
+}
+
+// WithConsumeConcurrency set the max number of running handlers
+//
+// Default 10
+func WithConsumeConcurrency(concurrency int) ConsumeOption {
+	return func(options *ConsumeOptions) {
+		if concurrency > 0 {
+			options.Concurrency = concurrency
+		}
+	}
+}
+
+// WithConsumeBlock set the block time of XREADGROUP
+//
+// The context cancellation is detected after the block time.
+// Default 2 second
+func WithConsumeBlock(block time.Duration) ConsumeOption {
+	return func(options *ConsumeOptions) {
+		if block > 0 {
+			options.Block = block
+		}
+	}
+}
+
+// WithConsumeClaim set the idle time of pending message to be claimed and
+// the interval to scan the pending messages
+//
+// idle = 0 means never claim.
+// Default 1 minute idle and 30 second interval
+func WithConsumeClaim(idle, interval time.Duration) ConsumeOption {
+	return func(options *ConsumeOptions) {
+		options.ClaimIdle = idle
+		options.ClaimInterval = interval
+	}
+}
+
+// WithConsumeDeadLetter set the max deliveries and the dead-letter stream
+//
+// The claimed message exceeded maxDeliveries is moved to stream.
+// maxDeliveries = 0 means no limit, empty stream means <stream>:dead.
+// Default 10 deliveries
+func WithConsumeDeadLetter(maxDeliveries int64, stream string) ConsumeOption {
+	return func(options *ConsumeOptions) {
+		options.MaxDeliveries = maxDeliveries
+		options.DeadLetterStream = stream
+	}
+}
+
+// WithConsumeRetry set the interval to retry after the connection fail
+//
+// Default 1 second
+func WithConsumeRetry(retry time.Duration) ConsumeOption {
+	return func(options *ConsumeOptions) {
+		options.Retry = retry
+	}
+}
@@ -0,0 +1,241 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+// Pipeliner queues the commands of pipeline
+type Pipeliner interface {
+
+	// Send queues a command, the command is sent after the pipeline
+	// function returned
+	Send(cmd string, args ...interface{})
+
+	// Len returns the number of queued commands
+	Len() int
+}
+
+// Tx the optimistic transaction on the watched connection
+type Tx interface {
+	Pipeliner
+
+	// Do sends a command on the watched connection and returns the received
+	// reply immediately, use it to read the watched keys.
+	Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+}
+
+// Reply the reply of a pipelined command
+//
+// Use the reply helpers to convert it. e.g.
+//
+//	v, err := redis.Int(replies[0].Result())
+type Reply struct {
+	Value interface{}
+	Err   error
+}
+
+// Result returns the value and error of reply
+func (r Reply) Result() (interface{}, error) {
+	return r.Value, r.Err
+}
+
+type pipelineCommand struct {
+	name string
+	args []interface{}
+}
+
+type pipeline struct {
+	cmds []pipelineCommand
+}
+
+// Send queues a command
+func (p *pipeline) Send(cmd string, args ...interface{}) {
+	p.cmds = append(p.cmds, pipelineCommand{name: cmd, args: args})
+}
+
+// Len returns the number of queued commands
+func (p *pipeline) Len() int {
+	return len(p.cmds)
+}
+
+// exec send the queued commands in one round trip
+//
+// The error of each command is returned in its reply, the error returned
+// means the connection fail.
+func (p *pipeline) exec(ctx context.Context, conn redigo.Conn) ([]Reply, error) {
+	for _, v := range p.cmds {
+		if err := conn.Send(v.name, v.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]Reply, 0, len(p.cmds))
+	for range p.cmds {
+		reply, err := redigo.ReceiveContext(conn, ctx)
+		if err != nil && !isRedisError(err) {
+			return nil, err
+		}
+
+		replies = append(replies, Reply{Value: reply, Err: err})
+	}
+
+	return replies, nil
+}
+
+// execTx send the queued commands wrapped by MULTI and EXEC
+//
+// ErrTxAborted returned when the watched keys changed.
+func (p *pipeline) execTx(ctx context.Context, conn redigo.Conn) ([]Reply, error) {
+	tx := &pipeline{
+		cmds: make([]pipelineCommand, 0, len(p.cmds)+2),
+	}
+	tx.Send("MULTI")
+	tx.cmds = append(tx.cmds, p.cmds...)
+	tx.Send("EXEC")
+
+	replies, err := tx.exec(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// the error of MULTI or the queued commands aborts EXEC
+	exec := replies[len(replies)-1]
+	if exec.Err != nil {
+		for _, v := range replies[:len(replies)-1] {
+			if v.Err != nil {
+				return nil, v.Err
+			}
+		}
+
+		return nil, exec.Err
+	}
+
+	if exec.Value == nil {
+		return nil, ErrTxAborted
+	}
+
+	values, err := redigo.Values(exec.Result())
+	if err != nil {
+		return nil, err
+	}
+
+	replies = make([]Reply, 0, len(values))
+	for _, v := range values {
+		if e, ok := v.(redigo.Error); ok {
+			replies = append(replies, Reply{Err: e})
+			continue
+		}
+
+		replies = append(replies, Reply{Value: v})
+	}
+
+	return replies, nil
+}
+
+type txImpl struct {
+	pipeline
+	conn redigo.Conn
+}
+
+// Do sends a command on the watched connection
+func (t *txImpl) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return redigo.DoContext(t.conn, ctx, cmd, args...)
+}
+
+// Pipeline send the commands queued by fn in one round trip
+//
+// The commands are not sent if fn returns error.
+func (c *clientProxyImpl) Pipeline(ctx context.Context, fn func(p Pipeliner) error) ([]Reply, error) {
+	return c.pipeline(ctx, fn, false)
+}
+
+// TxPipeline send the commands queued by fn in a MULTI/EXEC transaction
+//
+// The commands are not sent if fn returns error.
+func (c *clientProxyImpl) TxPipeline(ctx context.Context, fn func(p Pipeliner) error) ([]Reply, error) {
+	return c.pipeline(ctx, fn, true)
+}
+
+func (c *clientProxyImpl) pipeline(ctx context.Context, fn func(p Pipeliner) error, tx bool) ([]Reply, error) {
+	p := &pipeline{}
+	if err := fn(p); err != nil {
+		return nil, err
+	}
+
+	if p.Len() == 0 {
+		return []Reply{}, nil
+	}
+
+	conn := c.Conn()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logErrorf("connect close fail. error:%v", err)
+		}
+	}()
+
+	if tx {
+		return p.execTx(ctx, conn)
+	}
+
+	return p.exec(ctx, conn)
+}
+
+// Watch run the optimistic transaction on keys
+//
+// The keys are watched before fn called, and the commands queued by fn are
+// executed in a MULTI/EXEC transaction. The transaction is retried when the
+// watched keys changed by others, and ErrTxAborted returned after all
+// retries aborted.
+func (c *clientProxyImpl) Watch(ctx context.Context, keys []string, fn func(tx Tx) error, opts ...WatchOption) ([]Reply, error) {
+	options := newWatchOptions(opts...)
+	for i := 0; ; i++ {
+		replies, err := c.watch(ctx, keys, fn)
+		if !errors.Is(err, ErrTxAborted) || i >= options.Retries {
+			return replies, err
+		}
+
+		if ctx != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *clientProxyImpl) watch(ctx context.Context, keys []string, fn func(tx Tx) error) ([]Reply, error) {
+	conn := c.Conn()
+
+	// the connection will be unwatched when returned to pool
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logErrorf("connect close fail. error:%v", err)
+		}
+	}()
+
+	args := make([]interface{}, 0, len(keys))
+	for _, v := range keys {
+		args = append(args, v)
+	}
+
+	if _, err := redigo.DoContext(conn, ctx, "WATCH", args...); err != nil {
+		return nil, err
+	}
+
+	tx := &txImpl{
+		conn: conn,
+	}
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+
+	if tx.Len() == 0 {
+		return []Reply{}, nil
+	}
+
+	return tx.execTx(ctx, conn)
+}
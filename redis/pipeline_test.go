@@ -0,0 +1,212 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_clientProxyImpl_Pipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(p Pipeliner) error
+		mock    func(conn *redigomock.Conn)
+		want    []Reply
+		wantErr bool
+	}{
+		{
+			name:    "fn fail",
+			fn:      func(p Pipeliner) error { return fmt.Errorf("") },
+			wantErr: true,
+		},
+		{
+			name: "empty",
+			fn:   func(p Pipeliner) error { return nil },
+			want: []Reply{},
+		},
+		{
+			name: "connection fail",
+			fn: func(p Pipeliner) error {
+				p.Send("GET", "k1")
+				return nil
+			},
+			mock:    func(conn *redigomock.Conn) {},
+			wantErr: true,
+		},
+		{
+			name: "normal process",
+			fn: func(p Pipeliner) error {
+				p.Send("SET", "k1", "v1")
+				p.Send("GET", "k1")
+				p.Send("INCR", "k1")
+				return nil
+			},
+			mock: func(conn *redigomock.Conn) {
+				conn.Command("SET", "k1", "v1").Expect("OK")
+				conn.Command("GET", "k1").Expect([]byte("v1"))
+				conn.Command("INCR", "k1").ExpectError(redigo.Error("ERR value is not an integer"))
+			},
+			want: []Reply{
+				{Value: "OK"},
+				{Value: []byte("v1")},
+				{Err: redigo.Error("ERR value is not an integer")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			if tt.mock != nil {
+				tt.mock(conn)
+			}
+
+			var cli *clientProxyImpl
+			patches := gomonkey.ApplyMethod(reflect.TypeOf(cli), "Conn",
+				func(*clientProxyImpl) redigo.Conn {
+					return conn
+				})
+			defer patches.Reset()
+
+			c := &clientProxyImpl{
+				name: "client_name",
+			}
+			got, err := c.Pipeline(context.Background(), tt.fn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("clientProxyImpl.Pipeline() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_clientProxyImpl_TxPipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(conn *redigomock.Conn)
+		want    []Reply
+		wantErr bool
+	}{
+		{
+			name: "queue fail",
+			mock: func(conn *redigomock.Conn) {
+				conn.Command("MULTI").Expect("OK")
+				conn.Command("SET", "k1", "v1").ExpectError(redigo.Error("ERR wrong number of arguments"))
+				conn.Command("INCR", "k1").Expect("QUEUED")
+				conn.Command("EXEC").ExpectError(redigo.Error("EXECABORT Transaction discarded"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "normal process",
+			mock: func(conn *redigomock.Conn) {
+				conn.Command("MULTI").Expect("OK")
+				conn.Command("SET", "k1", "v1").Expect("QUEUED")
+				conn.Command("INCR", "k1").Expect("QUEUED")
+				conn.Command("EXEC").Expect([]interface{}{"OK", redigo.Error("ERR value is not an integer")})
+			},
+			want: []Reply{
+				{Value: "OK"},
+				{Err: redigo.Error("ERR value is not an integer")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			tt.mock(conn)
+
+			var cli *clientProxyImpl
+			patches := gomonkey.ApplyMethod(reflect.TypeOf(cli), "Conn",
+				func(*clientProxyImpl) redigo.Conn {
+					return conn
+				})
+			defer patches.Reset()
+
+			c := &clientProxyImpl{
+				name: "client_name",
+			}
+			got, err := c.TxPipeline(context.Background(), func(p Pipeliner) error {
+				p.Send("SET", "k1", "v1")
+				p.Send("INCR", "k1")
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("clientProxyImpl.TxPipeline() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_clientProxyImpl_Watch(t *testing.T) {
+	tests := []struct {
+		name      string
+		exec      []interface{}
+		opts      []WatchOption
+		want      []Reply
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "retry",
+			exec:      []interface{}{nil, []interface{}{int64(1)}},
+			want:      []Reply{{Value: int64(1)}},
+			wantCalls: 2,
+		},
+		{
+			name:      "aborted",
+			exec:      []interface{}{nil},
+			opts:      []WatchOption{WithWatchRetries(1)},
+			wantErr:   ErrTxAborted,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			conn.Command("WATCH", "k1").Expect("OK")
+			conn.Command("GET", "k1").Expect([]byte("1"))
+			conn.Command("MULTI").Expect("OK")
+			conn.Command("SET", "k1", 2).Expect("QUEUED")
+			cmd := conn.Command("EXEC")
+			for _, v := range tt.exec {
+				cmd.Expect(v)
+			}
+
+			var cli *clientProxyImpl
+			patches := gomonkey.ApplyMethod(reflect.TypeOf(cli), "Conn",
+				func(*clientProxyImpl) redigo.Conn {
+					return conn
+				})
+			defer patches.Reset()
+
+			c := &clientProxyImpl{
+				name: "client_name",
+			}
+			calls := 0
+			got, err := c.Watch(context.Background(), []string{"k1"}, func(tx Tx) error {
+				calls++
+				v, err := Int(tx.Do(context.Background(), "GET", "k1"))
+				if err != nil {
+					return err
+				}
+
+				tx.Send("SET", "k1", v+1)
+				return nil
+			}, tt.opts...)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
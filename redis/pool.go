@@ -3,18 +3,52 @@ package redis
 import (
 	"context"
 	"net"
+	"net/url"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
 	redigo "github.com/gomodule/redigo/redis"
 )
 
+// connPool redis connection pool of the service topology
+//
+// The standalone service uses *redigo.Pool directly, the sentinel and
+// cluster services wrap the pools of nodes.
+type connPool interface {
+
+	// Get gets a connection, the application must close the returned connection
+	Get() redigo.Conn
+
+	// ActiveCount returns the number of connections in the pool
+	ActiveCount() int
+
+	// IdleCount returns the number of idle connections in the pool
+	IdleCount() int
+
+	// Close releases the resources used by the pool
+	Close() error
+}
+
+const (
+	// drainDelay the replaced pool will be closed after the delay and all
+	// the borrowed connections returned
+	drainDelay = time.Second
+
+	// drainTimeout the replaced pool will be closed after the timeout even
+	// if the borrowed connections not returned
+	drainTimeout = 30 * time.Second
+)
+
 var (
-	pools   = map[string]*redigo.Pool{}
-	poolsRW sync.RWMutex
+	pools       = map[string]connPool{}
+	poolConfigs = map[string]serviceConfig{}
+	poolOptions = map[string][]ClientOption{}
+	poolsRW     sync.RWMutex
 )
 
-func getRedisPool(name string, opts ...ClientOption) *redigo.Pool {
+func getRedisPool(name string, opts ...ClientOption) connPool {
 	poolsRW.RLock()
 	pool, ok := pools[name]
 	poolsRW.RUnlock()
@@ -27,10 +61,10 @@ func getRedisPool(name string, opts ...ClientOption) *redigo.Pool {
 		opt(&cfg)
 	}
 
-	return newRedisPool(&cfg)
+	return newRedisPool(&cfg, opts...)
 }
 
-func newRedisPool(cfg *serviceConfig) *redigo.Pool {
+func newRedisPool(cfg *serviceConfig, opts ...ClientOption) connPool {
 	poolsRW.Lock()
 	defer poolsRW.Unlock()
 
@@ -39,42 +73,148 @@ func newRedisPool(cfg *serviceConfig) *redigo.Pool {
 		return pool
 	}
 
-	timeout := time.Duration(cfg.Timeout) * time.Millisecond
-	pool = &redigo.Pool{
+	pool = buildRedisPool(cfg)
+	pools[cfg.Name] = pool
+	poolConfigs[cfg.Name] = *cfg
+	poolOptions[cfg.Name] = opts
+	return pool
+}
+
+// buildRedisPool build the pool by the mode of service
+func buildRedisPool(cfg *serviceConfig) connPool {
+	var pool connPool
+	switch cfg.Mode {
+	case ModeSentinel:
+		pool = newSentinelPool(cfg)
+	case ModeCluster:
+		// the replicas of cluster are managed by the cluster itself
+		return newClusterPool(cfg)
+	default:
+		pool = newStandalonePool(cfg, cfg.DSN)
+	}
+
+	if len(cfg.Replicas) == 0 {
+		return pool
+	}
+
+	return newReplicaPool(cfg, pool)
+}
+
+// newStandalonePool new the pool of the redis server in dsn
+func newStandalonePool(cfg *serviceConfig, dsn string) *redigo.Pool {
+	return &redigo.Pool{
 		MaxIdle:         cfg.MaxIdle,
 		MaxActive:       cfg.MaxActive,
 		IdleTimeout:     time.Duration(cfg.IdleTimeout) * time.Millisecond,
 		MaxConnLifetime: time.Duration(cfg.MaxConnLifetime) * time.Millisecond,
 		Dial: func() (redigo.Conn, error) {
-			dialOpts := []redigo.DialOption{
-				redigo.DialWriteTimeout(timeout),
-				redigo.DialReadTimeout(timeout),
-				redigo.DialConnectTimeout(timeout),
-				redigo.DialContextFunc(func(ctx context.Context, network, addr string) (net.Conn, error) {
-					dialer := &net.Dialer{
-						Timeout: timeout,
-					}
-					return dialer.DialContext(ctx, network, addr)
-				}),
-			}
+			return dialURL(cfg, dsn)
+		},
+		TestOnBorrow: testOnBorrow,
+		Wait:         cfg.Wait,
+	}
+}
 
-			c, err := redigo.DialURL(cfg.DSN, dialOpts...)
-			if err != nil {
-				return nil, err
+// dialURL dial the redis server in dsn with the timeout of service
+func dialURL(cfg *serviceConfig, dsn string) (redigo.Conn, error) {
+	timeout := time.Duration(cfg.Timeout) * time.Millisecond
+	dialOpts := []redigo.DialOption{
+		redigo.DialWriteTimeout(timeout),
+		redigo.DialReadTimeout(timeout),
+		redigo.DialConnectTimeout(timeout),
+		redigo.DialContextFunc(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialer := &net.Dialer{
+				Timeout: timeout,
 			}
+			return dialer.DialContext(ctx, network, addr)
+		}),
+	}
 
-			return c, nil
-		},
-		TestOnBorrow: func(c redigo.Conn, t time.Time) error {
-			if time.Since(t) < time.Minute {
-				return nil
-			}
-			_, err := c.Do("PING")
-			return err
-		},
-		Wait: cfg.Wait,
+	c, err := redigo.DialURL(dsn, dialOpts...)
+	if err != nil {
+		return nil, err
 	}
 
-	pools[cfg.Name] = pool
-	return pool
+	return c, nil
+}
+
+func testOnBorrow(c redigo.Conn, t time.Time) error {
+	if time.Since(t) < time.Minute {
+		return nil
+	}
+	_, err := c.Do("PING")
+	return err
+}
+
+// reloadRedisPools replace the pools whose config changed
+//
+// The new connections will be borrowed from the new pool, and the replaced
+// pool will be drained in background, so the in-flight commands will not
+// be interrupted.
+func reloadRedisPools() {
+	poolsRW.Lock()
+	var replaced []connPool
+	for name, pool := range pools {
+		cfg := getServiceConfig(name)
+		for _, opt := range poolOptions[name] {
+			opt(&cfg)
+		}
+
+		if reflect.DeepEqual(cfg, poolConfigs[name]) {
+			continue
+		}
+
+		pools[name] = buildRedisPool(&cfg)
+		poolConfigs[name] = cfg
+		replaced = append(replaced, pool)
+	}
+	poolsRW.Unlock()
+
+	for _, pool := range replaced {
+		go drainRedisPool(pool, drainDelay)
+	}
+}
+
+// drainRedisPool close the pool after the delay and the borrowed
+// connections returned
+func drainRedisPool(pool connPool, delay time.Duration) {
+	time.Sleep(delay)
+
+	deadline := time.Now().Add(drainTimeout)
+	for pool.ActiveCount() > pool.IdleCount() && time.Now().Before(deadline) {
+		time.Sleep(delay)
+	}
+
+	if err := pool.Close(); err != nil {
+		logErrorf("close replaced pool fail. error:%v", err)
+	}
+}
+
+// dsnHosts return the hosts in dsn separated by comma, e.g.
+// redis://:password@127.0.0.1:26379,127.0.0.2:26379/0
+func dsnHosts(dsn string) []string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil
+	}
+
+	var hosts []string
+	for _, v := range strings.Split(u.Host, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			hosts = append(hosts, v)
+		}
+	}
+
+	return hosts
+}
+
+// replaceHost replace the hosts in dsn with host
+func replaceHost(dsn, host string) string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn
+	}
+
+	u.Host = host
+	return u.String()
 }
@@ -0,0 +1,193 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+)
+
+// RateLimitAlgorithm the algorithm of rate limiter
+type RateLimitAlgorithm int
+
+const (
+	// RateLimitGCRA generic cell rate algorithm, the token bucket which
+	// refills Limit tokens per Period smoothly and holds Burst tokens at most
+	RateLimitGCRA RateLimitAlgorithm = iota
+
+	// RateLimitSlidingWindow sliding window log, allows Limit requests in
+	// any Period, each request is logged
+	RateLimitSlidingWindow
+
+	// RateLimitFixedWindow fixed window counter, allows Limit requests in
+	// the Period started by the first request
+	RateLimitFixedWindow
+)
+
+// RateLimit the rate limit rule
+type RateLimit struct {
+	// Algorithm the rate limit algorithm, default GCRA
+	Algorithm RateLimitAlgorithm
+
+	// Limit the number of requests allowed per Period
+	Limit int
+
+	// Period the period of Limit
+	Period time.Duration
+
+	// Burst the max number of requests allowed at once, GCRA only.
+	// Default Limit
+	Burst int
+}
+
+// RateLimitResult the result of rate limiter
+type RateLimitResult struct {
+	// Allowed whether the requests allowed
+	Allowed bool
+
+	// Remaining the number of requests still allowed now
+	Remaining int
+
+	// RetryAfter the time to wait before the requests would be allowed,
+	// zero when allowed, and -1 when the requests exceed the capacity and
+	// will never be allowed
+	RetryAfter time.Duration
+
+	// ResetAfter the time until the full quota restored
+	ResetAfter time.Duration
+}
+
+// RateLimiterProxy distributed rate limiter
+//
+// Each limit check is one atomic script, so the limiter is shared by all
+// the processes using the same redis, and without INCR and EXPIRE races.
+//
+//go:generate mockgen -source=ratelimit.go -destination=mockredis/ratelimit_mock.go -package=mockredis
+type RateLimiterProxy interface {
+
+	// Allow check whether n requests of the key allowed now, the quota will
+	// be taken when allowed.
+	//
+	// Not block the current goroutine.
+	Allow(ctx context.Context, key string, n int) (*RateLimitResult, error)
+
+	// Wait until a request of the key allowed or the context canceled
+	//
+	// Will block the current goroutine.
+	// Return ErrRateLimited immediately when the context deadline would
+	// be exceeded before allowed.
+	Wait(ctx context.Context, key string) error
+}
+
+type rateLimiterImpl struct {
+	name  string
+	opts  []ClientOption
+	limit RateLimit
+}
+
+// NewRateLimiterProxy new distributed rate limiter proxy with the limit rule
+func NewRateLimiterProxy(name string, limit RateLimit, opts ...ClientOption) RateLimiterProxy {
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Limit
+	}
+
+	return &rateLimiterImpl{
+		name:  name,
+		opts:  opts,
+		limit: limit,
+	}
+}
+
+// Allow check whether n requests of the key allowed now
+func (r *rateLimiterImpl) Allow(ctx context.Context, key string, n int) (*RateLimitResult, error) {
+	if r.limit.Limit <= 0 || r.limit.Period <= 0 {
+		return nil, errors.New("redis: rate limit and period must be positive")
+	}
+
+	if n <= 0 {
+		return nil, errors.New("redis: rate limit requests must be positive")
+	}
+
+	k := rateLimitKey(key)
+	pool := getRedisPool(r.name, r.opts...)
+
+	var reply interface{}
+	var err error
+	switch r.limit.Algorithm {
+	case RateLimitSlidingWindow:
+		reply, err = evalScript(ctx, pool, redigo.NewScript(1, luaScriptRateSlidingWindow),
+			k, r.limit.Limit, r.limit.Period.Milliseconds(), n, uuid.New().String())
+	case RateLimitFixedWindow:
+		reply, err = evalScript(ctx, pool, redigo.NewScript(1, luaScriptRateFixedWindow),
+			k, r.limit.Limit, r.limit.Period.Milliseconds(), n)
+	case RateLimitGCRA:
+		emission := float64(r.limit.Period.Microseconds()) / float64(r.limit.Limit)
+		reply, err = evalScript(ctx, pool, redigo.NewScript(1, luaScriptRateGCRA),
+			k, emission, r.limit.Burst, n)
+	default:
+		return nil, errors.New("redis: unknown rate limit algorithm")
+	}
+
+	values, err := Int64s(reply, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != 4 {
+		return nil, errors.New("redis: unexpected rate limit reply")
+	}
+
+	return &RateLimitResult{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: rateLimitDuration(values[2]),
+		ResetAfter: rateLimitDuration(values[3]),
+	}, nil
+}
+
+// Wait until a request of the key allowed or the context canceled
+func (r *rateLimiterImpl) Wait(ctx context.Context, key string) error {
+	for {
+		ret, err := r.Allow(ctx, key, 1)
+		if err != nil {
+			return err
+		}
+
+		if ret.Allowed {
+			return nil
+		}
+
+		if ret.RetryAfter < 0 {
+			return ErrRateLimited
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < ret.RetryAfter {
+			return ErrRateLimited
+		}
+
+		timer := time.NewTimer(ret.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimitKey returns the redis key of rate limiter
+func rateLimitKey(key string) string {
+	return key + ".ratelimit"
+}
+
+// rateLimitDuration convert the milliseconds of script reply, the negative
+// means never
+func rateLimitDuration(ms int64) time.Duration {
+	if ms < 0 {
+		return -1
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,196 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rateLimiterImpl_Allow(t *testing.T) {
+	id := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	patches := gomonkey.ApplyFunc(uuid.New, func() uuid.UUID { return id })
+	defer patches.Reset()
+
+	tests := []struct {
+		name    string
+		limit   RateLimit
+		n       int
+		script  string
+		args    []interface{}
+		reply   interface{}
+		want    *RateLimitResult
+		wantErr bool
+	}{
+		{
+			name:    "invalid limit",
+			limit:   RateLimit{Period: time.Second},
+			n:       1,
+			wantErr: true,
+		},
+		{
+			name:    "invalid requests",
+			limit:   RateLimit{Limit: 10, Period: time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "unknown algorithm",
+			limit:   RateLimit{Algorithm: 99, Limit: 10, Period: time.Second},
+			n:       1,
+			wantErr: true,
+		},
+		{
+			name:    "script fail",
+			limit:   RateLimit{Limit: 10, Period: time.Second},
+			n:       1,
+			script:  luaScriptRateGCRA,
+			args:    []interface{}{"k1.ratelimit", float64(100000), 10, 1},
+			reply:   redigo.Error("ERR fail"),
+			wantErr: true,
+		},
+		{
+			name:    "unexpected reply",
+			limit:   RateLimit{Limit: 10, Period: time.Second},
+			n:       1,
+			script:  luaScriptRateGCRA,
+			args:    []interface{}{"k1.ratelimit", float64(100000), 10, 1},
+			reply:   []interface{}{int64(1)},
+			wantErr: true,
+		},
+		{
+			name:   "gcra allowed",
+			limit:  RateLimit{Limit: 10, Period: time.Second, Burst: 5},
+			n:      2,
+			script: luaScriptRateGCRA,
+			args:   []interface{}{"k1.ratelimit", float64(100000), 5, 2},
+			reply:  []interface{}{int64(1), int64(3), int64(0), int64(200)},
+			want: &RateLimitResult{
+				Allowed:    true,
+				Remaining:  3,
+				ResetAfter: 200 * time.Millisecond,
+			},
+		},
+		{
+			name:   "sliding window denied",
+			limit:  RateLimit{Algorithm: RateLimitSlidingWindow, Limit: 10, Period: time.Minute},
+			n:      1,
+			script: luaScriptRateSlidingWindow,
+			args:   []interface{}{"k1.ratelimit", 10, int64(60000), 1, id.String()},
+			reply:  []interface{}{int64(0), int64(0), int64(1500), int64(30000)},
+			want: &RateLimitResult{
+				RetryAfter: 1500 * time.Millisecond,
+				ResetAfter: 30 * time.Second,
+			},
+		},
+		{
+			name:   "fixed window exceed capacity",
+			limit:  RateLimit{Algorithm: RateLimitFixedWindow, Limit: 10, Period: time.Minute},
+			n:      11,
+			script: luaScriptRateFixedWindow,
+			args:   []interface{}{"k1.ratelimit", 10, int64(60000), 11},
+			reply:  []interface{}{int64(0), int64(10), int64(-1), int64(60000)},
+			want: &RateLimitResult{
+				Remaining:  10,
+				RetryAfter: -1,
+				ResetAfter: time.Minute,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			if tt.script != "" {
+				args := append([]interface{}{redigo.NewScript(1, tt.script).Hash(), 1}, tt.args...)
+				cmd := conn.Command("EVALSHA", args...)
+				if err, ok := tt.reply.(error); ok {
+					cmd.ExpectError(err)
+				} else {
+					cmd.Expect(tt.reply)
+				}
+			}
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			got, err := NewRateLimiterProxy("client_name", tt.limit).Allow(context.Background(), "k1", tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("rateLimiterImpl.Allow() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_rateLimiterImpl_Wait(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies []interface{}
+		timeout time.Duration
+		want    error
+		wantN   int
+	}{
+		{
+			name: "allowed after retry",
+			replies: []interface{}{
+				[]interface{}{int64(0), int64(0), int64(10), int64(100)},
+				[]interface{}{int64(1), int64(0), int64(0), int64(100)},
+			},
+			timeout: time.Second,
+			wantN:   2,
+		},
+		{
+			name: "exceed the deadline",
+			replies: []interface{}{
+				[]interface{}{int64(0), int64(0), int64(5000), int64(5000)},
+			},
+			timeout: time.Second,
+			want:    ErrRateLimited,
+			wantN:   1,
+		},
+		{
+			name: "exceed the capacity",
+			replies: []interface{}{
+				[]interface{}{int64(0), int64(0), int64(-1), int64(0)},
+			},
+			want:  ErrRateLimited,
+			wantN: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			cmd := conn.Command("EVALSHA", redigo.NewScript(1, luaScriptRateGCRA).Hash(), 1,
+				"k1.ratelimit", float64(100000), 10, 1)
+			for _, reply := range tt.replies {
+				cmd = cmd.Expect(reply)
+			}
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			limiter := NewRateLimiterProxy("client_name", RateLimit{Limit: 10, Period: time.Second})
+			assert.Equal(t, tt.want, limiter.Wait(ctx, "k1"))
+			assert.Equal(t, tt.wantN, conn.Stats(cmd))
+		})
+	}
+}
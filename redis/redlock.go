@@ -0,0 +1,281 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+var (
+	// redlockDriftFactor the clock drift of the lock expire
+	redlockDriftFactor = 0.01
+
+	// redlockDriftMin the min clock drift
+	redlockDriftMin = 2 * time.Millisecond
+)
+
+type redlockImpl struct {
+	lockers []*lockerImpl
+	quorum  int
+}
+
+// NewRedlockProxy new the Redlock distributed lock provider across the
+// independent redis instances
+//
+// The names are the client names of client.service, and the opts are
+// applied to each of them. The lock is acquired when the majority of
+// instances locked in the validity time, which is the lock expire minus
+// the time elapsed and the clock drift.
+//
+// The surface and reentrancy are the same as NewLockerProxy. The fencing
+// token is the max token of the locked instances, and the fencing counters
+// of them are raised to it, so that the token increases across the quorums.
+// The blocking Lock polls with the jittered Retry interval.
+func NewRedlockProxy(names []string, opts ...ClientOption) LockerProxy {
+	lockers := make([]*lockerImpl, 0, len(names))
+	for _, name := range names {
+		lockers = append(lockers, &lockerImpl{
+			name: name,
+			opts: opts,
+		})
+	}
+
+	return &redlockImpl{
+		lockers: lockers,
+		quorum:  len(lockers)/2 + 1,
+	}
+}
+
+// LockAndCall try get lock first and call f() when lock acquired. Unlock will be performed
+// regardless of whether the f reports an error or not.
+func (r *redlockImpl) LockAndCall(ctx context.Context, key string, f func() error, opts ...LockOption) error {
+	if f == nil {
+		return nil
+	}
+
+	lock, err := r.Lock(ctx, key, opts...)
+	if err != nil {
+		return fmt.Errorf("lock fail case %v", err)
+	}
+
+	defer func() {
+		if err := lock.Release(ctx); err != nil {
+			logErrorf("lock:%s unlock fail: %v", key, err)
+		}
+	}()
+
+	return f()
+}
+
+// TryLock try get lock on the majority of instances
+//
+// Return ErrLockNotAcquired when any instance locked by others, otherwise
+// the error of instances returned when the quorum not reached.
+func (r *redlockImpl) TryLock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
+	if len(r.lockers) == 0 {
+		return nil, errors.New("redis: redlock clients required")
+	}
+
+	options := newLockOptions(opts...)
+	start := time.Now()
+
+	tokens := make([]int64, len(r.lockers))
+	errs := r.each(func(i int, l *lockerImpl) error {
+		var err error
+		tokens[i], err = l.acquire(ctx, key, options)
+		return err
+	})
+
+	var locked []int
+	var token int64
+	var notAcquired bool
+	var firstErr error
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			locked = append(locked, i)
+			if tokens[i] > token {
+				token = tokens[i]
+			}
+		case IsLockNotAcquired(err):
+			notAcquired = true
+		case firstErr == nil:
+			firstErr = err
+		}
+	}
+
+	deadline := start.Add(options.Expire - r.drift(options.Expire))
+	if len(locked) < r.quorum || !time.Now().Before(deadline) {
+		// roll back the instances locked by this attempt only, the unknown
+		// ones may hold the outer lock of the reentrant UUID
+		r.release(key, options.UUID, locked)
+
+		if notAcquired || firstErr == nil {
+			return nil, ErrLockNotAcquired
+		}
+		return nil, firstErr
+	}
+
+	r.raiseFence(ctx, key, token, locked)
+
+	return newLock(key, options, token, deadline,
+		func(ctx context.Context) (time.Time, error) {
+			return r.refresh(ctx, key, options, tokens, locked)
+		},
+		func(ctx context.Context) error {
+			return r.Unlock(ctx, key, options.UUID)
+		}), nil
+}
+
+// Lock try get lock until the context canceled or the lock acquired
+//
+// Will block the current goroutine, and poll with the jittered Retry
+// interval.
+func (r *redlockImpl) Lock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
+	return waitLock(ctx, "", nil, "", newLockOptions(opts...).Retry, func() (Lock, error) {
+		return r.TryLock(ctx, key, opts...)
+	})
+}
+
+// Unlock unlock on all instances
+//
+// Return nil when the majority of instances unlocked, otherwise the error
+// of instances returned.
+func (r *redlockImpl) Unlock(ctx context.Context, key, uuid string) error {
+	errs := r.each(func(_ int, l *lockerImpl) error {
+		return l.Unlock(ctx, key, uuid)
+	})
+
+	unlocked := 0
+	var firstErr error
+	for _, err := range errs {
+		if err == nil {
+			unlocked++
+			continue
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if unlocked >= r.quorum {
+		return nil
+	}
+
+	return firstErr
+}
+
+// refresh extends the lock expire on the locked instances
+//
+// The zero deadline returned when the quorum can not be reached anymore.
+func (r *redlockImpl) refresh(ctx context.Context, key string, options *LockOptions, tokens []int64,
+	locked []int) (time.Time, error) {
+	start := time.Now()
+	owned := make([]bool, len(locked))
+	errs := make([]error, len(locked))
+
+	var wg sync.WaitGroup
+	for i, idx := range locked {
+		wg.Add(1)
+		go func(i, idx int) {
+			defer wg.Done()
+			owned[i], errs[i] = r.lockers[idx].refresh(ctx, key, options.UUID, options.Expire, tokens[idx])
+		}(i, idx)
+	}
+	wg.Wait()
+
+	n, failed := 0, 0
+	var firstErr error
+	for i := range locked {
+		switch {
+		case errs[i] != nil:
+			failed++
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+		case owned[i]:
+			n++
+		}
+	}
+
+	deadline := start.Add(options.Expire - r.drift(options.Expire))
+	switch {
+	case n >= r.quorum && time.Now().Before(deadline):
+		return deadline, nil
+	case n+failed >= r.quorum && firstErr != nil:
+		// retry later, the lock is still valid until the current deadline
+		return time.Time{}, firstErr
+	}
+
+	return time.Time{}, nil
+}
+
+// release unlock the locked instances in background
+//
+// The instances fail with unknown state are not unlocked, the lock may not
+// be acquired on them, and unlocking decrements or deletes the outer lock
+// of the reentrant UUID. The lock is expired on them if acquired.
+func (r *redlockImpl) release(key, uuid string, locked []int) {
+	for _, idx := range locked {
+		go func(l *lockerImpl) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			if err := l.Unlock(ctx, key, uuid); err != nil && !IsLockNotExist(err) {
+				logErrorf("redlock:%s release on %s fail. error:%v", key, l.name, err)
+			}
+		}(r.lockers[idx])
+	}
+}
+
+// raiseFence raise the fencing counters of the locked instances to token
+func (r *redlockImpl) raiseFence(ctx context.Context, key string, token int64, locked []int) {
+	k := lockKey(key)
+	script := redigo.NewScript(1, luaScriptRaiseFence)
+
+	var wg sync.WaitGroup
+	for _, idx := range locked {
+		wg.Add(1)
+		go func(l *lockerImpl) {
+			defer wg.Done()
+
+			conn := l.getConn()
+			defer func() {
+				if err := conn.Close(); err != nil {
+					logErrorf("connect close fail. error:%v", err)
+				}
+			}()
+
+			if _, err := script.DoContext(ctx, conn, lockFenceKey(k), token, lockFenceExpire.Milliseconds()); err != nil {
+				logErrorf("redlock:%s raise fence on %s fail. error:%v", key, l.name, err)
+			}
+		}(r.lockers[idx])
+	}
+	wg.Wait()
+}
+
+// each call fn on every instance concurrently
+func (r *redlockImpl) each(fn func(i int, l *lockerImpl) error) []error {
+	errs := make([]error, len(r.lockers))
+
+	var wg sync.WaitGroup
+	for i, l := range r.lockers {
+		wg.Add(1)
+		go func(i int, l *lockerImpl) {
+			defer wg.Done()
+			errs[i] = fn(i, l)
+		}(i, l)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func (r *redlockImpl) drift(expire time.Duration) time.Duration {
+	return time.Duration(float64(expire)*redlockDriftFactor) + redlockDriftMin
+}
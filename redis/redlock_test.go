@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRedlockConns(n int) (map[string]*redigomock.Conn, *gomonkey.Patches) {
+	conns := map[string]*redigomock.Conn{}
+	pools := map[string]connPool{}
+	for _, name := range []string{"r1", "r2", "r3"}[:n] {
+		conns[name] = redigomock.NewConn()
+		pools[name] = &mockPool{conn: conns[name]}
+	}
+
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(name string, _ ...ClientOption) connPool {
+			return pools[name]
+		})
+	return conns, patches
+}
+
+func mockRedlockAcquire(conn *redigomock.Conn, reply interface{}) *redigomock.Cmd {
+	cmd := conn.Command("EVALSHA", redigo.NewScript(2, luaScriptLock).Hash(), 2, "k1.lock", "{k1.lock}.fence",
+		"uuid", int64(1000), lockFenceExpire.Milliseconds())
+	if err, ok := reply.(error); ok {
+		return cmd.ExpectError(err)
+	}
+
+	return cmd.Expect(reply)
+}
+
+func mockRedlockUnlock(conn *redigomock.Conn, reply interface{}) *redigomock.Cmd {
+	cmd := conn.Command("EVALSHA", redigo.NewScript(1, luaScriptUnlock).Hash(), 1, "k1.lock", "uuid")
+	if err, ok := reply.(error); ok {
+		return cmd.ExpectError(err)
+	}
+
+	return cmd.Expect(reply)
+}
+
+func Test_redlockImpl_TryLock(t *testing.T) {
+	tests := []struct {
+		name        string
+		acquire     []interface{}
+		wantToken   int64
+		wantErr     error
+		wantFence   []int
+		wantRelease []int
+	}{
+		{
+			name:      "quorum reached",
+			acquire:   []interface{}{[]interface{}{int64(1), int64(5)}, []interface{}{int64(1), int64(7)}, []interface{}{int64(0), int64(0)}},
+			wantToken: 7,
+			wantFence: []int{1, 1, 0},
+		},
+		{
+			name:        "locked by others",
+			acquire:     []interface{}{[]interface{}{int64(1), int64(5)}, []interface{}{int64(0), int64(0)}, []interface{}{int64(0), int64(0)}},
+			wantErr:     ErrLockNotAcquired,
+			wantRelease: []int{1, 0, 0},
+		},
+		{
+			name:        "instances fail",
+			acquire:     []interface{}{redigo.Error("ERR fail"), redigo.Error("ERR fail"), []interface{}{int64(1), int64(1)}},
+			wantErr:     redigo.Error("ERR fail"),
+			wantRelease: []int{0, 0, 1},
+		},
+		{
+			// the outer lock of the uuid held on r2 and r3 is not released
+			name:        "reentrant instances fail",
+			acquire:     []interface{}{[]interface{}{int64(1), int64(5)}, redigo.Error("i/o timeout"), redigo.Error("i/o timeout")},
+			wantErr:     redigo.Error("i/o timeout"),
+			wantRelease: []int{1, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conns, patches := newRedlockConns(3)
+			defer patches.Reset()
+
+			var fences, releases []*redigomock.Cmd
+			for i, name := range []string{"r1", "r2", "r3"} {
+				conn := conns[name]
+				mockRedlockAcquire(conn, tt.acquire[i])
+				fences = append(fences, conn.Command("EVALSHA", redigo.NewScript(1, luaScriptRaiseFence).Hash(), 1,
+					"{k1.lock}.fence", int64(7), lockFenceExpire.Milliseconds()).Expect(int64(1)))
+				releases = append(releases, mockRedlockUnlock(conn, int64(666)))
+			}
+
+			r := NewRedlockProxy([]string{"r1", "r2", "r3"})
+			got, err := r.TryLock(context.Background(), "k1", WithLockUUID("uuid"))
+			assert.Equal(t, tt.wantErr, err)
+			if err == nil {
+				assert.Equal(t, tt.wantToken, got.Token())
+				assert.Nil(t, got.Release(context.Background()))
+			}
+
+			for i, v := range tt.wantFence {
+				assert.Equal(t, v, conns[[]string{"r1", "r2", "r3"}[i]].Stats(fences[i]))
+			}
+
+			// released in background, only the locked instances
+			for i, v := range tt.wantRelease {
+				conn, cmd, want := conns[[]string{"r1", "r2", "r3"}[i]], releases[i], v
+				if want == 0 {
+					assert.Never(t, func() bool { return conn.Stats(cmd) != 0 }, 50*time.Millisecond, time.Millisecond)
+					continue
+				}
+				assert.Eventually(t, func() bool { return conn.Stats(cmd) == want }, time.Second, time.Millisecond)
+			}
+		})
+	}
+}
+
+func Test_redlockImpl_Unlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies []interface{}
+		wantErr bool
+	}{
+		{
+			name:    "quorum unlocked",
+			replies: []interface{}{int64(666), int64(666), int64(0)},
+		},
+		{
+			name:    "quorum not unlocked",
+			replies: []interface{}{int64(666), int64(1), redigo.Error("ERR fail")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conns, patches := newRedlockConns(3)
+			defer patches.Reset()
+
+			for i, name := range []string{"r1", "r2", "r3"} {
+				mockRedlockUnlock(conns[name], tt.replies[i])
+			}
+
+			err := NewRedlockProxy([]string{"r1", "r2", "r3"}).Unlock(context.Background(), "k1", "uuid")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("redlockImpl.Unlock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_redlockImpl_Refresh(t *testing.T) {
+	conns, patches := newRedlockConns(3)
+	defer patches.Reset()
+
+	refresh := redigo.NewScript(1, luaScriptRefresh).Hash()
+	for i, name := range []string{"r1", "r2", "r3"} {
+		mockRedlockAcquire(conns[name], []interface{}{int64(1), int64(i + 1)})
+		conns[name].GenericCommand("EVALSHA").Expect(int64(1)) // raise fence
+	}
+
+	// r2 and r3 lost on second refresh
+	conns["r1"].Command("EVALSHA", refresh, 1, "k1.lock", "uuid", int64(1000), int64(1)).Expect(int64(1))
+	conns["r2"].Command("EVALSHA", refresh, 1, "k1.lock", "uuid", int64(1000), int64(2)).
+		Expect(int64(1)).Expect(int64(0))
+	conns["r3"].Command("EVALSHA", refresh, 1, "k1.lock", "uuid", int64(1000), int64(3)).
+		Expect(int64(1)).ExpectError(redigo.Error("ERR fail")).Expect(int64(0))
+
+	lock, err := NewRedlockProxy([]string{"r1", "r2", "r3"}).TryLock(context.Background(), "k1", WithLockUUID("uuid"))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, int64(3), lock.Token())
+	assert.Nil(t, lock.Refresh(context.Background()))
+
+	// r3 fail, retry later
+	assert.NotNil(t, lock.Refresh(context.Background()))
+	assert.Nil(t, lock.Err())
+
+	assert.Equal(t, ErrLockLost, lock.Refresh(context.Background()))
+	assert.Equal(t, ErrLockLost, lock.Err())
+}
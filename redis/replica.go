@@ -0,0 +1,230 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+// replicaRetry the interval to retry the replica after the connection fail
+var replicaRetry = time.Second
+
+// readOnlyCommands the commands can be sent to replicas
+var readOnlyCommands = map[string]bool{
+	"BITCOUNT": true, "BITPOS": true, "DBSIZE": true, "EVALSHA_RO": true, "EVAL_RO": true,
+	"EXISTS": true, "GEODIST": true, "GEOHASH": true, "GEOPOS": true, "GEORADIUSBYMEMBER_RO": true,
+	"GEORADIUS_RO": true, "GEOSEARCH": true, "GET": true, "GETBIT": true, "GETRANGE": true,
+	"HEXISTS": true, "HGET": true, "HGETALL": true, "HKEYS": true, "HLEN": true,
+	"HMGET": true, "HRANDFIELD": true, "HSCAN": true, "HSTRLEN": true, "HVALS": true,
+	"KEYS": true, "LINDEX": true, "LLEN": true, "LPOS": true, "LRANGE": true,
+	"MGET": true, "PFCOUNT": true, "PTTL": true, "RANDOMKEY": true, "SCAN": true,
+	"SCARD": true, "SDIFF": true, "SINTER": true, "SISMEMBER": true, "SMEMBERS": true,
+	"SMISMEMBER": true, "SRANDMEMBER": true, "SSCAN": true, "STRLEN": true, "SUNION": true,
+	"TTL": true, "TYPE": true, "XLEN": true, "XRANGE": true, "XREVRANGE": true,
+	"ZCARD": true, "ZCOUNT": true, "ZLEXCOUNT": true, "ZMSCORE": true, "ZRANDMEMBER": true,
+	"ZRANGE": true, "ZRANGEBYLEX": true, "ZRANGEBYSCORE": true, "ZRANK": true, "ZREVRANGE": true,
+	"ZREVRANGEBYLEX": true, "ZREVRANGEBYSCORE": true, "ZREVRANK": true, "ZSCAN": true, "ZSCORE": true,
+}
+
+type readPrimaryKey struct{}
+
+// WithReadPrimary returns a context sending the read-only commands to the
+// primary, use it to read your writes.
+func WithReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPrimaryKey{}, true)
+}
+
+func isReadPrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	v, _ := ctx.Value(readPrimaryKey{}).(bool)
+	return v
+}
+
+// replicaPool the primary pool with the replica pools
+//
+// Get returns the primary connection, and the read-only commands are sent
+// to replicas by do. The replica failed to connect is skipped in
+// replicaRetry, and the command is sent to the primary when no replica
+// available.
+type replicaPool struct {
+	connPool
+
+	selector string
+	replicas []*replica
+	next     uint64
+}
+
+type replica struct {
+	*redigo.Pool
+
+	// latency the moving average latency in nanosecond
+	latency int64
+
+	// failedAt the unix nano of the last connection failure
+	failedAt int64
+}
+
+func newReplicaPool(cfg *serviceConfig, primary connPool) *replicaPool {
+	p := &replicaPool{
+		connPool: primary,
+		selector: cfg.ReplicaSelector,
+	}
+
+	for _, dsn := range cfg.Replicas {
+		p.replicas = append(p.replicas, &replica{
+			Pool: newStandalonePool(cfg, dsn),
+		})
+	}
+
+	return p
+}
+
+// ActiveCount returns the number of connections of primary and replicas
+func (p *replicaPool) ActiveCount() int {
+	count := p.connPool.ActiveCount()
+	for _, v := range p.replicas {
+		count += v.ActiveCount()
+	}
+
+	return count
+}
+
+// IdleCount returns the number of idle connections of primary and replicas
+func (p *replicaPool) IdleCount() int {
+	count := p.connPool.IdleCount()
+	for _, v := range p.replicas {
+		count += v.IdleCount()
+	}
+
+	return count
+}
+
+// Close close the pools of primary and replicas
+func (p *replicaPool) Close() error {
+	err := p.connPool.Close()
+	for _, v := range p.replicas {
+		if e := v.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// readable report whether the command can be sent to replicas
+func (p *replicaPool) readable(ctx context.Context, cmd string) bool {
+	return !isReadPrimary(ctx) && readOnlyCommands[strings.ToUpper(cmd)]
+}
+
+// do send the command to a replica, and send to the primary when the
+// replica connection fail
+func (p *replicaPool) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if r := p.pick(); r != nil {
+		conn := r.Get()
+		start := time.Now()
+		reply, err := redigo.DoContext(conn, ctx, cmd, args...)
+		if ctx == nil || ctx.Err() == nil {
+			r.observe(time.Since(start), err)
+		}
+		if e := conn.Close(); e != nil {
+			logErrorf("connect close fail. error:%v", e)
+		}
+
+		if !isConnError(ctx, err) {
+			return reply, err
+		}
+
+		logErrorf("replica %s fail, send to primary. error:%v", cmd, err)
+	}
+
+	return doContext(ctx, p.connPool, cmd, args...)
+}
+
+// pick pick a replica by selector, nil if no replica available
+func (p *replicaPool) pick() *replica {
+	n := uint64(len(p.replicas))
+	if n == 0 {
+		return nil
+	}
+
+	var picked *replica
+	start := atomic.AddUint64(&p.next, 1)
+	for i := uint64(0); i < n; i++ {
+		r := p.replicas[(start+i)%n]
+		if !r.available() {
+			continue
+		}
+
+		if p.selector != ReplicaLatency {
+			return r
+		}
+
+		if picked == nil || atomic.LoadInt64(&r.latency) < atomic.LoadInt64(&picked.latency) {
+			picked = r
+		}
+	}
+
+	return picked
+}
+
+func (r *replica) available() bool {
+	failedAt := atomic.LoadInt64(&r.failedAt)
+	return failedAt == 0 || time.Since(time.Unix(0, failedAt)) >= replicaRetry
+}
+
+// observe update the latency, and mark the replica failed on connection
+// error
+func (r *replica) observe(d time.Duration, err error) {
+	if err != nil && !isRedisError(err) {
+		atomic.StoreInt64(&r.failedAt, time.Now().UnixNano())
+		return
+	}
+
+	atomic.StoreInt64(&r.failedAt, 0)
+	for {
+		old := atomic.LoadInt64(&r.latency)
+		latency := int64(d)
+		if old != 0 {
+			latency = old + (int64(d)-old)/8
+		}
+
+		if atomic.CompareAndSwapInt64(&r.latency, old, latency) {
+			return
+		}
+	}
+}
+
+// isConnError report whether err is the connection error, the command may
+// be retried on another node
+func isConnError(ctx context.Context, err error) bool {
+	return err != nil && !isRedisError(err) && (ctx == nil || ctx.Err() == nil)
+}
+
+func isRedisError(err error) bool {
+	var e redigo.Error
+	return errors.As(err, &e) || errors.Is(err, redigo.ErrNil)
+}
+
+// doContext send the command by pool, the read-only command is sent to
+// replicas when configured
+func doContext(ctx context.Context, pool connPool, cmd string, args ...interface{}) (interface{}, error) {
+	if p, ok := pool.(*replicaPool); ok && p.readable(ctx, cmd) {
+		return p.do(ctx, cmd, args...)
+	}
+
+	conn := pool.Get()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logErrorf("connect close fail. error:%v", err)
+		}
+	}()
+
+	return redigo.DoContext(conn, ctx, cmd, args...)
+}
@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_replicaPool(t *testing.T) {
+	primary := redigomock.NewConn()
+	primary.Command("GET", "foo").Expect([]byte("primary"))
+	primary.Command("SET", "foo", "bar").Expect("OK")
+	primary.Command("SET", "fetch", []byte(`"bar"`), "PX", int64(1000), "NX").Expect("OK")
+
+	replica1 := redigomock.NewConn()
+	replica1.Command("GET", "foo").Expect([]byte("replica1"))
+	replica1.Command("GET", "fetch").ExpectError(redigo.ErrNil)
+
+	replica2 := redigomock.NewConn()
+	replica2.Command("GET", "foo").Expect([]byte("replica2"))
+	replica2.Command("GET", "fetch").ExpectError(redigo.ErrNil)
+	replica1.Command("GET", "lost").ExpectError(redigo.ErrNil)
+	replica2.Command("GET", "lost").ExpectError(redigo.ErrNil)
+	primary.Command("SET", "lost", []byte(`"bar"`), "PX", int64(1000), "NX").Expect(nil)
+	primary.Command("GET", "lost").Expect([]byte(`"newer"`))
+
+	replicaRetry = time.Hour
+	down := true
+	patches := gomonkey.ApplyFunc(redigo.DialURL,
+		func(rawurl string, _ ...redigo.DialOption) (redigo.Conn, error) {
+			u, _ := url.Parse(rawurl)
+			switch u.Host {
+			case "127.0.0.1:6379":
+				return primary, nil
+			case "127.0.0.1:6380":
+				return replica1, nil
+			case "127.0.0.1:6381":
+				if down {
+					return nil, fmt.Errorf("connection refused")
+				}
+				return replica2, nil
+			}
+			return nil, fmt.Errorf("dial %s fail", u.Host)
+		})
+	defer patches.Reset()
+
+	cli := NewClientProxy(fmt.Sprintf("redis_replica_%d", time.Now().UnixNano()),
+		WithClientDSN("redis://127.0.0.1:6379/0"),
+		WithClientReplicas("redis://127.0.0.1:6380/0", "redis://127.0.0.1:6381/0"),
+	)
+	ctx := context.Background()
+
+	// the replica down is skipped, send to primary
+	got, err := redigo.String(cli.Do(ctx, "GET", "foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "primary", got)
+
+	got, err = redigo.String(cli.Do(ctx, "GET", "foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "replica1", got)
+
+	got, err = redigo.String(cli.Do(ctx, "GET", "foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "replica1", got, "the replica down should be skipped")
+
+	// write to primary
+	got, err = redigo.String(cli.Do(ctx, "SET", "foo", "bar"))
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", got)
+
+	// read your writes
+	got, err = redigo.String(cli.Do(WithReadPrimary(ctx), "GET", "foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "primary", got)
+
+	// fetch from replica, write to primary
+	var dest string
+	err = cli.Fetcher().Fetch(ctx, "fetch", &dest,
+		WithFetchCallback(func() (interface{}, error) { return "bar", nil }, time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, "bar", dest)
+	assert.Equal(t, 1, primary.Stats(primary.Command("SET", "fetch", []byte(`"bar"`), "PX", int64(1000), "NX")))
+
+	// the replica up after retry interval
+	down = false
+	replicaRetry = 0
+	got, err = redigo.String(cli.Do(ctx, "GET", "foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "replica2", got)
+
+	// the newer value set by the others is read from primary
+	err = cli.Fetcher().Fetch(ctx, "lost", &dest,
+		WithFetchCallback(func() (interface{}, error) { return "bar", nil }, time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, "newer", dest)
+}
+
+func Test_replicaPool_pick(t *testing.T) {
+	replicaRetry = time.Hour
+	p := &replicaPool{
+		selector: ReplicaLatency,
+		replicas: []*replica{
+			{latency: int64(3 * time.Millisecond)},
+			{latency: int64(time.Millisecond)},
+			{latency: int64(2 * time.Millisecond)},
+		},
+	}
+
+	for i := 0; i < len(p.replicas); i++ {
+		assert.Equal(t, p.replicas[1], p.pick())
+	}
+
+	p.replicas[1].observe(0, fmt.Errorf("connection refused"))
+	assert.Equal(t, p.replicas[2], p.pick())
+
+	p.replicas[2].observe(18*time.Millisecond, redigo.Error("ERR"))
+	assert.Equal(t, int64(4*time.Millisecond), p.replicas[2].latency)
+	assert.Equal(t, p.replicas[0], p.pick())
+
+	p.selector = ReplicaRoundRobin
+	p.replicas[1].failedAt = 0
+	assert.NotEqual(t, p.pick(), p.pick())
+}
@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+// RWMutexProxy distributed reader/writer mutual exclusion lock
+//
+// The lock can be held by many readers or one writer. Each reader expires
+// individually, so a crashed reader does not block the writers forever.
+// The writer is reentrant like LockerProxy, and the writer can also take
+// the read lock with the same UUID.
+//
+// The readers may starve the writers when the read lock is always held.
+//
+//go:generate mockgen -source=rwmutex.go -destination=mockredis/rwmutex_mock.go -package=mockredis
+type RWMutexProxy interface {
+
+	// TryRLock try get the read lock, if lock acquired will return the lock
+	// handle.
+	//
+	// Not block the current goroutine.
+	// Return ErrLockNotAcquired when the write lock held by others.
+	// If Heartbeat option not empty, will automatically renewal until
+	// released or lost.
+	TryRLock(ctx context.Context, key string, opts ...LockOption) (Lock, error)
+
+	// RLock try get the read lock until the context canceled or the lock
+	// acquired
+	//
+	// Will block the current goroutine.
+	// If Heartbeat option not empty, will automatically renewal until
+	// released or lost.
+	RLock(ctx context.Context, key string, opts ...LockOption) (Lock, error)
+
+	// TryLock try get the write lock, if lock acquired will return the lock
+	// handle.
+	//
+	// Not block the current goroutine.
+	// Return ErrLockNotAcquired when the lock held by others.
+	// Will reentrant lock when UUID option not empty.
+	// If Heartbeat option not empty, will automatically renewal until
+	// released or lost.
+	TryLock(ctx context.Context, key string, opts ...LockOption) (Lock, error)
+
+	// Lock try get the write lock until the context canceled or the lock
+	// acquired
+	//
+	// Will block the current goroutine.
+	// Will reentrant lock when UUID option not empty.
+	// If Heartbeat option not empty, will automatically renewal until
+	// released or lost.
+	Lock(ctx context.Context, key string, opts ...LockOption) (Lock, error)
+}
+
+type rwMutexImpl struct {
+	name string
+	opts []ClientOption
+}
+
+// NewRWMutexProxy new reader/writer lock proxy
+func NewRWMutexProxy(name string, opts ...ClientOption) RWMutexProxy {
+	return &rwMutexImpl{
+		name: name,
+		opts: opts,
+	}
+}
+
+// TryRLock try get the read lock
+func (r *rwMutexImpl) TryRLock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
+	writer, readers, fence := rwMutexKeys(key)
+	options := newLockOptions(opts...)
+	pool := r.getPool()
+
+	start := time.Now()
+	token, err := Int64(evalScript(ctx, pool, redigo.NewScript(3, luaScriptRLock),
+		writer, readers, fence, options.UUID, options.Expire.Milliseconds(), lockFenceExpire.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	if token == 0 {
+		return nil, ErrLockNotAcquired
+	}
+
+	return newLock(key, options, token, start.Add(options.Expire),
+		func(ctx context.Context) (time.Time, error) {
+			return refreshHolder(ctx, pool, readers, options.UUID, options.Expire)
+		},
+		func(ctx context.Context) error {
+			// the writers wait on the writer key
+			return releaseHolder(ctx, pool, readers, writer, options.UUID)
+		}), nil
+}
+
+// RLock try get the read lock until the context canceled or the lock acquired
+func (r *rwMutexImpl) RLock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
+	writer, _, _ := rwMutexKeys(key)
+	return waitLock(ctx, r.name, r.opts, writer, newLockOptions(opts...).Retry, func() (Lock, error) {
+		return r.TryRLock(ctx, key, opts...)
+	})
+}
+
+// TryLock try get the write lock
+func (r *rwMutexImpl) TryLock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
+	writer, readers, fence := rwMutexKeys(key)
+	options := newLockOptions(opts...)
+	pool := r.getPool()
+
+	start := time.Now()
+	token, err := Int64(evalScript(ctx, pool, redigo.NewScript(3, luaScriptWLock),
+		writer, readers, fence, options.UUID, options.Expire.Milliseconds(), lockFenceExpire.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	if token == 0 {
+		return nil, ErrLockNotAcquired
+	}
+
+	return newLock(key, options, token, start.Add(options.Expire),
+		func(ctx context.Context) (time.Time, error) {
+			start := time.Now()
+			owned, err := refreshLock(ctx, pool, writer, options.UUID, options.Expire, token)
+			if err != nil || !owned {
+				return time.Time{}, err
+			}
+
+			return start.Add(options.Expire), nil
+		},
+		func(ctx context.Context) error {
+			return unlock(ctx, pool, writer, options.UUID)
+		}), nil
+}
+
+// Lock try get the write lock until the context canceled or the lock acquired
+func (r *rwMutexImpl) Lock(ctx context.Context, key string, opts ...LockOption) (Lock, error) {
+	writer, _, _ := rwMutexKeys(key)
+	return waitLock(ctx, r.name, r.opts, writer, newLockOptions(opts...).Retry, func() (Lock, error) {
+		return r.TryLock(ctx, key, opts...)
+	})
+}
+
+func (r *rwMutexImpl) getPool() connPool {
+	return getRedisPool(r.name, r.opts...)
+}
+
+// rwMutexKeys returns the keys of writer, readers and fencing counter, which
+// are in the same cluster slot
+func rwMutexKeys(key string) (writer, readers, fence string) {
+	k := key + ".rwlock"
+	return hashTagKey(k, ".writer"), hashTagKey(k, ".readers"), hashTagKey(k, ".fence")
+}
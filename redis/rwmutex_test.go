@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rwMutexImpl_TryRLock(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     interface{}
+		wantToken int64
+		wantErr   error
+	}{
+		{
+			name:    "script fail",
+			reply:   redigo.Error("ERR fail"),
+			wantErr: redigo.Error("ERR fail"),
+		},
+		{
+			name:    "write locked by others",
+			reply:   int64(0),
+			wantErr: ErrLockNotAcquired,
+		},
+		{
+			name:      "normal process",
+			reply:     int64(3),
+			wantToken: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			cmd := conn.Command("EVALSHA", redigo.NewScript(3, luaScriptRLock).Hash(), 3,
+				"{k1.rwlock}.writer", "{k1.rwlock}.readers", "{k1.rwlock}.fence", "uuid", int64(1000), lockFenceExpire.Milliseconds())
+			if err, ok := tt.reply.(error); ok {
+				cmd.ExpectError(err)
+			} else {
+				cmd.Expect(tt.reply)
+			}
+
+			conn.Command("EVALSHA", redigo.NewScript(1, luaScriptHolderRefresh).Hash(), 1,
+				"{k1.rwlock}.readers", "uuid", int64(1000)).Expect(int64(1))
+			release := conn.Command("EVALSHA", redigo.NewScript(2, luaScriptHolderRelease).Hash(), 2,
+				"{k1.rwlock}.readers", "{k1.rwlock}.writer", "uuid").Expect(int64(1)).Expect(int64(0))
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			got, err := NewRWMutexProxy("client_name").TryRLock(context.Background(), "k1", WithLockUUID("uuid"))
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantToken, got.Token())
+			assert.Nil(t, got.Refresh(context.Background()))
+			assert.Nil(t, got.Release(context.Background()))
+			assert.Equal(t, ErrLockReleased, got.Err())
+			assert.Equal(t, 1, conn.Stats(release))
+		})
+	}
+}
+
+func Test_rwMutexImpl_TryLock(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     interface{}
+		wantToken int64
+		wantErr   error
+	}{
+		{
+			name:    "read locked by others",
+			reply:   int64(0),
+			wantErr: ErrLockNotAcquired,
+		},
+		{
+			name:      "normal process",
+			reply:     int64(4),
+			wantToken: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			conn.Command("EVALSHA", redigo.NewScript(3, luaScriptWLock).Hash(), 3,
+				"{k1.rwlock}.writer", "{k1.rwlock}.readers", "{k1.rwlock}.fence", "uuid", int64(1000), lockFenceExpire.Milliseconds()).Expect(tt.reply)
+			conn.Command("EVALSHA", redigo.NewScript(1, luaScriptRefresh).Hash(), 1,
+				"{k1.rwlock}.writer", "uuid", int64(1000), tt.wantToken).Expect(int64(0))
+			release := conn.Command("EVALSHA", redigo.NewScript(1, luaScriptUnlock).Hash(), 1,
+				"{k1.rwlock}.writer", "uuid").Expect(int64(666))
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			got, err := NewRWMutexProxy("client_name").TryLock(context.Background(), "k1", WithLockUUID("uuid"))
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+
+			// lost after the refresh fail, and the release still performed
+			assert.Equal(t, tt.wantToken, got.Token())
+			assert.Equal(t, ErrLockLost, got.Refresh(context.Background()))
+			assert.Nil(t, got.Release(context.Background()))
+			assert.Equal(t, ErrLockLost, got.Err())
+			assert.Equal(t, 1, conn.Stats(release))
+		})
+	}
+}
+
+func Test_rwMutexImpl_Lock(t *testing.T) {
+	conn := redigomock.NewConn()
+	conn.Command("EVALSHA", redigo.NewScript(3, luaScriptWLock).Hash(), 3,
+		"{k1.rwlock}.writer", "{k1.rwlock}.readers", "{k1.rwlock}.fence", "uuid", int64(1000), lockFenceExpire.Milliseconds()).
+		Expect(int64(0)).Expect(int64(0)).Expect(int64(5))
+
+	// subscribe fail and polling
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return &mockPool{conn: conn}
+		})
+	defer patches.Reset()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	got, err := NewRWMutexProxy("client_name").Lock(ctx, "k1", WithLockUUID("uuid"), WithLockRetry(time.Millisecond))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, int64(5), got.Token())
+	assert.Equal(t, "k1", got.Key())
+}
@@ -1,21 +1,64 @@
 package redis
 
 var (
-	luaScriptLock = `
+	// luaFenceToken increase the fencing token counter and refresh its
+	// expire, the counter restarts from the current time in microseconds
+	// after expired
+	luaFenceToken = `
+local function fence_token(key, expire)
+  local token = redis.call('INCR', key)
+  if (token == 1)
+  then
+    local t = redis.call('TIME')
+    token = tonumber(t[1]) * 1000000 + tonumber(t[2])
+    redis.call('SET', key, token)
+  end
+
+  redis.call('PEXPIRE', key, expire)
+  return token
+end
+`
+
+	// luaScriptLock KEYS: lock, fence ARGV: uuid, expire, fence expire
+	luaScriptLock = luaFenceToken + `
 if (redis.call('EXISTS', KEYS[1]) == 0)
 then
+  local token = fence_token(KEYS[2], ARGV[3])
   redis.call('HSET', KEYS[1], 'UUID', ARGV[1])
+  redis.call('HSET', KEYS[1], 'TOKEN', token)
   redis.call('PEXPIRE', KEYS[1], ARGV[2])
-  return redis.call('HINCRBY', KEYS[1], 'COUNT', 1)
+  return {redis.call('HINCRBY', KEYS[1], 'COUNT', 1), token}
 end
 
 if (redis.call('HGET', KEYS[1], 'UUID') == ARGV[1])
 then
   redis.call('PEXPIRE', KEYS[1], ARGV[2])
-  return redis.call('HINCRBY', KEYS[1], 'COUNT', 1)
+  local token = tonumber(redis.call('HGET', KEYS[1], 'TOKEN') or 0)
+  return {redis.call('HINCRBY', KEYS[1], 'COUNT', 1), token}
+end
+
+return {0, 0}
+`
+
+	luaScriptRefresh = `
+if (redis.call('HGET', KEYS[1], 'UUID') == ARGV[1] and
+    tonumber(redis.call('HGET', KEYS[1], 'TOKEN') or 0) == tonumber(ARGV[3]))
+then
+  return redis.call('PEXPIRE', KEYS[1], ARGV[2])
 end
 
 return 0
+`
+
+	// luaScriptRaiseFence KEYS: fence ARGV: token, fence expire
+	luaScriptRaiseFence = `
+if ((tonumber(redis.call('GET', KEYS[1]) or 0)) < tonumber(ARGV[1]))
+then
+  redis.call('SET', KEYS[1], ARGV[1])
+end
+
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
 `
 
 	luaScriptUnlock = `
@@ -47,5 +90,216 @@ end
 
 redis.call('PUBLISH', KEYS[1], 1)
 return ret_success
+`
+	// luaScriptRLock KEYS: writer, readers, fence ARGV: uuid, expire, fence expire
+	luaScriptRLock = luaFenceToken + `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+if (redis.call('EXISTS', KEYS[1]) == 1 and redis.call('HGET', KEYS[1], 'UUID') ~= ARGV[1])
+then
+  return 0
+end
+
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+redis.call('ZADD', KEYS[2], now + tonumber(ARGV[2]), ARGV[1])
+if (redis.call('PTTL', KEYS[2]) < tonumber(ARGV[2]))
+then
+  redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+
+return fence_token(KEYS[3], ARGV[3])
+`
+
+	// luaScriptWLock KEYS: writer, readers, fence ARGV: uuid, expire, fence expire
+	luaScriptWLock = luaFenceToken + `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+if (redis.call('EXISTS', KEYS[1]) == 0)
+then
+  local readers = redis.call('ZCARD', KEYS[2])
+  if (readers > 1 or (readers == 1 and not redis.call('ZSCORE', KEYS[2], ARGV[1])))
+  then
+    return 0
+  end
+
+  local token = fence_token(KEYS[3], ARGV[3])
+  redis.call('HSET', KEYS[1], 'UUID', ARGV[1])
+  redis.call('HSET', KEYS[1], 'TOKEN', token)
+  redis.call('HINCRBY', KEYS[1], 'COUNT', 1)
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  return token
+end
+
+if (redis.call('HGET', KEYS[1], 'UUID') == ARGV[1])
+then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  redis.call('HINCRBY', KEYS[1], 'COUNT', 1)
+  return tonumber(redis.call('HGET', KEYS[1], 'TOKEN') or 0)
+end
+
+return 0
+`
+
+	// luaScriptSemAcquire KEYS: holders, fence ARGV: uuid, expire, permits, fence expire
+	luaScriptSemAcquire = luaFenceToken + `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if (not redis.call('ZSCORE', KEYS[1], ARGV[1]) and redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]))
+then
+  return 0
+end
+
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+if (redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]))
+then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+
+return fence_token(KEYS[2], ARGV[4])
+`
+
+	// luaScriptHolderRefresh KEYS: holders ARGV: uuid, expire
+	luaScriptHolderRefresh = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if (not score or tonumber(score) <= now)
+then
+  redis.call('ZREM', KEYS[1], ARGV[1])
+  return 0
+end
+
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+if (redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]))
+then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+
+return 1
+`
+
+	// luaScriptHolderRelease KEYS: holders, channel ARGV: uuid
+	luaScriptHolderRelease = `
+if (redis.call('ZREM', KEYS[1], ARGV[1]) == 0)
+then
+  return 0
+end
+
+redis.call('PUBLISH', KEYS[2], 1)
+return 1
+`
+
+	// luaScriptRateSlidingWindow KEYS: log ARGV: limit, window, n, id
+	luaScriptRateSlidingWindow = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if (count + n > limit)
+then
+  local retry = -1
+  if (n <= limit)
+  then
+    local idx = count + n - limit - 1
+    local entry = redis.call('ZRANGE', KEYS[1], idx, idx, 'WITHSCORES')
+    retry = tonumber(entry[2]) + window - now + 1
+  end
+
+  local reset = 0
+  if (count > 0)
+  then
+    local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+    reset = tonumber(last[2]) + window - now + 1
+  end
+
+  return {0, math.max(limit - count, 0), retry, reset}
+end
+
+for i = 1, n do
+  redis.call('ZADD', KEYS[1], now, ARGV[4] .. ':' .. i)
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+return {1, limit - count - n, 0, window}
+`
+
+	// luaScriptRateFixedWindow KEYS: counter ARGV: limit, window, n
+	luaScriptRateFixedWindow = `
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+local count = tonumber(redis.call('GET', KEYS[1]) or 0)
+local ttl = redis.call('PTTL', KEYS[1])
+if (ttl < 0)
+then
+  count = 0
+  ttl = window
+end
+
+if (count + n > limit)
+then
+  local retry = ttl
+  if (n > limit)
+  then
+    retry = -1
+  end
+
+  return {0, math.max(limit - count, 0), retry, ttl}
+end
+
+if (count == 0)
+then
+  redis.call('SET', KEYS[1], n, 'PX', window)
+else
+  redis.call('INCRBY', KEYS[1], n)
+end
+
+return {1, limit - count - n, 0, ttl}
+`
+
+	// luaScriptRateGCRA KEYS: tat ARGV: emission interval (us), burst, n
+	luaScriptRateGCRA = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local emission = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local tolerance = emission * burst
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if (tat < now)
+then
+  tat = now
+end
+
+local new_tat = tat + emission * n
+local allow_at = new_tat - tolerance
+if (allow_at > now)
+then
+  local retry = -1
+  if (n <= burst)
+  then
+    retry = math.ceil((allow_at - now) / 1000)
+  end
+
+  local remaining = math.floor((now - (tat - tolerance)) / emission)
+  return {0, math.max(remaining, 0), retry, math.ceil((tat - now) / 1000)}
+end
+
+local reset = math.ceil((new_tat - now) / 1000)
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.max(reset, 1))
+
+return {1, math.floor((now - allow_at) / emission), 0, reset}
 `
 )
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+// SemaphoreProxy distributed counting semaphore
+//
+// Each holder takes one of the permits, and expires individually, so a
+// crashed holder does not leak its permit. The holder with the same UUID
+// takes one permit only.
+//
+//go:generate mockgen -source=semaphore.go -destination=mockredis/semaphore_mock.go -package=mockredis
+type SemaphoreProxy interface {
+
+	// TryAcquire try take a permit of the semaphore with the permits in
+	// total, if acquired will return the permit handle.
+	//
+	// Not block the current goroutine.
+	// Return ErrLockNotAcquired when all permits taken.
+	// If Heartbeat option not empty, will automatically renewal until
+	// released or lost.
+	TryAcquire(ctx context.Context, key string, permits int, opts ...LockOption) (Lock, error)
+
+	// Acquire try take a permit until the context canceled or acquired
+	//
+	// Will block the current goroutine.
+	// If Heartbeat option not empty, will automatically renewal until
+	// released or lost.
+	Acquire(ctx context.Context, key string, permits int, opts ...LockOption) (Lock, error)
+}
+
+type semaphoreImpl struct {
+	name string
+	opts []ClientOption
+}
+
+// NewSemaphoreProxy new counting semaphore proxy
+func NewSemaphoreProxy(name string, opts ...ClientOption) SemaphoreProxy {
+	return &semaphoreImpl{
+		name: name,
+		opts: opts,
+	}
+}
+
+// TryAcquire try take a permit of the semaphore
+func (s *semaphoreImpl) TryAcquire(ctx context.Context, key string, permits int, opts ...LockOption) (Lock, error) {
+	if permits <= 0 {
+		return nil, errors.New("redis: semaphore permits must be positive")
+	}
+
+	k := semaphoreKey(key)
+	options := newLockOptions(opts...)
+	pool := s.getPool()
+
+	start := time.Now()
+	token, err := Int64(evalScript(ctx, pool, redigo.NewScript(2, luaScriptSemAcquire),
+		k, lockFenceKey(k), options.UUID, options.Expire.Milliseconds(), permits, lockFenceExpire.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	if token == 0 {
+		return nil, ErrLockNotAcquired
+	}
+
+	return newLock(key, options, token, start.Add(options.Expire),
+		func(ctx context.Context) (time.Time, error) {
+			return refreshHolder(ctx, pool, k, options.UUID, options.Expire)
+		},
+		func(ctx context.Context) error {
+			return releaseHolder(ctx, pool, k, k, options.UUID)
+		}), nil
+}
+
+// Acquire try take a permit until the context canceled or acquired
+func (s *semaphoreImpl) Acquire(ctx context.Context, key string, permits int, opts ...LockOption) (Lock, error) {
+	return waitLock(ctx, s.name, s.opts, semaphoreKey(key), newLockOptions(opts...).Retry, func() (Lock, error) {
+		return s.TryAcquire(ctx, key, permits, opts...)
+	})
+}
+
+func (s *semaphoreImpl) getPool() connPool {
+	return getRedisPool(s.name, s.opts...)
+}
+
+// semaphoreKey returns the redis key of semaphore holders
+func semaphoreKey(key string) string {
+	return key + ".sem"
+}
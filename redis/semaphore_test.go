@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_semaphoreImpl_TryAcquire(t *testing.T) {
+	tests := []struct {
+		name      string
+		permits   int
+		reply     interface{}
+		wantToken int64
+		wantErr   bool
+	}{
+		{
+			name:    "invalid permits",
+			wantErr: true,
+		},
+		{
+			name:    "script fail",
+			permits: 2,
+			reply:   redigo.Error("ERR fail"),
+			wantErr: true,
+		},
+		{
+			name:    "all permits taken",
+			permits: 2,
+			reply:   int64(0),
+			wantErr: true,
+		},
+		{
+			name:      "normal process",
+			permits:   2,
+			reply:     int64(6),
+			wantToken: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			cmd := conn.Command("EVALSHA", redigo.NewScript(2, luaScriptSemAcquire).Hash(), 2,
+				"k1.sem", "{k1.sem}.fence", "uuid", int64(1000), tt.permits, lockFenceExpire.Milliseconds())
+			if err, ok := tt.reply.(error); ok {
+				cmd.ExpectError(err)
+			} else {
+				cmd.Expect(tt.reply)
+			}
+
+			// released by others, e.g. expired
+			conn.Command("EVALSHA", redigo.NewScript(2, luaScriptHolderRelease).Hash(), 2,
+				"k1.sem", "k1.sem", "uuid").Expect(int64(0))
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			got, err := NewSemaphoreProxy("client_name").TryAcquire(context.Background(), "k1", tt.permits,
+				WithLockUUID("uuid"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("semaphoreImpl.TryAcquire() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantToken, got.Token())
+			assert.Equal(t, ErrLockNotExist, got.Release(context.Background()))
+		})
+	}
+}
+
+func Test_semaphoreImpl_Acquire(t *testing.T) {
+	conn := redigomock.NewConn()
+	conn.Command("EVALSHA", redigo.NewScript(2, luaScriptSemAcquire).Hash(), 2,
+		"k1.sem", "{k1.sem}.fence", "uuid", int64(1000), 1, lockFenceExpire.Milliseconds()).
+		Expect(int64(0)).Expect(int64(0)).Expect(int64(7))
+
+	useReleaseWatcher(t, "client_name", newSubscribedConn("SUBSCRIBE", "k1.sem", "1"))
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return &mockPool{conn: conn}
+		})
+	defer patches.Reset()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// retried on the release notification
+	got, err := NewSemaphoreProxy("client_name").Acquire(ctx, "k1", 1, WithLockUUID("uuid"), WithLockRetry(time.Hour))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, int64(7), got.Token())
+}
@@ -0,0 +1,257 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+var (
+	// sentinelRetry the interval to resubscribe the switch-master event
+	// after the sentinel connection lost
+	sentinelRetry = time.Second
+
+	// sentinelPing the interval to ping the subscribed sentinel, the dead
+	// connection will be detected in twice the interval
+	sentinelPing = 10 * time.Second
+
+	errMasterSwitched = errors.New("redis: master switched")
+)
+
+// sentinelPool the pool of the master discovered from sentinels
+//
+// The connections are dialed to the master asked from sentinels, and the
+// +switch-master event is subscribed to drop the connections of the old
+// master on failover.
+type sentinelPool struct {
+	*redigo.Pool
+
+	cfg       serviceConfig
+	sentinels []string
+
+	// generation is increased on failover, the connections dialed before
+	// will be closed on borrow
+	generation int64
+
+	rw     sync.Mutex
+	sub    redigo.Conn
+	closed bool
+}
+
+func newSentinelPool(cfg *serviceConfig) *sentinelPool {
+	p := &sentinelPool{
+		cfg:       *cfg,
+		sentinels: dsnHosts(cfg.DSN),
+	}
+
+	p.Pool = newStandalonePool(cfg, cfg.DSN)
+	p.Pool.Dial = p.dial
+	p.Pool.TestOnBorrow = p.testOnBorrow
+
+	go p.watch()
+	return p
+}
+
+// sentinelConn the master connection with the generation dialed in
+type sentinelConn struct {
+	redigo.Conn
+	generation int64
+}
+
+// DoContext implements redigo.ConnWithContext
+func (c *sentinelConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return redigo.DoContext(c.Conn, ctx, cmd, args...)
+}
+
+// ReceiveContext implements redigo.ConnWithContext
+func (c *sentinelConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return redigo.ReceiveContext(c.Conn, ctx)
+}
+
+// DoWithTimeout implements redigo.ConnWithTimeout
+func (c *sentinelConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	return redigo.DoWithTimeout(c.Conn, timeout, cmd, args...)
+}
+
+// ReceiveWithTimeout implements redigo.ConnWithTimeout
+func (c *sentinelConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return redigo.ReceiveWithTimeout(c.Conn, timeout)
+}
+
+// dial dial the master and check its role
+func (p *sentinelPool) dial() (redigo.Conn, error) {
+	if p.cfg.MasterName == "" {
+		return nil, errors.New("redis: master name of sentinel required")
+	}
+
+	generation := atomic.LoadInt64(&p.generation)
+	addr, err := p.masterAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := dialURL(&p.cfg, replaceHost(p.cfg.DSN, addr))
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := redigo.Values(c.Do("ROLE"))
+	if err == nil && (len(role) == 0 || fmt.Sprintf("%s", role[0]) != "master") {
+		err = fmt.Errorf("redis: %s is not master", addr)
+	}
+
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return &sentinelConn{
+		Conn:       c,
+		generation: generation,
+	}, nil
+}
+
+func (p *sentinelPool) testOnBorrow(c redigo.Conn, t time.Time) error {
+	if sc, ok := c.(*sentinelConn); ok && sc.generation != atomic.LoadInt64(&p.generation) {
+		return errMasterSwitched
+	}
+
+	return testOnBorrow(c, t)
+}
+
+// masterAddr ask the master address from sentinels in order
+func (p *sentinelPool) masterAddr() (string, error) {
+	var errs []string
+	for _, addr := range p.sentinels {
+		c, err := p.dialSentinel(addr)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		master, err := redigo.Strings(c.Do("SENTINEL", "get-master-addr-by-name", p.cfg.MasterName))
+		c.Close()
+		if err == nil && len(master) != 2 {
+			err = fmt.Errorf("master %s not found", p.cfg.MasterName)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("sentinel %s: %v", addr, err))
+			continue
+		}
+
+		return net.JoinHostPort(master[0], master[1]), nil
+	}
+
+	return "", fmt.Errorf("redis: get master addr fail. error:%s", strings.Join(errs, "; "))
+}
+
+func (p *sentinelPool) dialSentinel(addr string) (redigo.Conn, error) {
+	dsn := fmt.Sprintf("redis://%s", addr)
+	if p.cfg.SentinelPassword != "" {
+		dsn = fmt.Sprintf("redis://:%s@%s", p.cfg.SentinelPassword, addr)
+	}
+
+	return dialURL(&p.cfg, dsn)
+}
+
+// watch subscribe the +switch-master event until the pool closed
+func (p *sentinelPool) watch() {
+	if len(p.sentinels) == 0 {
+		logErrorf("sentinel address not found in dsn. name:%s", p.cfg.Name)
+		return
+	}
+
+	for i := 0; ; i++ {
+		addr := p.sentinels[i%len(p.sentinels)]
+		c, err := p.dialSentinel(addr)
+		if err == nil {
+			err = p.subscribe(c)
+		}
+
+		if p.isClosed() {
+			return
+		}
+
+		logErrorf("sentinel %s subscribe fail, retry after %v. error:%v", addr, sentinelRetry, err)
+		time.Sleep(sentinelRetry)
+	}
+}
+
+func (p *sentinelPool) subscribe(c redigo.Conn) error {
+	p.rw.Lock()
+	if p.closed {
+		p.rw.Unlock()
+		c.Close()
+		return nil
+	}
+	p.sub = c
+	p.rw.Unlock()
+
+	psc := redigo.PubSubConn{Conn: c}
+	defer psc.Close()
+
+	if err := psc.Subscribe("+switch-master"); err != nil {
+		return err
+	}
+
+	// the failover may happen before subscribed
+	atomic.AddInt64(&p.generation, 1)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(sentinelPing)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := psc.Ping(""); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		switch v := psc.ReceiveWithTimeout(2 * sentinelPing).(type) {
+		case redigo.Message:
+			// <master name> <old ip> <old port> <new ip> <new port>
+			fields := strings.Fields(string(v.Data))
+			if len(fields) == 5 && fields[0] == p.cfg.MasterName {
+				atomic.AddInt64(&p.generation, 1)
+				logInfof("sentinel master %s switched to %s", p.cfg.MasterName, net.JoinHostPort(fields[3], fields[4]))
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+func (p *sentinelPool) isClosed() bool {
+	p.rw.Lock()
+	defer p.rw.Unlock()
+	return p.closed
+}
+
+// Close stop watching and close the pool
+func (p *sentinelPool) Close() error {
+	p.rw.Lock()
+	p.closed = true
+	if p.sub != nil {
+		p.sub.Close()
+	}
+	p.rw.Unlock()
+
+	return p.Pool.Close()
+}
@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sentinelPool_dial(t *testing.T) {
+	tests := []struct {
+		name       string
+		masterName string
+		master     []interface{}
+		role       string
+		wantErr    bool
+	}{
+		{
+			name:    "master name required",
+			wantErr: true,
+		},
+		{
+			name:       "master not found",
+			masterName: "mymaster",
+			wantErr:    true,
+		},
+		{
+			name:       "not master",
+			masterName: "mymaster",
+			master:     []interface{}{[]byte("127.0.0.1"), []byte("6379")},
+			role:       "slave",
+			wantErr:    true,
+		},
+		{
+			name:       "normal process",
+			masterName: "mymaster",
+			master:     []interface{}{[]byte("127.0.0.1"), []byte("6379")},
+			role:       "master",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sentinel := redigomock.NewConn()
+			if tt.master != nil {
+				sentinel.Command("SENTINEL", "get-master-addr-by-name", tt.masterName).Expect(tt.master)
+			} else {
+				sentinel.Command("SENTINEL", "get-master-addr-by-name", tt.masterName).Expect(nil)
+			}
+
+			master := redigomock.NewConn()
+			master.Command("ROLE").Expect([]interface{}{[]byte(tt.role)})
+
+			dialed := map[string]string{}
+			patches := gomonkey.ApplyFunc(redigo.DialURL,
+				func(rawurl string, _ ...redigo.DialOption) (redigo.Conn, error) {
+					u, _ := url.Parse(rawurl)
+					dialed[u.Host] = rawurl
+					switch u.Host {
+					case "127.0.0.1:26380":
+						return sentinel, nil
+					case "127.0.0.1:6379":
+						return master, nil
+					}
+					return nil, fmt.Errorf("dial %s fail", u.Host)
+				})
+			defer patches.Reset()
+
+			p := &sentinelPool{
+				cfg: serviceConfig{
+					Name:       "sentinel",
+					Mode:       ModeSentinel,
+					DSN:        "redis://:password@127.0.0.1:26379,127.0.0.1:26380/1",
+					MasterName: tt.masterName,
+				},
+				sentinels:  []string{"127.0.0.1:26379", "127.0.0.1:26380"},
+				generation: 3,
+			}
+
+			c, err := p.dial()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sentinelPool.dial() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			assert.Equal(t, "redis://:password@127.0.0.1:6379/1", dialed["127.0.0.1:6379"])
+			assert.Nil(t, p.testOnBorrow(c, time.Now()))
+
+			p.generation++
+			assert.Equal(t, errMasterSwitched, p.testOnBorrow(c, time.Now()))
+		})
+	}
+}
+
+func Test_sentinelPool_subscribe(t *testing.T) {
+	c := redigomock.NewConn()
+	c.Command("SUBSCRIBE", "+switch-master").Expect([]interface{}{[]byte("subscribe"), []byte("+switch-master"), int64(1)})
+	c.AddSubscriptionMessage([]interface{}{[]byte("message"), []byte("+switch-master"), []byte("other 10.0.0.1 6379 10.0.0.2 6379")})
+	c.AddSubscriptionMessage([]interface{}{[]byte("message"), []byte("+switch-master"), []byte("mymaster 10.0.0.1 6379 10.0.0.2 6379")})
+
+	p := &sentinelPool{
+		cfg: serviceConfig{
+			MasterName: "mymaster",
+		},
+	}
+
+	assert.NotNil(t, p.subscribe(c), "subscribe should return when connection lost")
+	assert.Equal(t, int64(2), p.generation, "generation should be increased on subscribed and switched")
+}
@@ -0,0 +1,468 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+// streamAckTimeout the timeout of XACK and the dead-letter XADD, which are
+// not bound to the consumer context, so the handled messages are still
+// acked when the consumer canceled on shutdown
+const streamAckTimeout = 3 * time.Second
+
+// StreamProxy redis streams producer and consumer group worker
+//
+// XAUTOCLAIM requires redis 6.2 or later.
+//
+//go:generate mockgen -source=stream.go -destination=mockredis/stream_mock.go -package=mockredis
+type StreamProxy interface {
+
+	// Add appends the message to stream and returns the message id.
+	Add(ctx context.Context, stream string, values map[string]interface{}, opts ...StreamAddOption) (id string, err error)
+
+	// Consume runs the consumer group worker until the context canceled.
+	//
+	// The group will be created if not exist. The new messages are read by
+	// XREADGROUP with blocking, and the handlers run with bounded
+	// concurrency. The message is acked when handler returns nil, otherwise
+	// it stays pending and will be claimed after the idle timeout. The
+	// message exceeded max deliveries is moved to the dead-letter stream.
+	//
+	// Will block the current goroutine, and wait for the running handlers
+	// before return.
+	Consume(ctx context.Context, stream, group, consumer string, handler StreamHandler, opts ...ConsumeOption) error
+}
+
+// StreamHandler the handler of stream message
+type StreamHandler func(ctx context.Context, msg *StreamMessage) error
+
+// StreamMessage the message of stream
+type StreamMessage struct {
+	// Stream the stream of message
+	Stream string
+
+	// ID the message id
+	ID string
+
+	// Values the field values of message
+	Values map[string]string
+
+	// Deliveries the number of times the message delivered
+	Deliveries int64
+}
+
+type streamImpl struct {
+	name string
+	opts []ClientOption
+}
+
+// NewStreamProxy new stream proxy
+func NewStreamProxy(name string, opts ...ClientOption) StreamProxy {
+	return &streamImpl{
+		name: name,
+		opts: opts,
+	}
+}
+
+// Add appends the message to stream and returns the message id
+func (s *streamImpl) Add(ctx context.Context, stream string, values map[string]interface{},
+	opts ...StreamAddOption) (string, error) {
+	if len(values) == 0 {
+		return "", errors.New("redis: stream message values required")
+	}
+
+	options := newStreamAddOptions(opts...)
+	args := []interface{}{stream}
+	if options.MaxLen > 0 {
+		args = append(args, "MAXLEN")
+		if options.Approx {
+			args = append(args, "~")
+		}
+		args = append(args, options.MaxLen)
+	}
+
+	args = append(args, "*")
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k, values[k])
+	}
+
+	return String(s.do(ctx, "XADD", args...))
+}
+
+// Consume runs the consumer group worker until the context canceled
+func (s *streamImpl) Consume(ctx context.Context, stream, group, consumer string, handler StreamHandler,
+	opts ...ConsumeOption) error {
+	if handler == nil {
+		return errors.New("redis: stream handler required")
+	}
+
+	options := newConsumeOptions(opts...)
+	if options.DeadLetterStream == "" {
+		options.DeadLetterStream = stream + ":dead"
+	}
+
+	w := &streamWorker{
+		streamImpl: s,
+		stream:     stream,
+		group:      group,
+		consumer:   consumer,
+		handler:    handler,
+		options:    options,
+		sem:        make(chan struct{}, options.Concurrency),
+		claimStart: "0-0",
+	}
+
+	if err := w.createGroup(ctx); err != nil {
+		return err
+	}
+
+	w.run(ctx)
+	return nil
+}
+
+func (s *streamImpl) do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return doContext(ctx, s.getPool(), cmd, args...)
+}
+
+func (s *streamImpl) getPool() connPool {
+	return getRedisPool(s.name, s.opts...)
+}
+
+type streamWorker struct {
+	*streamImpl
+
+	stream   string
+	group    string
+	consumer string
+	handler  StreamHandler
+	options  *ConsumeOptions
+
+	// sem the slots of running handlers
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	// claimStart the cursor of XAUTOCLAIM
+	claimStart string
+}
+
+// createGroup create the group, the existing group is kept
+func (w *streamWorker) createGroup(ctx context.Context) error {
+	_, err := w.do(ctx, "XGROUP", "CREATE", w.stream, w.group, w.options.StartID, "MKSTREAM")
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	return nil
+}
+
+// run claim the idle pending messages and read the new messages until the
+// context canceled
+func (w *streamWorker) run(ctx context.Context) {
+	defer w.wg.Wait()
+
+	var claimedAt time.Time
+	for {
+		n := w.acquire(ctx)
+		if n == 0 {
+			return
+		}
+
+		var msgs []*StreamMessage
+		var err error
+		if w.options.ClaimIdle > 0 && time.Since(claimedAt) >= w.options.ClaimInterval {
+			msgs, err = w.claim(ctx, n)
+
+			// claim in each round until the pending list scanned
+			if err == nil && w.claimStart == "0-0" {
+				claimedAt = time.Now()
+			}
+		}
+
+		if err == nil && len(msgs) == 0 {
+			msgs, err = w.read(ctx, n)
+		}
+
+		w.release(n - len(msgs))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			logErrorf("consume stream %s fail, retry after %v. error:%v", w.stream, w.options.Retry, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.options.Retry):
+			}
+			continue
+		}
+
+		for _, msg := range msgs {
+			w.wg.Add(1)
+			go func(msg *StreamMessage) {
+				defer w.wg.Done()
+				defer w.release(1)
+				w.process(ctx, msg)
+			}(msg)
+		}
+	}
+}
+
+// acquire wait for a free slot and take all the free slots
+func (w *streamWorker) acquire(ctx context.Context) int {
+	select {
+	case <-ctx.Done():
+		return 0
+	case w.sem <- struct{}{}:
+	}
+
+	n := 1
+	for n < cap(w.sem) {
+		select {
+		case w.sem <- struct{}{}:
+			n++
+		default:
+			return n
+		}
+	}
+
+	return n
+}
+
+func (w *streamWorker) release(n int) {
+	for i := 0; i < n; i++ {
+		<-w.sem
+	}
+}
+
+// read read the new messages with blocking
+func (w *streamWorker) read(ctx context.Context, count int) ([]*StreamMessage, error) {
+	conn := w.getPool().Get()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logErrorf("connect close fail. error:%v", err)
+		}
+	}()
+
+	// the read timeout of connection is extended by the block time
+	reply, err := redigo.DoWithTimeout(conn, w.options.Block+time.Second, "XREADGROUP", "GROUP", w.group, w.consumer,
+		"COUNT", count, "BLOCK", w.options.Block.Milliseconds(), "STREAMS", w.stream, ">")
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	streams, err := redigo.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []*StreamMessage
+	for _, v := range streams {
+		stream, err := redigo.Values(v, nil)
+		if err != nil || len(stream) != 2 {
+			return nil, fmt.Errorf("invalid stream reply %v", v)
+		}
+
+		entries, err := parseStreamEntries(w.stream, stream[1])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, msg := range entries {
+			msg.Deliveries = 1
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs, nil
+}
+
+// claim claim the pending messages idle over ClaimIdle, and move the
+// message exceeded max deliveries to the dead-letter stream
+func (w *streamWorker) claim(ctx context.Context, count int) ([]*StreamMessage, error) {
+	reply, err := redigo.Values(w.do(ctx, "XAUTOCLAIM", w.stream, w.group, w.consumer,
+		w.options.ClaimIdle.Milliseconds(), w.claimStart, "COUNT", count))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reply) < 2 {
+		return nil, fmt.Errorf("invalid xautoclaim reply %v", reply)
+	}
+
+	next, err := redigo.String(reply[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseStreamEntries(w.stream, reply[1])
+	if err != nil {
+		return nil, err
+	}
+
+	w.claimStart = next
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	if err = w.fillDeliveries(ctx, entries); err != nil {
+		return nil, err
+	}
+
+	msgs := make([]*StreamMessage, 0, len(entries))
+	for _, msg := range entries {
+		switch {
+		case msg.Values == nil:
+			// the message deleted from stream
+			w.ack(msg)
+		case w.options.MaxDeliveries > 0 && msg.Deliveries > w.options.MaxDeliveries:
+			w.deadLetter(msg)
+		default:
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs, nil
+}
+
+// fillDeliveries query the delivery count of messages by XPENDING
+func (w *streamWorker) fillDeliveries(ctx context.Context, msgs []*StreamMessage) error {
+	cli := &clientProxyImpl{name: w.name, opts: w.opts}
+	replies, err := cli.Pipeline(ctx, func(p Pipeliner) error {
+		for _, msg := range msgs {
+			p.Send("XPENDING", w.stream, w.group, msg.ID, msg.ID, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, v := range replies {
+		pending, err := redigo.Values(v.Result())
+		if err != nil {
+			return err
+		}
+
+		// [[id, consumer, idle, deliveries]]
+		if len(pending) == 0 {
+			continue
+		}
+
+		entry, err := redigo.Values(pending[0], nil)
+		if err != nil || len(entry) != 4 {
+			return fmt.Errorf("invalid xpending reply %v", pending[0])
+		}
+
+		if msgs[i].Deliveries, err = redigo.Int64(entry[3], nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// process call handler and ack the message on success
+func (w *streamWorker) process(ctx context.Context, msg *StreamMessage) {
+	if err := w.call(ctx, msg); err != nil {
+		logErrorf("handle stream %s message %s fail. deliveries:%d error:%v", w.stream, msg.ID, msg.Deliveries, err)
+		return
+	}
+
+	w.ack(msg)
+}
+
+func (w *streamWorker) call(ctx context.Context, msg *StreamMessage) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+
+	return w.handler(ctx, msg)
+}
+
+func (w *streamWorker) ack(msg *StreamMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), streamAckTimeout)
+	defer cancel()
+
+	if _, err := w.do(ctx, "XACK", w.stream, w.group, msg.ID); err != nil {
+		logErrorf("ack stream %s message %s fail. error:%v", w.stream, msg.ID, err)
+	}
+}
+
+// deadLetter move the message to the dead-letter stream
+//
+// The source of message is appended to the values with the fields _stream,
+// _group, _id and _deliveries.
+func (w *streamWorker) deadLetter(msg *StreamMessage) {
+	values := make(map[string]interface{}, len(msg.Values)+4)
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+
+	values["_stream"] = w.stream
+	values["_group"] = w.group
+	values["_id"] = msg.ID
+	values["_deliveries"] = msg.Deliveries
+
+	ctx, cancel := context.WithTimeout(context.Background(), streamAckTimeout)
+	defer cancel()
+
+	if _, err := w.Add(ctx, w.options.DeadLetterStream, values); err != nil {
+		logErrorf("move stream %s message %s to %s fail. error:%v", w.stream, msg.ID, w.options.DeadLetterStream, err)
+		return
+	}
+
+	w.ack(msg)
+}
+
+// parseStreamEntries parse the entries [[id, [field, value, ...]], ...],
+// the values is nil when the message deleted
+func parseStreamEntries(stream string, reply interface{}) ([]*StreamMessage, error) {
+	entries, err := redigo.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]*StreamMessage, 0, len(entries))
+	for _, v := range entries {
+		entry, err := redigo.Values(v, nil)
+		if err != nil || len(entry) != 2 {
+			return nil, fmt.Errorf("invalid stream entry %v", v)
+		}
+
+		id, err := redigo.String(entry[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		msg := &StreamMessage{
+			Stream: stream,
+			ID:     id,
+		}
+
+		if entry[1] != nil {
+			if msg.Values, err = redigo.StringMap(entry[1], nil); err != nil {
+				return nil, err
+			}
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStreamEntry(id string, values ...string) interface{} {
+	if len(values) == 0 {
+		return []interface{}{[]byte(id), nil}
+	}
+
+	fields := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		fields = append(fields, []byte(v))
+	}
+
+	return []interface{}{[]byte(id), fields}
+}
+
+func Test_streamImpl_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		opts    []StreamAddOption
+		mock    func(conn *redigomock.Conn)
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "empty values",
+			wantErr: true,
+		},
+		{
+			name:   "normal process",
+			values: map[string]interface{}{"k2": 2, "k1": "v1"},
+			mock: func(conn *redigomock.Conn) {
+				conn.Command("XADD", "s1", "*", "k1", "v1", "k2", 2).Expect([]byte("1-0"))
+			},
+			want: "1-0",
+		},
+		{
+			name:   "trim",
+			values: map[string]interface{}{"k1": "v1"},
+			opts:   []StreamAddOption{WithStreamMaxLen(100, true)},
+			mock: func(conn *redigomock.Conn) {
+				conn.Command("XADD", "s1", "MAXLEN", "~", int64(100), "*", "k1", "v1").Expect([]byte("2-0"))
+			},
+			want: "2-0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := redigomock.NewConn()
+			if tt.mock != nil {
+				tt.mock(conn)
+			}
+
+			patches := gomonkey.ApplyFunc(getRedisPool,
+				func(string, ...ClientOption) connPool {
+					return &mockPool{conn: conn}
+				})
+			defer patches.Reset()
+
+			got, err := NewStreamProxy("client_name").Add(context.Background(), "s1", tt.values, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("streamImpl.Add() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// ctxConn fails the commands after the context canceled
+type ctxConn struct {
+	*redigomock.Conn
+}
+
+func (c *ctxConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Conn.DoContext(ctx, cmd, args...)
+}
+
+func Test_streamImpl_Consume(t *testing.T) {
+	conn := redigomock.NewConn()
+	conn.Command("XGROUP", "CREATE", "s1", "g1", "0", "MKSTREAM").
+		ExpectError(redigo.Error("BUSYGROUP Consumer Group name already exists"))
+
+	// 1-0 retried, 2-0 deleted, 3-0 exceeded max deliveries
+	conn.Command("XAUTOCLAIM", "s1", "g1", "c1", int64(1000), "0-0", "COUNT", 2).Expect([]interface{}{
+		[]byte("0-0"),
+		[]interface{}{
+			newStreamEntry("1-0", "k1", "v1"),
+			newStreamEntry("2-0"),
+			newStreamEntry("3-0", "k1", "v3"),
+		},
+		[]interface{}{},
+	})
+	conn.Command("XPENDING", "s1", "g1", "1-0", "1-0", 1).
+		Expect([]interface{}{[]interface{}{[]byte("1-0"), []byte("c1"), int64(1000), int64(2)}})
+	conn.Command("XPENDING", "s1", "g1", "2-0", "2-0", 1).
+		Expect([]interface{}{[]interface{}{[]byte("2-0"), []byte("c1"), int64(1000), int64(1)}})
+	conn.Command("XPENDING", "s1", "g1", "3-0", "3-0", 1).
+		Expect([]interface{}{[]interface{}{[]byte("3-0"), []byte("c1"), int64(1000), int64(6)}})
+	deleted := conn.Command("XACK", "s1", "g1", "2-0").Expect(int64(1))
+	deadLetter := conn.Command("XADD", "s1:dead", "*", "_deliveries", int64(6), "_group", "g1", "_id", "3-0",
+		"_stream", "s1", "k1", "v3").Expect([]byte("4-0"))
+	dead := conn.Command("XACK", "s1", "g1", "3-0").Expect(int64(1))
+	failed := conn.Command("XACK", "s1", "g1", "1-0").Expect(int64(1))
+	acked := conn.Command("XACK", "s1", "g1", "5-0").Expect(int64(1))
+
+	// the new message 5-0, and timeout after
+	conn.GenericCommand("XREADGROUP").Expect([]interface{}{
+		[]interface{}{[]byte("s1"), []interface{}{newStreamEntry("5-0", "k1", "v5")}},
+	}).Expect(nil)
+
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return &mockPool{conn: &ctxConn{conn}}
+		})
+	defer patches.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 5-0 is acked after the consumer canceled
+	var mu sync.Mutex
+	var got []*StreamMessage
+	err := NewStreamProxy("client_name").Consume(ctx, "s1", "g1", "c1", func(ctx context.Context, msg *StreamMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		got = append(got, msg)
+		if msg.ID == "5-0" {
+			cancel()
+		}
+
+		if msg.ID == "1-0" {
+			return fmt.Errorf("handle fail")
+		}
+		return nil
+	}, WithConsumeConcurrency(2), WithConsumeClaim(time.Second, time.Hour), WithConsumeDeadLetter(5, ""))
+	assert.Nil(t, err)
+
+	sort.Slice(got, func(i, j int) bool { return got[i].ID < got[j].ID })
+	assert.Equal(t, []*StreamMessage{
+		{Stream: "s1", ID: "1-0", Values: map[string]string{"k1": "v1"}, Deliveries: 2},
+		{Stream: "s1", ID: "5-0", Values: map[string]string{"k1": "v5"}, Deliveries: 1},
+	}, got)
+	assert.Equal(t, 1, conn.Stats(deleted))
+	assert.Equal(t, 1, conn.Stats(deadLetter))
+	assert.Equal(t, 1, conn.Stats(dead))
+	assert.Equal(t, 0, conn.Stats(failed))
+	assert.Equal(t, 1, conn.Stats(acked))
+}
+
+func Test_streamImpl_ConsumeCreateGroupFail(t *testing.T) {
+	conn := redigomock.NewConn()
+	conn.Command("XGROUP", "CREATE", "s1", "g1", "$", "MKSTREAM").ExpectError(redigo.Error("ERR fail"))
+
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return &mockPool{conn: conn}
+		})
+	defer patches.Reset()
+
+	err := NewStreamProxy("client_name").Consume(context.Background(), "s1", "g1", "c1",
+		func(ctx context.Context, msg *StreamMessage) error { return nil }, WithConsumeStartID("$"))
+	assert.NotNil(t, err)
+
+	err = NewStreamProxy("client_name").Consume(context.Background(), "s1", "g1", "c1", nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,291 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+// SubscriberProxy pub/sub subscriber
+//
+// The subscription is kept until the context canceled. The connection is
+// pinged periodically, and reconnected with backoff and resubscribed when
+// lost.
+//
+//go:generate mockgen -source=subscriber.go -destination=mockredis/subscriber_mock.go -package=mockredis
+type SubscriberProxy interface {
+
+	// Subscribe subscribes the channels and returns the message channel,
+	// the message channel will be closed after the context canceled.
+	//
+	// Return error when the first subscription fail.
+	Subscribe(ctx context.Context, channels []string, opts ...SubscribeOption) (<-chan *Message, error)
+
+	// PSubscribe subscribes the patterns and returns the message channel,
+	// the message channel will be closed after the context canceled.
+	//
+	// Return error when the first subscription fail.
+	PSubscribe(ctx context.Context, patterns []string, opts ...SubscribeOption) (<-chan *Message, error)
+
+	// SubscribeFunc subscribes the channels and calls handler for each
+	// message in order.
+	//
+	// Will block the current goroutine until the context canceled.
+	// Return error when the first subscription fail.
+	SubscribeFunc(ctx context.Context, channels []string, handler func(msg *Message), opts ...SubscribeOption) error
+
+	// PSubscribeFunc subscribes the patterns and calls handler for each
+	// message in order.
+	//
+	// Will block the current goroutine until the context canceled.
+	// Return error when the first subscription fail.
+	PSubscribeFunc(ctx context.Context, patterns []string, handler func(msg *Message), opts ...SubscribeOption) error
+}
+
+// Message the message received from channel
+type Message struct {
+	// Channel the channel of message
+	Channel string
+
+	// Pattern the matched pattern, empty for SUBSCRIBE
+	Pattern string
+
+	// Data the message data
+	Data []byte
+}
+
+type subscriberImpl struct {
+	name string
+	opts []ClientOption
+}
+
+// NewSubscriberProxy new subscriber proxy
+func NewSubscriberProxy(name string, opts ...ClientOption) SubscriberProxy {
+	return &subscriberImpl{
+		name: name,
+		opts: opts,
+	}
+}
+
+// Subscribe subscribes the channels and returns the message channel
+func (s *subscriberImpl) Subscribe(ctx context.Context, channels []string, opts ...SubscribeOption) (<-chan *Message, error) {
+	return s.subscribeChan(ctx, false, channels, opts...)
+}
+
+// PSubscribe subscribes the patterns and returns the message channel
+func (s *subscriberImpl) PSubscribe(ctx context.Context, patterns []string, opts ...SubscribeOption) (<-chan *Message, error) {
+	return s.subscribeChan(ctx, true, patterns, opts...)
+}
+
+// SubscribeFunc subscribes the channels and calls handler for each message
+func (s *subscriberImpl) SubscribeFunc(ctx context.Context, channels []string, handler func(msg *Message),
+	opts ...SubscribeOption) error {
+	return s.subscribeFunc(ctx, false, channels, handler, opts...)
+}
+
+// PSubscribeFunc subscribes the patterns and calls handler for each message
+func (s *subscriberImpl) PSubscribeFunc(ctx context.Context, patterns []string, handler func(msg *Message),
+	opts ...SubscribeOption) error {
+	return s.subscribeFunc(ctx, true, patterns, handler, opts...)
+}
+
+func (s *subscriberImpl) subscribeChan(ctx context.Context, pattern bool, targets []string,
+	opts ...SubscribeOption) (<-chan *Message, error) {
+	options := newSubscribeOptions(opts...)
+	msgs := make(chan *Message, options.BufferSize)
+	sub := s.newSubscription(pattern, targets, options, func(msg *Message) {
+		select {
+		case msgs <- msg:
+		case <-ctx.Done():
+		}
+	})
+
+	psc, err := sub.subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(msgs)
+		sub.run(ctx, psc)
+	}()
+
+	return msgs, nil
+}
+
+func (s *subscriberImpl) subscribeFunc(ctx context.Context, pattern bool, targets []string, handler func(msg *Message),
+	opts ...SubscribeOption) error {
+	if handler == nil {
+		return errors.New("redis: subscribe handler required")
+	}
+
+	sub := s.newSubscription(pattern, targets, newSubscribeOptions(opts...), handler)
+	psc, err := sub.subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	sub.run(ctx, psc)
+	return nil
+}
+
+func (s *subscriberImpl) newSubscription(pattern bool, targets []string, options *SubscribeOptions,
+	deliver func(msg *Message)) *subscription {
+	return &subscription{
+		getConn: s.getConn,
+		pattern: pattern,
+		targets: targets,
+		options: options,
+		deliver: deliver,
+	}
+}
+
+func (s *subscriberImpl) getConn() redigo.Conn {
+	return getRedisPool(s.name, s.opts...).Get()
+}
+
+// subscription the subscription of channels or patterns
+type subscription struct {
+	getConn func() redigo.Conn
+	pattern bool
+	targets []string
+	options *SubscribeOptions
+	deliver func(msg *Message)
+
+	// notify is called when the subscription lost or resubscribed, the
+	// messages may be missed in between
+	notify func(subscribed bool)
+}
+
+// run receive the messages until the context canceled, and resubscribe
+// with backoff when the connection lost
+func (s *subscription) run(ctx context.Context, psc *redigo.PubSubConn) {
+	for {
+		err := s.receive(ctx, psc)
+		if e := psc.Close(); e != nil {
+			logErrorf("connect close fail. error:%v", e)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if s.notify != nil {
+			s.notify(false)
+		}
+
+		backoff := s.options.MinBackoff
+		for {
+			logErrorf("subscription %v lost, resubscribe after %v. error:%v", s.targets, backoff, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			if psc, err = s.subscribe(ctx); err == nil {
+				if s.notify != nil {
+					s.notify(true)
+				}
+				break
+			}
+
+			if backoff *= 2; backoff > s.options.MaxBackoff {
+				backoff = s.options.MaxBackoff
+			}
+		}
+	}
+}
+
+// subscribe subscribe the targets and wait for the confirmations
+func (s *subscription) subscribe(ctx context.Context) (*redigo.PubSubConn, error) {
+	if len(s.targets) == 0 {
+		return nil, errors.New("redis: subscribe channels required")
+	}
+
+	psc := &redigo.PubSubConn{Conn: s.getConn()}
+	args := make([]interface{}, 0, len(s.targets))
+	for _, v := range s.targets {
+		args = append(args, v)
+	}
+
+	var err error
+	if s.pattern {
+		err = psc.PSubscribe(args...)
+	} else {
+		err = psc.Subscribe(args...)
+	}
+
+	for confirmed := 0; err == nil && confirmed < len(s.targets); {
+		switch v := psc.ReceiveWithTimeout(s.options.Ping).(type) {
+		case redigo.Subscription:
+			confirmed++
+		case redigo.Message:
+			s.deliver(newMessage(v))
+		case error:
+			err = v
+		}
+	}
+
+	if err != nil {
+		psc.Close()
+		return nil, err
+	}
+
+	return psc, nil
+}
+
+// receive deliver the messages until the connection lost or unsubscribed
+// on context canceled
+func (s *subscription) receive(ctx context.Context, psc *redigo.PubSubConn) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	// the writes are in one goroutine, concurrent with the reads
+	go func() {
+		ticker := time.NewTicker(s.options.Ping)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				if s.pattern {
+					psc.PUnsubscribe()
+				} else {
+					psc.Unsubscribe()
+				}
+				return
+			case <-ticker.C:
+				if err := psc.Ping(""); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		switch v := psc.ReceiveWithTimeout(2 * s.options.Ping).(type) {
+		case redigo.Message:
+			s.deliver(newMessage(v))
+		case redigo.Subscription:
+			if v.Count == 0 {
+				return nil
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+func newMessage(msg redigo.Message) *Message {
+	return &Message{
+		Channel: msg.Channel,
+		Pattern: msg.Pattern,
+		Data:    msg.Data,
+	}
+}
@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockPool returns the connections in order, and conn or the empty mock
+// connection after all returned
+type mockPool struct {
+	mu    sync.Mutex
+	conns []redigo.Conn
+	conn  redigo.Conn
+}
+
+func (p *mockPool) Get() redigo.Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.conns) == 0 {
+		if p.conn != nil {
+			return p.conn
+		}
+		return redigomock.NewConn()
+	}
+
+	c := p.conns[0]
+	p.conns = p.conns[1:]
+	return c
+}
+
+func (p *mockPool) ActiveCount() int { return 0 }
+func (p *mockPool) IdleCount() int   { return 0 }
+func (p *mockPool) Close() error     { return nil }
+
+func newSubscribedConn(cmd, target string, data ...string) *redigomock.Conn {
+	kind := "message"
+	if cmd == "PSUBSCRIBE" {
+		kind = "pmessage"
+	}
+
+	conn := redigomock.NewConn()
+	conn.Command(cmd, target).Expect([]interface{}{[]byte(strings.ToLower(cmd)), []byte(target), int64(1)})
+	for _, v := range data {
+		if kind == "pmessage" {
+			conn.AddSubscriptionMessage([]interface{}{[]byte(kind), []byte(target), []byte("ch1"), []byte(v)})
+			continue
+		}
+		conn.AddSubscriptionMessage([]interface{}{[]byte(kind), []byte(target), []byte(v)})
+	}
+
+	return conn
+}
+
+func Test_subscriberImpl_Subscribe(t *testing.T) {
+	pool := &mockPool{
+		conns: []redigo.Conn{
+			newSubscribedConn("SUBSCRIBE", "ch1", "m1"),
+			redigomock.NewConn(), // subscribe fail
+			newSubscribedConn("SUBSCRIBE", "ch1", "m2"),
+		},
+	}
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return pool
+		})
+	defer patches.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewSubscriberProxy("client_name")
+	msgs, err := s.Subscribe(ctx, []string{"ch1"}, WithSubscribeBackoff(time.Millisecond, 2*time.Millisecond))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// resubscribed after the connection lost
+	assert.Equal(t, &Message{Channel: "ch1", Data: []byte("m1")}, <-msgs)
+	assert.Equal(t, &Message{Channel: "ch1", Data: []byte("m2")}, <-msgs)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		select {
+		case _, ok := <-msgs:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond, "message channel should be closed")
+
+	_, err = s.Subscribe(context.Background(), nil)
+	assert.NotNil(t, err)
+}
+
+func Test_subscriberImpl_PSubscribeFunc(t *testing.T) {
+	pool := &mockPool{
+		conns: []redigo.Conn{
+			newSubscribedConn("PSUBSCRIBE", "ch*", "m1", "m2"),
+		},
+	}
+	patches := gomonkey.ApplyFunc(getRedisPool,
+		func(string, ...ClientOption) connPool {
+			return pool
+		})
+	defer patches.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []*Message
+	err := NewSubscriberProxy("client_name").PSubscribeFunc(ctx, []string{"ch*"}, func(msg *Message) {
+		got = append(got, msg)
+		if len(got) == 2 {
+			cancel()
+		}
+	}, WithSubscribeBackoff(time.Millisecond, time.Millisecond))
+	assert.Nil(t, err)
+	assert.Equal(t, []*Message{
+		{Channel: "ch1", Pattern: "ch*", Data: []byte("m1")},
+		{Channel: "ch1", Pattern: "ch*", Data: []byte("m2")},
+	}, got)
+
+	err = NewSubscriberProxy("client_name").SubscribeFunc(context.Background(), []string{"ch1"}, nil)
+	assert.NotNil(t, err)
+}